@@ -8,6 +8,7 @@ import (
 	"log/slog"
 	"os"
 	"time"
+	_ "time/tzdata" // time zones of reports do not depend on the host
 
 	"github.com/joho/godotenv"
 	"github.com/moxicom/user_test/internal/config"
@@ -60,20 +61,67 @@ func runServer(ctx context.Context) error {
 
 	cfg := config.InitDbConfig()
 
+	autoStopCfg, err := config.InitAutoStopConfig()
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	roundingCfg, err := config.InitRoundingConfig()
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	invoiceCfg, err := config.InitInvoiceConfig()
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	estimateCfg, err := config.InitEstimateAlertConfig()
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	serverCfg, err := config.InitServerConfig()
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
 	db, err := postgres.NewDbInit(cfg)
 	if err != nil {
 		log.Error(err.Error())
 		return err
 	}
 
-	migrations.MigratePostgres(db, log)
+	rebuildRollup := migrations.MigratePostgres(db, log)
 
 	// Dependency injection
 	storage := postgres.NewStorage(db, log)
-	service := services.New(storage, log)
-	handler := handlers.New(service, log)
+	if rebuildRollup {
+		log.Info("Building daily totals...")
+		if err := storage.RebuildDailyTotals(); err != nil {
+			log.Error("failed to build daily totals", slog.Any("err", err))
+		}
+	}
+	rounding := services.Rounding{Minutes: roundingCfg.Minutes, Mode: services.RoundingMode(roundingCfg.Mode)}
+	invoicing := services.InvoiceConfig{Currency: invoiceCfg.Currency, Issuer: invoiceCfg.Issuer}
+	service := services.New(storage, log, rounding, invoicing)
+	handler := handlers.New(service, serverCfg.PublicURL, log)
 	server := server.New()
 
+	autoStop := services.AutoStopConfig{MaxPeriod: autoStopCfg.MaxPeriod, Interval: autoStopCfg.Interval}
+	estimateAlerts := services.EstimateAlertConfig{
+		Thresholds: estimateCfg.Thresholds,
+		Interval:   estimateCfg.Interval,
+		WebhookURL: estimateCfg.WebhookURL,
+	}
+	go services.NewAutoStop(storage, log, autoStop).Run(ctx)
+	go services.NewEstimateAlerts(storage, log, estimateAlerts).Run(ctx)
+
 	go func() {
 		if err = server.Run(os.Getenv("SERVER_PORT"), handler.InitRoutes()); err != nil {
 			log.Error("listen and serve: %s", slog.Any("err", err))
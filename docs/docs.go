@@ -15,9 +15,9 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/tasks": {
-            "post": {
-                "description": "Create a new task for a user",
+        "/alerts": {
+            "get": {
+                "description": "Retrieve alerts raised when tracked time of a task crossed a threshold percent of its estimate (80, 100 and 150 by default), latest first. Alerts are recorded by a periodic check and are pushed only to ESTIMATE_WEBHOOK_URL if set, so poll this endpoint otherwise",
                 "consumes": [
                     "application/json"
                 ],
@@ -27,33 +27,45 @@ const docTemplate = `{
                 "tags": [
                     "tasks"
                 ],
-                "summary": "Create a new task",
+                "summary": "Get estimate alerts",
                 "parameters": [
                     {
-                        "description": "Task object",
-                        "name": "task",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.Task"
-                        }
+                        "type": "integer",
+                        "description": "Task ID",
+                        "name": "task_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Project ID",
+                        "name": "project_id",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Task created successfully",
+                        "description": "List of alerts",
                         "schema": {
-                            "$ref": "#/definitions/handlers.Message"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.TaskAlert"
+                            }
                         }
                     },
                     "400": {
-                        "description": "Invalid body data",
+                        "description": "Invalid input data",
                         "schema": {
                             "$ref": "#/definitions/handlers.Message"
                         }
                     },
                     "500": {
-                        "description": "Failed to create task",
+                        "description": "Failed to get alerts",
                         "schema": {
                             "$ref": "#/definitions/handlers.Message"
                         }
@@ -61,43 +73,40 @@ const docTemplate = `{
                 }
             }
         },
-        "/tasks/{id}": {
-            "delete": {
-                "description": "Delete a task by ID",
-                "consumes": [
-                    "application/json"
-                ],
+        "/calendar/{token}": {
+            "get": {
+                "description": "Subscribable iCalendar feed with periods of the last 90 days of the token owner",
                 "produces": [
-                    "application/json"
+                    "text/calendar"
                 ],
                 "tags": [
-                    "tasks"
+                    "calendar"
                 ],
-                "summary": "Delete a task",
+                "summary": "Get calendar feed",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Task ID",
-                        "name": "id",
+                        "type": "string",
+                        "description": "Feed token, optionally with .ics suffix",
+                        "name": "token",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Task deleted",
+                        "description": "iCalendar file",
                         "schema": {
-                            "$ref": "#/definitions/handlers.Message"
+                            "type": "string"
                         }
                     },
-                    "400": {
-                        "description": "ID should be an integer",
+                    "404": {
+                        "description": "Calendar not found",
                         "schema": {
                             "$ref": "#/definitions/handlers.Message"
                         }
                     },
                     "500": {
-                        "description": "Failed to delete task",
+                        "description": "Failed to get calendar",
                         "schema": {
                             "$ref": "#/definitions/handlers.Message"
                         }
@@ -105,43 +114,61 @@ const docTemplate = `{
                 }
             }
         },
-        "/tasks/{id}/end": {
+        "/import/csv": {
             "post": {
-                "description": "End a period for a task by ID",
+                "description": "Import Toggl or Clockify detailed report CSV. People are mapped to users by passport column if present, otherwise by email, projects by name. Billable column (Yes or No) marks periods billable, billable by default. Dry run (default) returns preview of the import without saving",
                 "consumes": [
-                    "application/json"
+                    "multipart/form-data"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "tasks"
+                    "import"
                 ],
-                "summary": "End a period for a task",
+                "summary": "Import time entries from CSV",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Task ID",
-                        "name": "id",
-                        "in": "path",
+                        "type": "file",
+                        "description": "Detailed report CSV",
+                        "name": "file",
+                        "in": "formData",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Source format, 'toggl' or 'clockify'. Detected from header by default",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only preview the import. Default is true",
+                        "name": "dry_run",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "IANA time zone of report times, time zone of each user by default",
+                        "name": "tz",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Period ended",
+                        "description": "Import result",
                         "schema": {
-                            "$ref": "#/definitions/handlers.Message"
+                            "$ref": "#/definitions/models.CSVImportResult"
                         }
                     },
                     "400": {
-                        "description": "Failed to end period. Period not started",
+                        "description": "Invalid input data",
                         "schema": {
                             "$ref": "#/definitions/handlers.Message"
                         }
                     },
                     "500": {
-                        "description": "Failed to end",
+                        "description": "Failed to import time entries",
                         "schema": {
                             "$ref": "#/definitions/handlers.Message"
                         }
@@ -149,9 +176,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/tasks/{id}/finish": {
-            "post": {
-                "description": "Mark a task as finished by ID",
+        "/invoices": {
+            "get": {
+                "description": "Retrieve invoices with their items, latest first",
                 "consumes": [
                     "application/json"
                 ],
@@ -159,43 +186,49 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "tasks"
+                    "invoices"
                 ],
-                "summary": "Finish a task",
+                "summary": "Get invoices",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Task ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Project ID",
+                        "name": "project_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client, case insensitive",
+                        "name": "client",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Task ended",
+                        "description": "List of invoices",
                         "schema": {
-                            "$ref": "#/definitions/handlers.Message"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Invoice"
+                            }
                         }
                     },
                     "400": {
-                        "description": "ID should be an integer",
+                        "description": "Invalid input data",
                         "schema": {
                             "$ref": "#/definitions/handlers.Message"
                         }
                     },
                     "500": {
-                        "description": "Failed to finish task",
+                        "description": "Failed to get invoices",
                         "schema": {
                             "$ref": "#/definitions/handlers.Message"
                         }
                     }
                 }
-            }
-        },
-        "/tasks/{id}/start": {
+            },
             "post": {
-                "description": "Start a period for a task by ID",
+                "description": "Bill unbilled billable finished periods of a project or of all projects of a client started within the range. Time is grouped into items by task and hourly rate effective on each day. Invoice gets the next number of the year and its periods can not be billed again, edited or deleted",
                 "consumes": [
                     "application/json"
                 ],
@@ -203,33 +236,41 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "tasks"
+                    "invoices"
                 ],
-                "summary": "Start a period for a task",
+                "summary": "Create an invoice",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Task ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Exactly one of project_id and client, range in RFC3339 or YYYY-MM-DD format, to date includes the whole day",
+                        "name": "invoice",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.invoiceInput"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "IANA time zone of dates without offset, server time zone by default",
+                        "name": "tz",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Period started",
+                        "description": "Created invoice",
                         "schema": {
-                            "$ref": "#/definitions/handlers.Message"
+                            "$ref": "#/definitions/models.Invoice"
                         }
                     },
                     "400": {
-                        "description": "Failed to start period. Period not finished",
+                        "description": "Invalid input data or nothing to invoice",
                         "schema": {
                             "$ref": "#/definitions/handlers.Message"
                         }
                     },
                     "500": {
-                        "description": "Failed to start",
+                        "description": "Failed to create invoice",
                         "schema": {
                             "$ref": "#/definitions/handlers.Message"
                         }
@@ -237,9 +278,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/users": {
+        "/invoices/{id}": {
             "get": {
-                "description": "Retrieve users based on filters",
+                "description": "Retrieve an invoice with its items by ID",
                 "consumes": [
                     "application/json"
                 ],
@@ -247,61 +288,41 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "invoices"
                 ],
-                "summary": "Get users",
+                "summary": "Get an invoice",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Passport Number",
-                        "name": "passport_number",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Surname",
-                        "name": "surname",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Name",
-                        "name": "name",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Patronymic",
-                        "name": "patronymic",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Address",
-                        "name": "address",
-                        "in": "query"
+                        "type": "integer",
+                        "description": "Invoice ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "List of users",
+                        "description": "Invoice found",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.User"
-                            }
+                            "$ref": "#/definitions/models.Invoice"
+                        }
+                    },
+                    "400": {
+                        "description": "ID should be an integer",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
                         }
                     },
                     "500": {
-                        "description": "Failed to get users",
+                        "description": "Failed to get invoice",
                         "schema": {
                             "$ref": "#/definitions/handlers.Message"
                         }
                     }
                 }
             },
-            "post": {
-                "description": "Create a new user with the provided passport number",
+            "delete": {
+                "description": "Delete an invoice by ID. Its periods become unbilled and can be invoiced again",
                 "consumes": [
                     "application/json"
                 ],
@@ -309,35 +330,33 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "invoices"
                 ],
-                "summary": "Create a new user",
+                "summary": "Delete an invoice",
                 "parameters": [
                     {
-                        "description": "User",
-                        "name": "user",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handlers.createUser"
-                        }
+                        "type": "integer",
+                        "description": "Invoice ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "User ID",
+                        "description": "Invoice deleted",
                         "schema": {
                             "$ref": "#/definitions/handlers.Message"
                         }
                     },
                     "400": {
-                        "description": "Invalid body data or invalid passport number",
+                        "description": "ID should be an integer",
                         "schema": {
                             "$ref": "#/definitions/handlers.Message"
                         }
                     },
                     "500": {
-                        "description": "Failed to create user",
+                        "description": "Failed to delete invoice",
                         "schema": {
                             "$ref": "#/definitions/handlers.Message"
                         }
@@ -345,95 +364,61 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/{id}": {
-            "put": {
-                "description": "Update a user with the provided data",
-                "consumes": [
-                    "application/json"
-                ],
+        "/invoices/{id}/invoice.html": {
+            "get": {
+                "description": "Render HTML invoice with its items and total. It has a print stylesheet for A4",
                 "produces": [
-                    "application/json"
+                    "text/html"
                 ],
                 "tags": [
-                    "users"
+                    "invoices"
                 ],
-                "summary": "Update a user",
+                "summary": "Get printable invoice",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "User ID",
+                        "description": "Invoice ID",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Passport Number",
-                        "name": "passport_number",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Surname",
-                        "name": "surname",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Name",
-                        "name": "name",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Patronymic",
-                        "name": "patronymic",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Address",
-                        "name": "address",
-                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "User updated",
+                        "description": "HTML invoice",
                         "schema": {
-                            "$ref": "#/definitions/handlers.Message"
+                            "type": "string"
                         }
                     },
                     "400": {
-                        "description": "Incorrect ID or invalid input data",
+                        "description": "ID should be an integer",
                         "schema": {
                             "$ref": "#/definitions/handlers.Message"
                         }
                     },
                     "500": {
-                        "description": "Failed to update user",
+                        "description": "Failed to get invoice",
                         "schema": {
                             "$ref": "#/definitions/handlers.Message"
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Delete a user by ID",
-                "consumes": [
-                    "application/json"
-                ],
+            }
+        },
+        "/invoices/{id}/invoice.pdf": {
+            "get": {
+                "description": "Render PDF invoice with its items and total. Text is set in embedded DejaVu Sans",
                 "produces": [
-                    "application/json"
+                    "application/pdf"
                 ],
                 "tags": [
-                    "users"
+                    "invoices"
                 ],
-                "summary": "Delete a user",
+                "summary": "Get invoice PDF",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "User ID",
+                        "description": "Invoice ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -441,9 +426,9 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "User deleted",
+                        "description": "PDF invoice",
                         "schema": {
-                            "$ref": "#/definitions/handlers.Message"
+                            "type": "file"
                         }
                     },
                     "400": {
@@ -453,7 +438,7 @@ const docTemplate = `{
                         }
                     },
                     "500": {
-                        "description": "Failed to delete user",
+                        "description": "Failed to get invoice",
                         "schema": {
                             "$ref": "#/definitions/handlers.Message"
                         }
@@ -461,9 +446,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/{id}/tasks": {
-            "get": {
-                "description": "Get tasks for a user within a specified date range and with optional sorting",
+        "/periods/{id}": {
+            "put": {
+                "description": "Change bounds of a finished period with the same rules as on adding. Period within an approved timesheet or invoiced can not be moved",
                 "consumes": [
                     "application/json"
                 ],
@@ -471,105 +456,4307 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "periods"
                 ],
-                "summary": "Get user tasks",
+                "summary": "Update a period",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "User ID",
+                        "description": "Period ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "type": "string",
-                        "description": "Start date in RFC3339 format",
-                        "name": "start_date",
-                        "in": "query",
+                        "description": "Period bounds in RFC3339 format",
+                        "name": "period",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.periodInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Period updated",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input data",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "404": {
+                        "description": "Period not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to update period",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a finished period by ID. Period within an approved timesheet or invoiced can not be deleted",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "periods"
+                ],
+                "summary": "Delete a period",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Period ID",
+                        "name": "id",
+                        "in": "path",
                         "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Period deleted",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "400": {
+                        "description": "ID should be an integer or period can not be deleted",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "404": {
+                        "description": "Period not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
                     },
+                    "500": {
+                        "description": "Failed to delete period",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Mark a period billable or non-billable. Only billable time counts in billable amount of reports and is invoiced. Period within an approved timesheet or invoiced can not be changed",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "periods"
+                ],
+                "summary": "Mark period billable",
+                "parameters": [
                     {
-                        "type": "string",
-                        "description": "End date in RFC3339 format",
-                        "name": "end_date",
-                        "in": "query",
+                        "type": "integer",
+                        "description": "Period ID",
+                        "name": "id",
+                        "in": "path",
                         "required": true
                     },
+                    {
+                        "description": "Billable flag",
+                        "name": "period",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.billableInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Period updated",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input data",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "404": {
+                        "description": "Period not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to update period",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    }
+                }
+            }
+        },
+        "/projects": {
+            "get": {
+                "description": "Retrieve projects based on filters",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "projects"
+                ],
+                "summary": "Get projects",
+                "parameters": [
                     {
                         "type": "string",
-                        "description": "Sort order, can be 'asc' or 'desc'",
-                        "name": "sort",
+                        "description": "Name",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client",
+                        "name": "client",
                         "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Tasks found",
+                        "description": "List of projects",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/models.Task"
+                                "$ref": "#/definitions/models.Project"
                             }
                         }
                     },
+                    "500": {
+                        "description": "Failed to get projects",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a new project. Tasks are assigned to projects with project_id. Optional budget_hours and budget_amount (cost at hourly rates) are shown in burn-down",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "projects"
+                ],
+                "summary": "Create a new project",
+                "parameters": [
+                    {
+                        "description": "Project object",
+                        "name": "project",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Project"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Project ID",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
                     "400": {
-                        "description": "Invalid input data",
+                        "description": "Invalid body data or negative budget",
                         "schema": {
                             "$ref": "#/definitions/handlers.Message"
                         }
                     },
                     "500": {
-                        "description": "Failed to get tasks for user",
+                        "description": "Failed to create project",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    }
+                }
+            }
+        },
+        "/projects/{id}": {
+            "get": {
+                "description": "Retrieve a project by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "projects"
+                ],
+                "summary": "Get a project",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Project ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Project",
+                        "schema": {
+                            "$ref": "#/definitions/models.Project"
+                        }
+                    },
+                    "400": {
+                        "description": "ID should be an integer",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get project",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update a project with the provided data",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "projects"
+                ],
+                "summary": "Update a project",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Project ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Name",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client",
+                        "name": "client",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Description",
+                        "name": "description",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Hour budget, 0 removes it",
+                        "name": "budget_hours",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Money budget of cost at hourly rates, 0 removes it",
+                        "name": "budget_amount",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Project updated",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "400": {
+                        "description": "Incorrect ID or invalid input data",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to update project",
                         "schema": {
                             "$ref": "#/definitions/handlers.Message"
                         }
                     }
                 }
+            },
+            "delete": {
+                "description": "Delete a project by ID. Its tasks are kept without project",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "projects"
+                ],
+                "summary": "Delete a project",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Project ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Project deleted",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "400": {
+                        "description": "ID should be an integer",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to delete project",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    }
+                }
+            }
+        },
+        "/projects/{id}/burndown": {
+            "get": {
+                "description": "Get time and cost of a project per day with cumulative totals, including time tracked before the range, and remaining hour and money budgets. Cost values time at hourly rates, running timers count until now",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "projects"
+                ],
+                "summary": "Get project burn-down",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Project ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Range start in RFC3339 or YYYY-MM-DD format, project creation day by default",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Range end in RFC3339 or YYYY-MM-DD format, date includes the whole day, today by default",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "IANA time zone of days, server time zone by default",
+                        "name": "tz",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Burn-down",
+                        "schema": {
+                            "$ref": "#/definitions/models.Burndown"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input data",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get burn-down",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    }
+                }
+            }
+        },
+        "/rates": {
+            "get": {
+                "description": "Retrieve rates of a user, project or task, latest effective date first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rates"
+                ],
+                "summary": "Get rates",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Project ID",
+                        "name": "project_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Task ID",
+                        "name": "task_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "List of rates",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Rate"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input data",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get rates",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Set hourly rate of exactly one of user, project or task from a date (UTC) until the next rate of the same target. Task rate overrides project rate which overrides user rate",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rates"
+                ],
+                "summary": "Create a rate",
+                "parameters": [
+                    {
+                        "description": "Rate target, hourly rate and effective date in YYYY-MM-DD format",
+                        "name": "rate",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.rateInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Rate ID",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid body data",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to create rate",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    }
+                }
+            }
+        },
+        "/rates/{id}": {
+            "delete": {
+                "description": "Delete a rate by ID. The previous rate of the target applies again",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rates"
+                ],
+                "summary": "Delete a rate",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Rate ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Rate deleted",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "400": {
+                        "description": "ID should be an integer",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "404": {
+                        "description": "Rate not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to delete rate",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/time": {
+            "get": {
+                "description": "Get time worked within the range across all users or members of a team, grouped by user, task or project. Periods are clipped to the range",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Get time report",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Range start in RFC3339 or YYYY-MM-DD format",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Range end in RFC3339 or YYYY-MM-DD format, date includes the whole day",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Grouping, can be 'user', 'task' or 'project'. Default is 'user'",
+                        "name": "group_by",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only count members of the team",
+                        "name": "team_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort order by time, can be 'asc' or 'desc'. Default is 'desc'",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Return only the first N rows",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "IANA time zone of dates without offset, server time zone by default",
+                        "name": "tz",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Time report",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.TimeReportRow"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input data",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get time report",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    }
+                }
+            }
+        },
+        "/tags": {
+            "get": {
+                "description": "Retrieve all task tags",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "Get tags",
+                "responses": {
+                    "200": {
+                        "description": "List of tags",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Tag"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get tags",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks": {
+            "get": {
+                "description": "Retrieve tasks with their tags based on filters",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "Get tasks",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tag name",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Project ID",
+                        "name": "project_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort order by creation time, can be 'asc' or 'desc'",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "List of tasks",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Task"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input data",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get tasks",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a new task for a user. Tags are matched by name, missing tags are created. Optional estimate_seconds enables remaining and overrun in task reports and estimate alerts",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "Create a new task",
+                "parameters": [
+                    {
+                        "description": "Task object",
+                        "name": "task",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Task"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Task created successfully",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid body data, negative estimate or user is not active",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to create task",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{id}": {
+            "delete": {
+                "description": "Delete a task by ID. Task with periods within an approved timesheet can not be deleted",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "Delete a task",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Task deleted",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "400": {
+                        "description": "ID should be an integer or task has approved or invoiced periods",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to delete task",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Update name, description, tags or estimate of a task. Omitted fields are kept, tags are replaced. Zero estimate removes it, changed estimate resets its alerts",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "Update a task",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Task fields to update",
+                        "name": "task",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.TaskUpdate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Task updated",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input data",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to update task",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{id}/archive": {
+            "post": {
+                "description": "Archive a done task",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "Archive a task",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Task archived",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "400": {
+                        "description": "ID should be an integer or task is not done",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to archive task",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{id}/end": {
+            "post": {
+                "description": "End a period for a task by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "End a period for a task",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Period ended",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "400": {
+                        "description": "Failed to end period. Period not started or within an approved timesheet",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to end",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{id}/finish": {
+            "post": {
+                "description": "Mark a task as done by ID. Ongoing period is ended",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "Finish a task",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Task ended",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "400": {
+                        "description": "ID should be an integer or task can not be finished",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to finish task",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{id}/periods": {
+            "get": {
+                "description": "Get all periods of a task ordered by start time",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "periods"
+                ],
+                "summary": "Get task periods",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Periods found",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.TaskPeriod"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "ID should be an integer",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get periods",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Add a finished period to a task, billable unless billable is false. Start should be before end, period can not be in the future, overlap other periods of the task or be within an approved timesheet",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "periods"
+                ],
+                "summary": "Add a period manually",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Period bounds in RFC3339 format",
+                        "name": "period",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.periodInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Period ID",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input data",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to add period",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{id}/reopen": {
+            "post": {
+                "description": "Reopen a done or archived task. Task with tracked periods becomes paused, otherwise todo",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "Reopen a task",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Task reopened",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "400": {
+                        "description": "ID should be an integer or task is not done",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to reopen task",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{id}/start": {
+            "post": {
+                "description": "Start a period for a task by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "Start a period for a task",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Period started",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "400": {
+                        "description": "Failed to start period. Period not finished, another timer is running, task is done or user is not active",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to start",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{id}/switch": {
+            "post": {
+                "description": "End all running periods of the task owner and start a period for the task atomically",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "Switch to a task",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Switched to task",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "400": {
+                        "description": "ID should be an integer, task is done or user is not active",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to switch",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    }
+                }
+            }
+        },
+        "/teams": {
+            "get": {
+                "description": "Retrieve all teams",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "teams"
+                ],
+                "summary": "Get teams",
+                "responses": {
+                    "200": {
+                        "description": "List of teams",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Team"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get teams",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a new team with the provided name",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "teams"
+                ],
+                "summary": "Create a new team",
+                "parameters": [
+                    {
+                        "description": "Team object",
+                        "name": "team",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Team"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Team ID",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid body data",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to create team",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    }
+                }
+            }
+        },
+        "/teams/{id}": {
+            "delete": {
+                "description": "Delete a team by ID. Users are kept",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "teams"
+                ],
+                "summary": "Delete a team",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Team ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Team deleted",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "400": {
+                        "description": "ID should be an integer",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to delete team",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    }
+                }
+            }
+        },
+        "/teams/{id}/members": {
+            "get": {
+                "description": "Get users of a team with their roles",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "teams"
+                ],
+                "summary": "Get team members",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Team ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Team members",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.TeamMemberInfo"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "ID should be an integer",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get team members",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Add a user to a team as 'member' or 'manager'. Updates the role if user is already in the team",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "teams"
+                ],
+                "summary": "Add a team member",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Team ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Member",
+                        "name": "member",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.addTeamMember"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Member added",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input data",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "404": {
+                        "description": "Team or user not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to add team member",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    }
+                }
+            }
+        },
+        "/teams/{id}/members/{user_id}": {
+            "delete": {
+                "description": "Remove a user from a team",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "teams"
+                ],
+                "summary": "Remove a team member",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Team ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Member removed",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "400": {
+                        "description": "ID should be an integer",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to remove team member",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    }
+                }
+            }
+        },
+        "/teams/{id}/tasks": {
+            "get": {
+                "description": "Get tasks of every team member within a specified date range and with optional sorting",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "teams"
+                ],
+                "summary": "Get team tasks",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Team ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start date in RFC3339 or YYYY-MM-DD format",
+                        "name": "start_date",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date in RFC3339 or YYYY-MM-DD format, date includes the whole day",
+                        "name": "end_date",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "IANA time zone of dates without offset and of day boundaries, server time zone by default",
+                        "name": "tz",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort order, can be 'asc' or 'desc'",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Project ID",
+                        "name": "project_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Tasks found",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.UserWithTasks"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input data",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get tasks for team",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    }
+                }
+            }
+        },
+        "/teams/{id}/users": {
+            "get": {
+                "description": "Retrieve users of a team based on filters",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "teams"
+                ],
+                "summary": "Get team users",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Team ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Passport Number",
+                        "name": "passport_number",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Surname",
+                        "name": "surname",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Name",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Patronymic",
+                        "name": "patronymic",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Address",
+                        "name": "address",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Status: active, suspended or terminated",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "List of users",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.User"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "ID should be an integer",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get users",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    }
+                }
+            }
+        },
+        "/timers/active": {
+            "get": {
+                "description": "List every running period with its task, user and elapsed time",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "timers"
+                ],
+                "summary": "Get running timers",
+                "responses": {
+                    "200": {
+                        "description": "Running timers",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.RunningPeriod"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get running timers",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    }
+                }
+            }
+        },
+        "/timesheets": {
+            "get": {
+                "description": "Get submissions of all users or of users managed by the manager, latest week first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "approvals"
+                ],
+                "summary": "Get timesheet submissions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Status, can be 'submitted', 'approved' or 'rejected'",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only submissions of members of teams managed by the user",
+                        "name": "manager_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Submissions",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.TimesheetSubmission"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input data",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get submissions",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    }
+                }
+            }
+        },
+        "/timesheets/{id}/approve": {
+            "post": {
+                "description": "Approve a submitted week by a manager of the user. Periods within the week are locked: they can not be ended, added, edited or deleted and their tasks can not be deleted",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "approvals"
+                ],
+                "summary": "Approve timesheet",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Submission ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reviewer and optional comment",
+                        "name": "review",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.reviewInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Timesheet approved",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input data or timesheet can not be approved",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to approve timesheet",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    }
+                }
+            }
+        },
+        "/timesheets/{id}/reject": {
+            "post": {
+                "description": "Reject a submitted week by a manager of the user with a comment. The user can fix periods and submit the week again",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "approvals"
+                ],
+                "summary": "Reject timesheet",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Submission ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reviewer and comment",
+                        "name": "review",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.reviewInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Timesheet rejected",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input data or timesheet can not be rejected",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to reject timesheet",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    }
+                }
+            }
+        },
+        "/users": {
+            "get": {
+                "description": "Retrieve users based on filters",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get users",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Passport Number",
+                        "name": "passport_number",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Surname",
+                        "name": "surname",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Name",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Patronymic",
+                        "name": "patronymic",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Address",
+                        "name": "address",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Email",
+                        "name": "email",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Status: active, suspended or terminated",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Team ID",
+                        "name": "team_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Single timer policy",
+                        "name": "single_timer",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "List of users",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.User"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get users",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a new user with the provided passport number",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Create a new user",
+                "parameters": [
+                    {
+                        "description": "User",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.createUser"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User ID",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid body data or invalid passport number",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to create user",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}": {
+            "put": {
+                "description": "Update a user with the provided data",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Update a user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Passport Number",
+                        "name": "passport_number",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Surname",
+                        "name": "surname",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Name",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Patronymic",
+                        "name": "patronymic",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Address",
+                        "name": "address",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Email",
+                        "name": "email",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Allow at most one running timer across all user tasks",
+                        "name": "single_timer",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Workday end in HH:MM format. Running periods are auto-closed after it. Empty or null clears it",
+                        "name": "workday_end",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "IANA time zone of user reports and workday end. Empty or null clears it",
+                        "name": "time_zone",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User updated",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "400": {
+                        "description": "Incorrect ID or invalid input data",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to update user",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a user by ID with tasks and periods. User with approved timesheets can not be deleted",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Delete a user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User deleted",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "400": {
+                        "description": "ID should be an integer or user has approved timesheets",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to delete user",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/calendar.ics": {
+            "get": {
+                "description": "Export finished periods of a user within the range as iCalendar events with task name and user",
+                "produces": [
+                    "text/calendar"
+                ],
+                "tags": [
+                    "calendar"
+                ],
+                "summary": "Export user calendar",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Range start in RFC3339 or YYYY-MM-DD format",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Range end in RFC3339 or YYYY-MM-DD format, date includes the whole day",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "IANA time zone of dates without offset, user time zone by default",
+                        "name": "tz",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "iCalendar file",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input data",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get calendar",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/calendar/token": {
+            "post": {
+                "description": "Issue a new secret token of the user calendar feed and return the subscribable URL. Previous URL stops working",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "calendar"
+                ],
+                "summary": "Create calendar feed URL",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Feed token and URL",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.calendarFeed"
+                        }
+                    },
+                    "400": {
+                        "description": "ID should be an integer",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to create calendar token",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/import/ics": {
+            "post": {
+                "description": "Create tasks and periods of a user from events of uploaded .ics files. Events are matched by category and title prefix, the prefix is stripped from task name. Already imported UIDs, all-day, recurring and future events are skipped and counted as ignored",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "calendar"
+                ],
+                "summary": "Import user calendar",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "iCalendar file, may be repeated",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Import only events with the category",
+                        "name": "category",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Import only events with title starting with the prefix",
+                        "name": "prefix",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Project of created tasks",
+                        "name": "project_id",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "IANA time zone of event times without zone, user time zone by default",
+                        "name": "tz",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Import result",
+                        "schema": {
+                            "$ref": "#/definitions/models.ImportResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input data",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "413": {
+                        "description": "Calendar files are larger than 10 MB in total",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to import calendar",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/projects": {
+            "get": {
+                "description": "Get total time of user tasks within a specified date range grouped by project",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get user time by project",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start date in RFC3339 or YYYY-MM-DD format",
+                        "name": "start_date",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date in RFC3339 or YYYY-MM-DD format, date includes the whole day",
+                        "name": "end_date",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "IANA time zone of dates without offset and of day boundaries, user time zone by default",
+                        "name": "tz",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Projects found",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ProjectWithTotalTime"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input data",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get projects for user",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/reactivate": {
+            "post": {
+                "description": "Reactivate a suspended user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Reactivate a user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Effective date in RFC3339 format, defaults to now. Can not be in the future",
+                        "name": "effective_date",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User reactivated",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input data or invalid status transition",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to reactivate user",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/subordinates": {
+            "get": {
+                "description": "Get members of all teams where the user is a manager",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get subordinates",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "List of users",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.User"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "ID should be an integer",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get subordinates",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/summary": {
+            "get": {
+                "description": "Get time worked by a user in every day, week (from Monday) or month of the range, in total and per task. Periods crossing a boundary are split between buckets",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get user time summary",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Bucket size, can be 'day', 'week' or 'month'. Default is 'day'",
+                        "name": "group_by",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Range start in RFC3339 or YYYY-MM-DD format",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Range end in RFC3339 or YYYY-MM-DD format, date includes the whole day. Range is at most 3660 days",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "IANA time zone of dates without offset and of day boundaries, user time zone by default",
+                        "name": "tz",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Summary",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.SummaryBucket"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input data or too long range",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get summary for user",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/suspend": {
+            "post": {
+                "description": "Suspend an active user. Suspended users can not create tasks or start periods",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Suspend a user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Effective date in RFC3339 format, defaults to now. Can not be in the future",
+                        "name": "effective_date",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User suspended",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input data or invalid status transition",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to suspend user",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/tags": {
+            "get": {
+                "description": "Get total time of user tasks within a specified date range grouped by tag. Task with several tags counts in each of them",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get user time by tag",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start date in RFC3339 or YYYY-MM-DD format",
+                        "name": "start_date",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date in RFC3339 or YYYY-MM-DD format, date includes the whole day",
+                        "name": "end_date",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "IANA time zone of dates without offset and of day boundaries, user time zone by default",
+                        "name": "tz",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Tags found",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.TagWithTotalTime"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input data",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get tags for user",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/tasks": {
+            "get": {
+                "description": "Get tasks a user worked on within a specified date range and with optional sorting. Only time inside the range is counted",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get user tasks",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start date in RFC3339 or YYYY-MM-DD format",
+                        "name": "start_date",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date in RFC3339 or YYYY-MM-DD format, date includes the whole day",
+                        "name": "end_date",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "IANA time zone of dates without offset and of day boundaries, user time zone by default",
+                        "name": "tz",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort order, can be 'asc' or 'desc'",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Project ID",
+                        "name": "project_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Tag name",
+                        "name": "tag",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Tasks found",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Task"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input data",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get tasks for user",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/terminate": {
+            "post": {
+                "description": "Terminate an active or suspended user. Unlike delete, tasks and periods are kept",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Terminate a user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Effective date in RFC3339 format, defaults to now. Can not be in the future",
+                        "name": "effective_date",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User terminated",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input data or invalid status transition",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to terminate user",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/timers/active": {
+            "get": {
+                "description": "List running periods of a user with task and elapsed time",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "timers"
+                ],
+                "summary": "Get running timers of a user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Running timers",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.RunningPeriod"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "ID should be an integer",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get running timers",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/timesheet.html": {
+            "get": {
+                "description": "Render HTML timesheet of a user with task totals of the range, grand total and signatures block. It has a print stylesheet for A4",
+                "produces": [
+                    "text/html"
+                ],
+                "tags": [
+                    "timesheets"
+                ],
+                "summary": "Get printable timesheet",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Range start in RFC3339 or YYYY-MM-DD format",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Range end in RFC3339 or YYYY-MM-DD format, date includes the whole day",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "IANA time zone of dates without offset, user time zone by default",
+                        "name": "tz",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "HTML timesheet",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input data",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get timesheet",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/timesheet.pdf": {
+            "get": {
+                "description": "Render PDF timesheet of a user with task totals of the range, grand total and signatures block. Text is set in embedded DejaVu Sans",
+                "produces": [
+                    "application/pdf"
+                ],
+                "tags": [
+                    "timesheets"
+                ],
+                "summary": "Get timesheet PDF",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Range start in RFC3339 or YYYY-MM-DD format",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Range end in RFC3339 or YYYY-MM-DD format, date includes the whole day",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "IANA time zone of dates without offset, user time zone by default",
+                        "name": "tz",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "PDF timesheet",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input data",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get timesheet",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/timesheet.xlsx": {
+            "get": {
+                "description": "Export workbook with hours of a user per task and day of the week (from Monday), totals and a summary sheet",
+                "produces": [
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+                ],
+                "tags": [
+                    "timesheets"
+                ],
+                "summary": "Export weekly timesheet as XLSX",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Any date of the week in RFC3339 or YYYY-MM-DD format, current week by default",
+                        "name": "week",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "IANA time zone of the week days, user time zone by default",
+                        "name": "tz",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "XLSX workbook",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input data",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get timesheet",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/timesheets": {
+            "get": {
+                "description": "Get submissions of a user with review status and comments, latest week first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "approvals"
+                ],
+                "summary": "Get user timesheet submissions",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Status, can be 'submitted', 'approved' or 'rejected'",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Submissions",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.TimesheetSubmission"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input data",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get submissions",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Submit periods of a finished week (from Monday) for approval by a manager of the user. Week with a running timer or already submitted can not be submitted, rejected week can be submitted again",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "approvals"
+                ],
+                "summary": "Submit weekly timesheet",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Any date of the week in RFC3339 or YYYY-MM-DD format, previous week by default",
+                        "name": "week",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "IANA time zone of the week days, user time zone by default",
+                        "name": "tz",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Submission ID",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input data or week can not be submitted",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to submit timesheet",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Message"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "handlers.Message": {
+            "type": "object",
+            "properties": {
+                "msg": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.addTeamMember": {
+            "type": "object",
+            "required": [
+                "user_id"
+            ],
+            "properties": {
+                "role": {
+                    "$ref": "#/definitions/models.TeamRole"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.billableInput": {
+            "type": "object",
+            "required": [
+                "billable"
+            ],
+            "properties": {
+                "billable": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "handlers.calendarFeed": {
+            "type": "object",
+            "properties": {
+                "token": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.createUser": {
+            "type": "object",
+            "required": [
+                "passportNumber"
+            ],
+            "properties": {
+                "passportNumber": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.invoiceInput": {
+            "type": "object",
+            "required": [
+                "from",
+                "to"
+            ],
+            "properties": {
+                "client": {
+                    "type": "string"
+                },
+                "from": {
+                    "type": "string",
+                    "example": "2024-07-01"
+                },
+                "project_id": {
+                    "type": "integer"
+                },
+                "to": {
+                    "type": "string",
+                    "example": "2024-07-31"
+                }
+            }
+        },
+        "handlers.periodInput": {
+            "type": "object",
+            "required": [
+                "end_time",
+                "start_time"
+            ],
+            "properties": {
+                "billable": {
+                    "description": "billable by default, ignored on update",
+                    "type": "boolean"
+                },
+                "end_time": {
+                    "type": "string"
+                },
+                "start_time": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.rateInput": {
+            "type": "object",
+            "required": [
+                "effective_from",
+                "hourly_rate"
+            ],
+            "properties": {
+                "effective_from": {
+                    "type": "string",
+                    "example": "2024-07-01"
+                },
+                "hourly_rate": {
+                    "type": "number"
+                },
+                "project_id": {
+                    "type": "integer"
+                },
+                "task_id": {
+                    "type": "integer"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.reviewInput": {
+            "type": "object",
+            "required": [
+                "reviewer_id"
+            ],
+            "properties": {
+                "comment": {
+                    "type": "string"
+                },
+                "reviewer_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Burndown": {
+            "type": "object",
+            "properties": {
+                "budget_amount": {
+                    "type": "number"
+                },
+                "budget_hours": {
+                    "type": "number"
+                },
+                "days": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BurndownDay"
+                    }
+                },
+                "project_id": {
+                    "type": "integer"
+                },
+                "time_zone": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.BurndownDay": {
+            "type": "object",
+            "properties": {
+                "cost": {
+                    "type": "number"
+                },
+                "cumulative_cost": {
+                    "type": "number"
+                },
+                "cumulative_hours": {
+                    "type": "number"
+                },
+                "cumulative_seconds": {
+                    "type": "integer"
+                },
+                "date": {
+                    "type": "string",
+                    "example": "2024-07-01"
+                },
+                "remaining_amount": {
+                    "description": "negative once over budget",
+                    "type": "number"
+                },
+                "remaining_hours": {
+                    "description": "negative once over budget",
+                    "type": "number"
+                },
+                "seconds": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.CSVFormat": {
+            "type": "string",
+            "enum": [
+                "toggl",
+                "clockify"
+            ],
+            "x-enum-varnames": [
+                "CSVToggl",
+                "CSVClockify"
+            ]
+        },
+        "models.CSVImportResult": {
+            "type": "object",
+            "properties": {
+                "dry_run": {
+                    "type": "boolean"
+                },
+                "duplicates": {
+                    "description": "already imported external ids",
+                    "type": "integer"
+                },
+                "entries": {
+                    "description": "periods to import, only on dry run",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ImportEntry"
+                    }
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "format": {
+                    "$ref": "#/definitions/models.CSVFormat"
+                },
+                "ignored": {
+                    "description": "not matching import rules or invalid",
+                    "type": "integer"
+                },
+                "imported": {
+                    "type": "integer"
+                },
+                "locked": {
+                    "description": "within weeks of approved timesheets",
+                    "type": "integer"
+                },
+                "overlapping": {
+                    "description": "overlap periods of the task",
+                    "type": "integer"
+                },
+                "tasks_created": {
+                    "type": "integer"
+                },
+                "unknown_people": {
+                    "description": "emails and passports without user",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.ImportEntry": {
+            "type": "object",
+            "properties": {
+                "billable": {
+                    "description": "billable if not set",
+                    "type": "boolean"
+                },
+                "end_time": {
+                    "type": "string"
+                },
+                "external_id": {
+                    "type": "string"
+                },
+                "project_id": {
+                    "type": "integer"
+                },
+                "start_time": {
+                    "type": "string"
+                },
+                "task_name": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ImportResult": {
+            "type": "object",
+            "properties": {
+                "duplicates": {
+                    "description": "already imported external ids",
+                    "type": "integer"
+                },
+                "ignored": {
+                    "description": "not matching import rules or invalid",
+                    "type": "integer"
+                },
+                "imported": {
+                    "type": "integer"
+                },
+                "locked": {
+                    "description": "within weeks of approved timesheets",
+                    "type": "integer"
+                },
+                "overlapping": {
+                    "description": "overlap periods of the task",
+                    "type": "integer"
+                },
+                "tasks_created": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Invoice": {
+            "type": "object",
+            "properties": {
+                "client": {
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "from": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "issued_at": {
+                    "type": "string"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.InvoiceItem"
+                    }
+                },
+                "number": {
+                    "description": "YYYY-NNNN, sequential within a year",
+                    "type": "string"
+                },
+                "project_id": {
+                    "type": "integer"
+                },
+                "time_zone": {
+                    "description": "dates of the range are printed in it",
+                    "type": "string"
+                },
+                "to": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "number"
+                },
+                "total_seconds": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.InvoiceItem": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "hourly_rate": {
+                    "type": "number"
+                },
+                "hours": {
+                    "type": "number"
+                },
+                "position": {
+                    "type": "integer"
+                },
+                "seconds": {
+                    "type": "integer"
+                },
+                "task_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Project": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "budget_amount": {
+                    "description": "budget of cost, no money budget if null",
+                    "type": "number"
+                },
+                "budget_hours": {
+                    "description": "no hour budget if null",
+                    "type": "number"
+                },
+                "client": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ProjectWithTotalTime": {
+            "type": "object",
+            "properties": {
+                "billable_amount": {
+                    "type": "number"
+                },
+                "billable_seconds": {
+                    "type": "integer"
+                },
+                "cost": {
+                    "type": "number"
+                },
+                "duration": {
+                    "type": "string",
+                    "example": "PT1H30M"
+                },
+                "duration_hours": {
+                    "type": "integer"
+                },
+                "duration_minutes": {
+                    "type": "integer"
+                },
+                "duration_seconds": {
+                    "type": "integer"
+                },
+                "project_id": {
+                    "type": "integer"
+                },
+                "project_name": {
+                    "type": "string"
+                },
+                "tasks_count": {
+                    "type": "integer"
+                },
+                "total_seconds": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Rate": {
+            "type": "object",
+            "properties": {
+                "effective_from": {
+                    "type": "string"
+                },
+                "hourly_rate": {
+                    "type": "number"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "project_id": {
+                    "type": "integer"
+                },
+                "task_id": {
+                    "type": "integer"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.RunningPeriod": {
+            "type": "object",
+            "properties": {
+                "auto_closed": {
+                    "type": "boolean"
+                },
+                "billable": {
+                    "type": "boolean"
+                },
+                "elapsed_seconds": {
+                    "type": "integer"
+                },
+                "end_time": {
+                    "type": "string"
+                },
+                "external_id": {
+                    "description": "source id of imported period",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "invoice_id": {
+                    "description": "set once the period is billed",
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "start_time": {
+                    "type": "string"
+                },
+                "surname": {
+                    "type": "string"
+                },
+                "task_id": {
+                    "type": "integer"
+                },
+                "task_name": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.SubmissionStatus": {
+            "type": "string",
+            "enum": [
+                "submitted",
+                "approved",
+                "rejected"
+            ],
+            "x-enum-varnames": [
+                "SubmissionSubmitted",
+                "SubmissionApproved",
+                "SubmissionRejected"
+            ]
+        },
+        "models.SummaryBucket": {
+            "type": "object",
+            "properties": {
+                "duration": {
+                    "type": "string",
+                    "example": "PT1H30M"
+                },
+                "duration_hours": {
+                    "type": "integer"
+                },
+                "duration_minutes": {
+                    "type": "integer"
+                },
+                "duration_seconds": {
+                    "type": "integer"
+                },
+                "end": {
+                    "type": "string"
+                },
+                "start": {
+                    "type": "string"
+                },
+                "tasks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SummaryTask"
+                    }
+                },
+                "total_seconds": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.SummaryTask": {
+            "type": "object",
+            "properties": {
+                "duration": {
+                    "type": "string",
+                    "example": "PT1H30M"
+                },
+                "duration_hours": {
+                    "type": "integer"
+                },
+                "duration_minutes": {
+                    "type": "integer"
+                },
+                "duration_seconds": {
+                    "type": "integer"
+                },
+                "task_id": {
+                    "type": "integer"
+                },
+                "task_name": {
+                    "type": "string"
+                },
+                "total_seconds": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Tag": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.TagWithTotalTime": {
+            "type": "object",
+            "properties": {
+                "billable_amount": {
+                    "type": "number"
+                },
+                "billable_seconds": {
+                    "type": "integer"
+                },
+                "cost": {
+                    "type": "number"
+                },
+                "duration": {
+                    "type": "string",
+                    "example": "PT1H30M"
+                },
+                "duration_hours": {
+                    "type": "integer"
+                },
+                "duration_minutes": {
+                    "type": "integer"
+                },
+                "duration_seconds": {
+                    "type": "integer"
+                },
+                "tag": {
+                    "type": "string"
+                },
+                "tasks_count": {
+                    "type": "integer"
+                },
+                "total_seconds": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Task": {
+            "type": "object",
+            "required": [
+                "task_name",
+                "user_id"
+            ],
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "estimate_seconds": {
+                    "description": "planned time, no estimate if null",
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "project_id": {
+                    "type": "integer"
+                },
+                "status": {
+                    "$ref": "#/definitions/models.TaskStatus"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Tag"
+                    }
+                },
+                "task_name": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
             }
-        }
-    },
-    "definitions": {
-        "handlers.Message": {
+        },
+        "models.TaskAlert": {
             "type": "object",
             "properties": {
-                "msg": {
+                "created_at": {
                     "type": "string"
+                },
+                "estimate_seconds": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "task_id": {
+                    "type": "integer"
+                },
+                "threshold": {
+                    "type": "integer"
+                },
+                "tracked_seconds": {
+                    "type": "integer"
+                },
+                "user_id": {
+                    "type": "integer"
                 }
             }
         },
-        "handlers.createUser": {
+        "models.TaskPeriod": {
             "type": "object",
-            "required": [
-                "passportNumber"
+            "properties": {
+                "auto_closed": {
+                    "type": "boolean"
+                },
+                "billable": {
+                    "type": "boolean"
+                },
+                "end_time": {
+                    "type": "string"
+                },
+                "external_id": {
+                    "description": "source id of imported period",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "invoice_id": {
+                    "description": "set once the period is billed",
+                    "type": "integer"
+                },
+                "start_time": {
+                    "type": "string"
+                },
+                "task_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.TaskStatus": {
+            "type": "string",
+            "enum": [
+                "todo",
+                "in_progress",
+                "paused",
+                "done",
+                "archived"
             ],
+            "x-enum-varnames": [
+                "TaskTodo",
+                "TaskInProgress",
+                "TaskPaused",
+                "TaskDone",
+                "TaskArchived"
+            ]
+        },
+        "models.TaskUpdate": {
+            "type": "object",
             "properties": {
-                "passportNumber": {
+                "description": {
+                    "type": "string"
+                },
+                "estimate_seconds": {
+                    "description": "zero removes the estimate",
+                    "type": "integer"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "task_name": {
                     "type": "string"
                 }
             }
         },
-        "models.Task": {
+        "models.TaskWithTotalTime": {
             "type": "object",
             "required": [
                 "task_name",
                 "user_id"
             ],
             "properties": {
+                "billable_amount": {
+                    "type": "number"
+                },
+                "billable_seconds": {
+                    "type": "integer"
+                },
+                "cost": {
+                    "type": "number"
+                },
                 "created_at": {
                     "type": "string"
                 },
+                "description": {
+                    "type": "string"
+                },
+                "duration": {
+                    "type": "string",
+                    "example": "PT1H30M"
+                },
+                "duration_hours": {
+                    "type": "integer"
+                },
+                "duration_minutes": {
+                    "type": "integer"
+                },
+                "duration_seconds": {
+                    "type": "integer"
+                },
+                "estimate_seconds": {
+                    "description": "planned time, no estimate if null",
+                    "type": "integer"
+                },
+                "estimate_used": {
+                    "description": "percent of estimate tracked",
+                    "type": "number"
+                },
                 "id": {
                     "type": "integer"
                 },
-                "is_finished": {
-                    "type": "boolean"
+                "overrun_seconds": {
+                    "type": "integer"
+                },
+                "project_id": {
+                    "type": "integer"
+                },
+                "remaining_seconds": {
+                    "type": "integer"
+                },
+                "status": {
+                    "$ref": "#/definitions/models.TaskStatus"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Tag"
+                    }
                 },
                 "task_name": {
                     "type": "string"
                 },
+                "total_seconds": {
+                    "type": "integer"
+                },
+                "tracked_seconds": {
+                    "type": "integer"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Team": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.TeamMemberInfo": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "passport_number": {
+                    "type": "string"
+                },
+                "patronymic": {
+                    "type": "string"
+                },
+                "role": {
+                    "$ref": "#/definitions/models.TeamRole"
+                },
+                "single_timer": {
+                    "description": "at most one running period across all user tasks",
+                    "type": "boolean"
+                },
+                "status": {
+                    "$ref": "#/definitions/models.UserStatus"
+                },
+                "status_changed_at": {
+                    "type": "string"
+                },
+                "surname": {
+                    "type": "string"
+                },
+                "terminated_at": {
+                    "type": "string"
+                },
+                "time_zone": {
+                    "description": "IANA name, server time zone if empty",
+                    "type": "string"
+                },
+                "workday_end": {
+                    "description": "HH:MM after which running periods are auto-closed",
+                    "type": "string"
+                }
+            }
+        },
+        "models.TeamRole": {
+            "type": "string",
+            "enum": [
+                "member",
+                "manager"
+            ],
+            "x-enum-varnames": [
+                "TeamMemberRole",
+                "TeamManagerRole"
+            ]
+        },
+        "models.TimeReportRow": {
+            "type": "object",
+            "properties": {
+                "billable_amount": {
+                    "type": "number"
+                },
+                "billable_seconds": {
+                    "type": "integer"
+                },
+                "cost": {
+                    "type": "number"
+                },
+                "duration": {
+                    "type": "string",
+                    "example": "PT1H30M"
+                },
+                "duration_hours": {
+                    "type": "integer"
+                },
+                "duration_minutes": {
+                    "type": "integer"
+                },
+                "duration_seconds": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "tasks_count": {
+                    "type": "integer"
+                },
+                "total_seconds": {
+                    "type": "integer"
+                },
+                "users_count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.TimesheetSubmission": {
+            "type": "object",
+            "properties": {
+                "comment": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "reviewed_at": {
+                    "type": "string"
+                },
+                "reviewer_id": {
+                    "type": "integer"
+                },
+                "status": {
+                    "$ref": "#/definitions/models.SubmissionStatus"
+                },
+                "submitted_at": {
+                    "type": "string"
+                },
+                "time_zone": {
+                    "type": "string"
+                },
+                "total_seconds": {
+                    "description": "time within the week on submission, updated on approval",
+                    "type": "integer"
+                },
                 "user_id": {
                     "type": "integer"
+                },
+                "week_end": {
+                    "type": "string"
+                },
+                "week_start": {
+                    "type": "string"
                 }
             }
         },
@@ -579,6 +4766,9 @@ const docTemplate = `{
                 "address": {
                     "type": "string"
                 },
+                "email": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "integer"
                 },
@@ -591,8 +4781,56 @@ const docTemplate = `{
                 "patronymic": {
                     "type": "string"
                 },
+                "single_timer": {
+                    "description": "at most one running period across all user tasks",
+                    "type": "boolean"
+                },
+                "status": {
+                    "$ref": "#/definitions/models.UserStatus"
+                },
+                "status_changed_at": {
+                    "type": "string"
+                },
                 "surname": {
                     "type": "string"
+                },
+                "terminated_at": {
+                    "type": "string"
+                },
+                "time_zone": {
+                    "description": "IANA name, server time zone if empty",
+                    "type": "string"
+                },
+                "workday_end": {
+                    "description": "HH:MM after which running periods are auto-closed",
+                    "type": "string"
+                }
+            }
+        },
+        "models.UserStatus": {
+            "type": "string",
+            "enum": [
+                "active",
+                "suspended",
+                "terminated"
+            ],
+            "x-enum-varnames": [
+                "UserActive",
+                "UserSuspended",
+                "UserTerminated"
+            ]
+        },
+        "models.UserWithTasks": {
+            "type": "object",
+            "properties": {
+                "tasks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TaskWithTotalTime"
+                    }
+                },
+                "user": {
+                    "$ref": "#/definitions/models.User"
                 }
             }
         }
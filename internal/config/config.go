@@ -1,11 +1,33 @@
 package config
 
 import (
+	"fmt"
+	"net/url"
 	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/moxicom/user_test/internal/storage/postgres"
 )
 
+const (
+	defaultAutoStopInterval = 5 * time.Minute
+	defaultInvoiceCurrency  = "USD"
+	defaultEstimateInterval = 5 * time.Minute
+)
+
+const (
+	roundNearest = "nearest"
+	roundUp      = "up"
+)
+
+var defaultEstimateThresholds = []int{80, 100, 150}
+
+var currencyCode = regexp.MustCompile(`^[A-Z]{3}$`)
+
 func InitDbConfig() postgres.PgConfig {
 	return postgres.PgConfig{
 		Host:     os.Getenv("DB_HOST"),
@@ -16,3 +38,142 @@ func InitDbConfig() postgres.PgConfig {
 		SSLMode:  os.Getenv("SSL_MODE"),
 	}
 }
+
+type AutoStopConfig struct {
+	MaxPeriod time.Duration // zero disables the limit
+	Interval  time.Duration
+}
+
+type RoundingConfig struct {
+	Minutes int    // zero disables rounding
+	Mode    string // nearest or up
+}
+
+type InvoiceConfig struct {
+	Currency string // ISO 4217 code of amounts
+	Issuer   string // name of the invoicing company
+}
+
+type ServerConfig struct {
+	PublicURL string // scheme and host of links given to clients, empty to use the request host
+}
+
+type EstimateAlertConfig struct {
+	Thresholds []int // percents of estimate, ascending
+	Interval   time.Duration
+	WebhookURL string // new alerts are posted to it, empty to only record them
+}
+
+func InitAutoStopConfig() (AutoStopConfig, error) {
+	cfg := AutoStopConfig{Interval: defaultAutoStopInterval}
+
+	if v := os.Getenv("MAX_PERIOD_DURATION"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return cfg, fmt.Errorf("MAX_PERIOD_DURATION: %w", err)
+		}
+		cfg.MaxPeriod = d
+	}
+
+	if v := os.Getenv("AUTO_STOP_INTERVAL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return cfg, fmt.Errorf("AUTO_STOP_INTERVAL should be a positive duration: %s", v)
+		}
+		cfg.Interval = d
+	}
+
+	return cfg, nil
+}
+
+func InitRoundingConfig() (RoundingConfig, error) {
+	cfg := RoundingConfig{Mode: roundNearest}
+
+	if v := os.Getenv("ROUNDING_MINUTES"); v != "" {
+		m, err := strconv.Atoi(v)
+		if err != nil || m < 0 {
+			return cfg, fmt.Errorf("ROUNDING_MINUTES should be a non-negative integer: %s", v)
+		}
+		cfg.Minutes = m
+	}
+
+	if v := os.Getenv("ROUNDING_MODE"); v != "" {
+		if v != roundNearest && v != roundUp {
+			return cfg, fmt.Errorf("ROUNDING_MODE should be nearest or up: %s", v)
+		}
+		cfg.Mode = v
+	}
+
+	return cfg, nil
+}
+
+func InitInvoiceConfig() (InvoiceConfig, error) {
+	cfg := InvoiceConfig{
+		Currency: defaultInvoiceCurrency,
+		Issuer:   os.Getenv("INVOICE_ISSUER"),
+	}
+
+	if v := os.Getenv("INVOICE_CURRENCY"); v != "" {
+		if !currencyCode.MatchString(v) {
+			return cfg, fmt.Errorf("INVOICE_CURRENCY should be an ISO 4217 code: %s", v)
+		}
+		cfg.Currency = v
+	}
+
+	return cfg, nil
+}
+
+func InitServerConfig() (ServerConfig, error) {
+	var cfg ServerConfig
+
+	if v := os.Getenv("PUBLIC_URL"); v != "" {
+		u, err := url.Parse(v)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return cfg, fmt.Errorf("PUBLIC_URL should be an absolute http or https URL: %s", v)
+		}
+		cfg.PublicURL = strings.TrimSuffix(v, "/")
+	}
+
+	return cfg, nil
+}
+
+func InitEstimateAlertConfig() (EstimateAlertConfig, error) {
+	cfg := EstimateAlertConfig{
+		Thresholds: defaultEstimateThresholds,
+		Interval:   defaultEstimateInterval,
+	}
+
+	if v := os.Getenv("ESTIMATE_THRESHOLDS"); v != "" {
+		seen := make(map[int]bool)
+		cfg.Thresholds = nil
+		for _, part := range strings.Split(v, ",") {
+			t, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil || t <= 0 {
+				return cfg, fmt.Errorf("ESTIMATE_THRESHOLDS should be comma separated positive percents: %s", v)
+			}
+			if !seen[t] {
+				seen[t] = true
+				cfg.Thresholds = append(cfg.Thresholds, t)
+			}
+		}
+		sort.Ints(cfg.Thresholds)
+	}
+
+	if v := os.Getenv("ESTIMATE_CHECK_INTERVAL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return cfg, fmt.Errorf("ESTIMATE_CHECK_INTERVAL should be a positive duration: %s", v)
+		}
+		cfg.Interval = d
+	}
+
+	if v := os.Getenv("ESTIMATE_WEBHOOK_URL"); v != "" {
+		u, err := url.Parse(v)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return cfg, fmt.Errorf("ESTIMATE_WEBHOOK_URL should be an absolute http or https URL: %s", v)
+		}
+		cfg.WebhookURL = v
+	}
+
+	return cfg, nil
+}
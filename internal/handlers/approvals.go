@@ -0,0 +1,232 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/moxicom/user_test/internal/models"
+	"github.com/moxicom/user_test/internal/services"
+	"github.com/moxicom/user_test/internal/storage"
+	"github.com/moxicom/user_test/internal/utils"
+)
+
+type reviewInput struct {
+	ReviewerID uint   `json:"reviewer_id" binding:"required"`
+	Comment    string `json:"comment"`
+}
+
+// approvalErrors are caused by invalid submission or review and reported as bad request
+var approvalErrors = []error{
+	services.ErrWeekNotOver,
+	services.ErrNotManager,
+	services.ErrEmptyComment,
+	services.ErrInvalidSubmissionStatus,
+	storage.ErrWeekTimerRunning,
+	storage.ErrAlreadySubmitted,
+	storage.ErrNotSubmitted,
+}
+
+func isApprovalError(err error) bool {
+	for _, target := range approvalErrors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// SubmitTimesheet submits a week of a user for approval
+// @Summary Submit weekly timesheet
+// @Description Submit periods of a finished week (from Monday) for approval by a manager of the user. Week with a running timer or already submitted can not be submitted, rejected week can be submitted again
+// @Tags approvals
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param week query string false "Any date of the week in RFC3339 or YYYY-MM-DD format, previous week by default"
+// @Param tz query string false "IANA time zone of the week days, user time zone by default"
+// @Success 200 {object} Message "Submission ID"
+// @Failure 400 {object} Message "Invalid input data or week can not be submitted"
+// @Failure 404 {object} Message "User not found"
+// @Failure 500 {object} Message "Failed to submit timesheet"
+// @Router /users/{id}/timesheets [post]
+func (h *Handler) SubmitTimesheet(c *gin.Context) {
+	log := h.log.With(slog.String("op", "handler.SubmitTimesheet"))
+	id := c.Param("id")
+	id64, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		log.Warn("Invalid user ID format", slog.String("id", id), slog.Any("err", err))
+		c.JSON(http.StatusBadRequest, Message{"id should be integer"})
+		return
+	}
+
+	loc, ok := h.userLocation(c, log, uint(id64))
+	if !ok {
+		return
+	}
+
+	day := time.Now().AddDate(0, 0, -7)
+	if v := c.Query("week"); v != "" {
+		if day, err = utils.ParseTime(v, loc, false); err != nil {
+			log.Warn("Invalid week", slog.String("week", v), slog.Any("err", err))
+			c.JSON(http.StatusBadRequest, Message{"Invalid week"})
+			return
+		}
+	}
+
+	submissionID, err := h.service.Approval.SubmitTimesheet(uint(id64), day, loc)
+	if err != nil {
+		if isApprovalError(err) {
+			log.Warn("Failed to submit timesheet", slog.Uint64("user_id", id64), slog.Any("err", err))
+			c.JSON(http.StatusBadRequest, Message{err.Error()})
+			return
+		}
+		log.Error("Failed to submit timesheet", slog.Uint64("user_id", id64), slog.Any("err", err))
+		c.JSON(http.StatusInternalServerError, Message{"Failed to submit timesheet"})
+		return
+	}
+
+	log.Info("Timesheet submitted", slog.Uint64("user_id", id64), slog.Uint64("submission_id", uint64(submissionID)))
+	c.JSON(http.StatusOK, Message{fmt.Sprint(submissionID)})
+}
+
+// GetUserSubmissions gets timesheet submissions of a user
+// @Summary Get user timesheet submissions
+// @Description Get submissions of a user with review status and comments, latest week first
+// @Tags approvals
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param status query string false "Status, can be 'submitted', 'approved' or 'rejected'"
+// @Success 200 {array} models.TimesheetSubmission "Submissions"
+// @Failure 400 {object} Message "Invalid input data"
+// @Failure 500 {object} Message "Failed to get submissions"
+// @Router /users/{id}/timesheets [get]
+func (h *Handler) GetUserSubmissions(c *gin.Context) {
+	log := h.log.With(slog.String("op", "handler.GetUserSubmissions"))
+	id := c.Param("id")
+	id64, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		log.Warn("Invalid user ID format", slog.String("id", id), slog.Any("err", err))
+		c.JSON(http.StatusBadRequest, Message{"id should be integer"})
+		return
+	}
+
+	h.getSubmissions(c, log, models.SubmissionFilters{
+		UserID: uint(id64),
+		Status: models.SubmissionStatus(c.Query("status")),
+	})
+}
+
+// GetSubmissions gets timesheet submissions
+// @Summary Get timesheet submissions
+// @Description Get submissions of all users or of users managed by the manager, latest week first
+// @Tags approvals
+// @Accept json
+// @Produce json
+// @Param status query string false "Status, can be 'submitted', 'approved' or 'rejected'"
+// @Param manager_id query int false "Only submissions of members of teams managed by the user"
+// @Success 200 {array} models.TimesheetSubmission "Submissions"
+// @Failure 400 {object} Message "Invalid input data"
+// @Failure 500 {object} Message "Failed to get submissions"
+// @Router /timesheets [get]
+func (h *Handler) GetSubmissions(c *gin.Context) {
+	log := h.log.With(slog.String("op", "handler.GetSubmissions"))
+
+	filters := models.SubmissionFilters{Status: models.SubmissionStatus(c.Query("status"))}
+	if v := c.Query("manager_id"); v != "" {
+		managerID, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			log.Warn("Invalid manager ID format", slog.String("manager_id", v), slog.Any("err", err))
+			c.JSON(http.StatusBadRequest, Message{"manager_id should be integer"})
+			return
+		}
+		filters.ManagerID = uint(managerID)
+	}
+
+	h.getSubmissions(c, log, filters)
+}
+
+func (h *Handler) getSubmissions(c *gin.Context, log *slog.Logger, filters models.SubmissionFilters) {
+	subs, err := h.service.Approval.GetSubmissions(filters)
+	if err != nil {
+		if isApprovalError(err) {
+			log.Warn("Invalid submission filters", slog.Any("err", err))
+			c.JSON(http.StatusBadRequest, Message{err.Error()})
+			return
+		}
+		log.Error("Failed to get submissions", slog.Any("err", err))
+		c.JSON(http.StatusInternalServerError, Message{"Failed to get submissions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, subs)
+}
+
+// ApproveTimesheet approves a submitted timesheet
+// @Summary Approve timesheet
+// @Description Approve a submitted week by a manager of the user. Periods within the week are locked: they can not be ended, added, edited or deleted and their tasks can not be deleted
+// @Tags approvals
+// @Accept json
+// @Produce json
+// @Param id path int true "Submission ID"
+// @Param review body reviewInput true "Reviewer and optional comment"
+// @Success 200 {object} Message "Timesheet approved"
+// @Failure 400 {object} Message "Invalid input data or timesheet can not be approved"
+// @Failure 500 {object} Message "Failed to approve timesheet"
+// @Router /timesheets/{id}/approve [post]
+func (h *Handler) ApproveTimesheet(c *gin.Context) {
+	h.reviewTimesheet(c, "handler.ApproveTimesheet", h.service.Approval.ApproveTimesheet, "timesheet approved")
+}
+
+// RejectTimesheet rejects a submitted timesheet
+// @Summary Reject timesheet
+// @Description Reject a submitted week by a manager of the user with a comment. The user can fix periods and submit the week again
+// @Tags approvals
+// @Accept json
+// @Produce json
+// @Param id path int true "Submission ID"
+// @Param review body reviewInput true "Reviewer and comment"
+// @Success 200 {object} Message "Timesheet rejected"
+// @Failure 400 {object} Message "Invalid input data or timesheet can not be rejected"
+// @Failure 500 {object} Message "Failed to reject timesheet"
+// @Router /timesheets/{id}/reject [post]
+func (h *Handler) RejectTimesheet(c *gin.Context) {
+	h.reviewTimesheet(c, "handler.RejectTimesheet", h.service.Approval.RejectTimesheet, "timesheet rejected")
+}
+
+func (h *Handler) reviewTimesheet(c *gin.Context, op string, review func(uint, uint, string) error, msg string) {
+	log := h.log.With(slog.String("op", op))
+	id := c.Param("id")
+	id64, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		log.Warn("Invalid submission ID format", slog.String("id", id), slog.Any("err", err))
+		c.JSON(http.StatusBadRequest, Message{"id should be integer"})
+		return
+	}
+
+	var input reviewInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		log.Error("error while parsing json ", slog.Any("err", err))
+		c.JSON(http.StatusBadRequest, Message{"invalid body data"})
+		return
+	}
+
+	if err := review(uint(id64), input.ReviewerID, input.Comment); err != nil {
+		if isApprovalError(err) {
+			log.Warn("Failed to review timesheet", slog.Uint64("submission_id", id64), slog.Any("err", err))
+			c.JSON(http.StatusBadRequest, Message{err.Error()})
+			return
+		}
+		log.Error("Failed to review timesheet", slog.Uint64("submission_id", id64), slog.Any("err", err))
+		c.JSON(http.StatusInternalServerError, Message{"Failed to review timesheet"})
+		return
+	}
+
+	log.Info("Timesheet reviewed", slog.Uint64("submission_id", id64), slog.Uint64("reviewer_id", uint64(input.ReviewerID)))
+	c.JSON(http.StatusOK, Message{msg})
+}
@@ -0,0 +1,243 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/moxicom/user_test/internal/models"
+	"github.com/moxicom/user_test/internal/services"
+	"github.com/moxicom/user_test/internal/storage"
+)
+
+const (
+	calendarContentType = "text/calendar; charset=utf-8"
+	maxCalendarUpload   = 10 << 20 // bytes of an import request
+)
+
+type calendarFeed struct {
+	Token string `json:"token"`
+	URL   string `json:"url"`
+}
+
+// GetUserCalendar exports periods of a user as iCalendar
+// @Summary Export user calendar
+// @Description Export finished periods of a user within the range as iCalendar events with task name and user
+// @Tags calendar
+// @Produce text/calendar
+// @Param id path int true "User ID"
+// @Param from query string true "Range start in RFC3339 or YYYY-MM-DD format"
+// @Param to query string true "Range end in RFC3339 or YYYY-MM-DD format, date includes the whole day"
+// @Param tz query string false "IANA time zone of dates without offset, user time zone by default"
+// @Success 200 {string} string "iCalendar file"
+// @Failure 400 {object} Message "Invalid input data"
+// @Failure 404 {object} Message "User not found"
+// @Failure 500 {object} Message "Failed to get calendar"
+// @Router /users/{id}/calendar.ics [get]
+func (h *Handler) GetUserCalendar(c *gin.Context) {
+	log := h.log.With(slog.String("op", "handler.GetUserCalendar"))
+	id := c.Param("id")
+	id64, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		log.Warn("Invalid user ID format", slog.String("id", id), slog.Any("err", err))
+		c.JSON(http.StatusBadRequest, Message{"id should be integer"})
+		return
+	}
+
+	loc, ok := h.userLocation(c, log, uint(id64))
+	if !ok {
+		return
+	}
+
+	from, to, ok := parseDateRange(c, log, loc, "from", "to")
+	if !ok {
+		return
+	}
+
+	calendar, err := h.service.User.GetUserCalendar(uint(id64), from, to)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidRange) {
+			c.JSON(http.StatusBadRequest, Message{err.Error()})
+			return
+		}
+		log.Error("Failed to get calendar", slog.Uint64("user_id", id64), slog.Any("err", err))
+		c.JSON(http.StatusInternalServerError, Message{"Failed to get calendar"})
+		return
+	}
+
+	c.Data(http.StatusOK, calendarContentType, calendar)
+}
+
+// CreateCalendarToken issues calendar feed URL of a user
+// @Summary Create calendar feed URL
+// @Description Issue a new secret token of the user calendar feed and return the subscribable URL. Previous URL stops working
+// @Tags calendar
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} calendarFeed "Feed token and URL"
+// @Failure 400 {object} Message "ID should be an integer"
+// @Failure 404 {object} Message "User not found"
+// @Failure 500 {object} Message "Failed to create calendar token"
+// @Router /users/{id}/calendar/token [post]
+func (h *Handler) CreateCalendarToken(c *gin.Context) {
+	log := h.log.With(slog.String("op", "handler.CreateCalendarToken"))
+	id := c.Param("id")
+	id64, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		log.Warn("Invalid user ID format", slog.String("id", id), slog.Any("err", err))
+		c.JSON(http.StatusBadRequest, Message{"id should be integer"})
+		return
+	}
+
+	token, err := h.service.User.CreateCalendarToken(uint(id64))
+	if err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) {
+			log.Warn("User not found", slog.Uint64("user_id", id64))
+			c.JSON(http.StatusNotFound, Message{err.Error()})
+			return
+		}
+		log.Error("Failed to create calendar token", slog.Uint64("user_id", id64), slog.Any("err", err))
+		c.JSON(http.StatusInternalServerError, Message{"Failed to create calendar token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, calendarFeed{
+		Token: token,
+		URL:   h.baseURL(c) + "/calendar/" + token + ".ics",
+	})
+}
+
+// baseURL returns the configured public URL. Without it the request host is
+// used; forwarded headers are ignored as any client may set them
+func (h *Handler) baseURL(c *gin.Context) string {
+	if h.publicURL != "" {
+		return h.publicURL
+	}
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + c.Request.Host
+}
+
+// GetCalendarFeed serves subscribable calendar of a user
+// @Summary Get calendar feed
+// @Description Subscribable iCalendar feed with periods of the last 90 days of the token owner
+// @Tags calendar
+// @Produce text/calendar
+// @Param token path string true "Feed token, optionally with .ics suffix"
+// @Success 200 {string} string "iCalendar file"
+// @Failure 404 {object} Message "Calendar not found"
+// @Failure 500 {object} Message "Failed to get calendar"
+// @Router /calendar/{token} [get]
+func (h *Handler) GetCalendarFeed(c *gin.Context) {
+	log := h.log.With(slog.String("op", "handler.GetCalendarFeed"))
+	token := strings.TrimSuffix(c.Param("token"), ".ics")
+
+	calendar, err := h.service.User.GetCalendarFeed(token)
+	if err != nil {
+		if errors.Is(err, storage.ErrCalendarNotFound) {
+			c.JSON(http.StatusNotFound, Message{err.Error()})
+			return
+		}
+		log.Error("Failed to get calendar feed", slog.Any("err", err))
+		c.JSON(http.StatusInternalServerError, Message{"Failed to get calendar"})
+		return
+	}
+
+	c.Data(http.StatusOK, calendarContentType, calendar)
+}
+
+// ImportCalendar imports periods of a user from iCalendar files
+// @Summary Import user calendar
+// @Description Create tasks and periods of a user from events of uploaded .ics files. Events are matched by category and title prefix, the prefix is stripped from task name. Already imported UIDs, all-day, recurring and future events are skipped and counted as ignored
+// @Tags calendar
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path int true "User ID"
+// @Param file formData file true "iCalendar file, may be repeated"
+// @Param category formData string false "Import only events with the category"
+// @Param prefix formData string false "Import only events with title starting with the prefix"
+// @Param project_id formData int false "Project of created tasks"
+// @Param tz query string false "IANA time zone of event times without zone, user time zone by default"
+// @Success 200 {object} models.ImportResult "Import result"
+// @Failure 400 {object} Message "Invalid input data"
+// @Failure 404 {object} Message "User not found"
+// @Failure 413 {object} Message "Calendar files are larger than 10 MB in total"
+// @Failure 500 {object} Message "Failed to import calendar"
+// @Router /users/{id}/import/ics [post]
+func (h *Handler) ImportCalendar(c *gin.Context) {
+	log := h.log.With(slog.String("op", "handler.ImportCalendar"))
+	id := c.Param("id")
+	id64, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		log.Warn("Invalid user ID format", slog.String("id", id), slog.Any("err", err))
+		c.JSON(http.StatusBadRequest, Message{"id should be integer"})
+		return
+	}
+
+	// form is parsed before reading fields so a too large body is reported
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxCalendarUpload)
+	form, err := c.MultipartForm()
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		log.Warn("Calendar upload is too large", slog.Int64("limit", tooLarge.Limit))
+		c.JSON(http.StatusRequestEntityTooLarge, Message{"calendar files are too large"})
+		return
+	}
+	if err != nil || len(form.File["file"]) == 0 {
+		log.Warn("No calendar file uploaded", slog.Any("err", err))
+		c.JSON(http.StatusBadRequest, Message{"file is required"})
+		return
+	}
+
+	rules := models.CalendarImportRules{
+		Category: c.PostForm("category"),
+		Prefix:   c.PostForm("prefix"),
+	}
+	if v := c.PostForm("project_id"); v != "" {
+		projectID, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			log.Warn("Invalid project ID format", slog.String("project_id", v), slog.Any("err", err))
+			c.JSON(http.StatusBadRequest, Message{"project_id should be integer"})
+			return
+		}
+		p := uint(projectID)
+		rules.ProjectID = &p
+	}
+
+	calendars := make([]io.Reader, 0, len(form.File["file"]))
+	for _, header := range form.File["file"] {
+		f, err := header.Open()
+		if err != nil {
+			log.Error("Failed to open uploaded file", slog.String("file", header.Filename), slog.Any("err", err))
+			c.JSON(http.StatusInternalServerError, Message{"Failed to import calendar"})
+			return
+		}
+		defer f.Close()
+		calendars = append(calendars, f)
+	}
+
+	loc, ok := h.userLocation(c, log, uint(id64))
+	if !ok {
+		return
+	}
+
+	result, err := h.service.User.ImportCalendar(uint(id64), calendars, rules, loc)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidCalendar) {
+			c.JSON(http.StatusBadRequest, Message{err.Error()})
+			return
+		}
+		log.Error("Failed to import calendar", slog.Uint64("user_id", id64), slog.Any("err", err))
+		c.JSON(http.StatusInternalServerError, Message{"Failed to import calendar"})
+		return
+	}
+
+	log.Info("Calendar imported", slog.Uint64("user_id", id64), slog.Any("result", result))
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/moxicom/user_test/internal/services"
+	"github.com/moxicom/user_test/internal/storage"
+	"github.com/moxicom/user_test/internal/utils"
+)
+
+// Reports read dates and split days in the time zone given by tz query
+// parameter. Without it a report about one user uses the user time zone and
+// reports about several users (teams, projects, the organization, invoices)
+// use the server time zone, which is also used for users without a zone
+
+// userLocation resolves time zone of user reports from tz query parameter or
+// user default. It writes error response and returns false on failure
+func (h *Handler) userLocation(c *gin.Context, log *slog.Logger, userID uint) (*time.Location, bool) {
+	loc, err := h.service.User.GetUserLocation(userID, c.Query("tz"))
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidTimeZone) {
+			log.Warn("Invalid time zone", slog.String("tz", c.Query("tz")))
+			c.JSON(http.StatusBadRequest, Message{err.Error()})
+			return nil, false
+		}
+		if errors.Is(err, storage.ErrUserNotFound) {
+			log.Warn("User not found", slog.Uint64("user_id", uint64(userID)))
+			c.JSON(http.StatusNotFound, Message{err.Error()})
+			return nil, false
+		}
+		log.Error("Failed to get user time zone", slog.Uint64("user_id", uint64(userID)), slog.Any("err", err))
+		c.JSON(http.StatusInternalServerError, Message{"failed to get user time zone"})
+		return nil, false
+	}
+	return loc, true
+}
+
+// queryLocation resolves time zone of reports about several users from tz
+// query parameter, server time zone by default
+func queryLocation(c *gin.Context, log *slog.Logger) (*time.Location, bool) {
+	tz := c.Query("tz")
+	if tz == "" {
+		return time.Local, true
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		log.Warn("Invalid time zone", slog.String("tz", tz))
+		c.JSON(http.StatusBadRequest, Message{services.ErrInvalidTimeZone.Error()})
+		return nil, false
+	}
+	return loc, true
+}
+
+// parseDateRange parses range bounds from query parameters in loc.
+// It writes bad request and returns false on failure
+func parseDateRange(c *gin.Context, log *slog.Logger, loc *time.Location, startKey, endKey string) (time.Time, time.Time, bool) {
+	start, err := utils.ParseTime(c.Query(startKey), loc, false)
+	if err != nil {
+		log.Warn("Invalid range start", slog.String(startKey, c.Query(startKey)), slog.Any("err", err))
+		c.JSON(http.StatusBadRequest, Message{"Invalid " + startKey})
+		return time.Time{}, time.Time{}, false
+	}
+
+	end, err := utils.ParseTime(c.Query(endKey), loc, true)
+	if err != nil {
+		log.Warn("Invalid range end", slog.String(endKey, c.Query(endKey)), slog.Any("err", err))
+		c.JSON(http.StatusBadRequest, Message{"Invalid " + endKey})
+		return time.Time{}, time.Time{}, false
+	}
+
+	return start, end, true
+}
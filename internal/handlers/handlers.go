@@ -16,14 +16,18 @@ type Message struct {
 }
 
 type Handler struct {
-	service services.Service
-	log     *slog.Logger
+	service   services.Service
+	publicURL string
+	log       *slog.Logger
 }
 
-func New(service *services.Service, log *slog.Logger) *Handler {
+// New returns handlers of the service. Links given to clients start with
+// publicURL, or with the request host if it is empty
+func New(service *services.Service, publicURL string, log *slog.Logger) *Handler {
 	return &Handler{
-		service: *service,
-		log:     log,
+		service:   *service,
+		publicURL: publicURL,
+		log:       log,
 	}
 }
 
@@ -31,7 +35,7 @@ func (h *Handler) InitRoutes() *gin.Engine {
 	router := gin.Default()
 	router.Use(cors.New(cors.Config{
 		AllowAllOrigins:  true,
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE"},
+		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE"},
 		AllowCredentials: true,
 		MaxAge:           12 * 3600,
 	}))
@@ -45,15 +49,106 @@ func (h *Handler) InitRoutes() *gin.Engine {
 		users.PUT("/:id", h.UpdateUser)
 		users.DELETE("/:id", h.DeleteUser)
 		users.GET("/:id/tasks", h.GetUsersWithTasks)
+		users.POST("/:id/suspend", h.SuspendUser)
+		users.POST("/:id/reactivate", h.ReactivateUser)
+		users.POST("/:id/terminate", h.TerminateUser)
+		users.GET("/:id/subordinates", h.GetManagedUsers)
+		users.GET("/:id/projects", h.GetUserProjects)
+		users.GET("/:id/tags", h.GetUserTags)
+		users.GET("/:id/timers/active", h.GetUserActiveTimers)
+		users.GET("/:id/summary", h.GetUserSummary)
+		users.GET("/:id/calendar.ics", h.GetUserCalendar)
+		users.POST("/:id/calendar/token", h.CreateCalendarToken)
+		users.POST("/:id/import/ics", h.ImportCalendar)
+		users.GET("/:id/timesheet.xlsx", h.GetUserTimesheetXLSX)
+		users.GET("/:id/timesheet.html", h.GetUserTimesheetHTML)
+		users.GET("/:id/timesheet.pdf", h.GetUserTimesheetPDF)
+		users.POST("/:id/timesheets", h.SubmitTimesheet)
+		users.GET("/:id/timesheets", h.GetUserSubmissions)
 	}
 
 	tasks := router.Group("/tasks")
 	{
+		tasks.GET("/", h.GetTasks)
 		tasks.POST("/", h.CreateTask)
+		tasks.PATCH("/:id", h.UpdateTask)
 		tasks.DELETE("/:id", h.DeleteTask)
 		tasks.POST("/:id/start", h.StartPeriod)
 		tasks.POST("/:id/end", h.EndPeriod)
+		tasks.POST("/:id/switch", h.SwitchTask)
 		tasks.POST(":id/finish", h.FinishTask)
+		tasks.POST("/:id/reopen", h.ReopenTask)
+		tasks.POST("/:id/archive", h.ArchiveTask)
+		tasks.GET("/:id/periods", h.GetTaskPeriods)
+		tasks.POST("/:id/periods", h.AddPeriod)
+	}
+
+	periods := router.Group("/periods")
+	{
+		periods.PUT("/:id", h.UpdatePeriod)
+		periods.PATCH("/:id", h.SetPeriodBillable)
+		periods.DELETE("/:id", h.DeletePeriod)
+	}
+
+	teams := router.Group("/teams")
+	{
+		teams.GET("/", h.GetTeams)
+		teams.POST("/", h.CreateTeam)
+		teams.DELETE("/:id", h.DeleteTeam)
+		teams.GET("/:id/members", h.GetTeamMembers)
+		teams.POST("/:id/members", h.AddTeamMember)
+		teams.DELETE("/:id/members/:user_id", h.RemoveTeamMember)
+		teams.GET("/:id/users", h.GetTeamUsers)
+		teams.GET("/:id/tasks", h.GetTeamTasks)
+	}
+
+	router.GET("/tags", h.GetTags)
+	router.GET("/timers/active", h.GetActiveTimers)
+	router.GET("/alerts", h.GetTaskAlerts)
+	router.GET("/calendar/:token", h.GetCalendarFeed)
+
+	projects := router.Group("/projects")
+	{
+		projects.GET("/", h.GetProjects)
+		projects.POST("/", h.CreateProject)
+		projects.GET("/:id", h.GetProject)
+		projects.GET("/:id/burndown", h.GetProjectBurndown)
+		projects.PUT("/:id", h.UpdateProject)
+		projects.DELETE("/:id", h.DeleteProject)
+	}
+
+	rates := router.Group("/rates")
+	{
+		rates.GET("/", h.GetRates)
+		rates.POST("/", h.CreateRate)
+		rates.DELETE("/:id", h.DeleteRate)
+	}
+
+	invoices := router.Group("/invoices")
+	{
+		invoices.GET("/", h.GetInvoices)
+		invoices.POST("/", h.CreateInvoice)
+		invoices.GET("/:id", h.GetInvoice)
+		invoices.GET("/:id/invoice.html", h.GetInvoiceHTML)
+		invoices.GET("/:id/invoice.pdf", h.GetInvoicePDF)
+		invoices.DELETE("/:id", h.DeleteInvoice)
+	}
+
+	timesheets := router.Group("/timesheets")
+	{
+		timesheets.GET("/", h.GetSubmissions)
+		timesheets.POST("/:id/approve", h.ApproveTimesheet)
+		timesheets.POST("/:id/reject", h.RejectTimesheet)
+	}
+
+	reports := router.Group("/reports")
+	{
+		reports.GET("/time", h.GetTimeReport)
+	}
+
+	imports := router.Group("/import")
+	{
+		imports.POST("/csv", h.ImportTimeEntries)
 	}
 
 	return router
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/moxicom/user_test/internal/models"
+	"github.com/moxicom/user_test/internal/services"
+)
+
+// ImportTimeEntries imports Toggl or Clockify detailed report
+// @Summary Import time entries from CSV
+// @Description Import Toggl or Clockify detailed report CSV. People are mapped to users by passport column if present, otherwise by email, projects by name. Billable column (Yes or No) marks periods billable, billable by default. Dry run (default) returns preview of the import without saving
+// @Tags import
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "Detailed report CSV"
+// @Param format query string false "Source format, 'toggl' or 'clockify'. Detected from header by default"
+// @Param dry_run query bool false "Only preview the import. Default is true"
+// @Param tz query string false "IANA time zone of report times, time zone of each user by default"
+// @Success 200 {object} models.CSVImportResult "Import result"
+// @Failure 400 {object} Message "Invalid input data"
+// @Failure 500 {object} Message "Failed to import time entries"
+// @Router /import/csv [post]
+func (h *Handler) ImportTimeEntries(c *gin.Context) {
+	log := h.log.With(slog.String("op", "handler.ImportTimeEntries"))
+
+	opts := models.CSVImportOptions{
+		Format:   models.CSVFormat(c.Query("format")),
+		DryRun:   true,
+		TimeZone: c.Query("tz"),
+	}
+	if v := c.Query("dry_run"); v != "" {
+		dryRun, err := strconv.ParseBool(v)
+		if err != nil {
+			log.Warn("Invalid dry_run", slog.String("dry_run", v))
+			c.JSON(http.StatusBadRequest, Message{"dry_run should be boolean"})
+			return
+		}
+		opts.DryRun = dryRun
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		log.Warn("No CSV file uploaded", slog.Any("err", err))
+		c.JSON(http.StatusBadRequest, Message{"file is required"})
+		return
+	}
+	f, err := header.Open()
+	if err != nil {
+		log.Error("Failed to open uploaded file", slog.String("file", header.Filename), slog.Any("err", err))
+		c.JSON(http.StatusInternalServerError, Message{"Failed to import time entries"})
+		return
+	}
+	defer f.Close()
+
+	result, err := h.service.Import.ImportTimeEntries(f, opts)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidCSV) || errors.Is(err, services.ErrInvalidCSVFormat) || errors.Is(err, services.ErrInvalidTimeZone) {
+			log.Warn("Invalid import parameters", slog.Any("err", err))
+			c.JSON(http.StatusBadRequest, Message{err.Error()})
+			return
+		}
+		log.Error("Failed to import time entries", slog.Any("err", err))
+		c.JSON(http.StatusInternalServerError, Message{"Failed to import time entries"})
+		return
+	}
+
+	log.Info("Time entries imported", slog.Bool("dry_run", opts.DryRun), slog.Int("imported", result.Imported))
+	c.JSON(http.StatusOK, result)
+}
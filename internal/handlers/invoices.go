@@ -0,0 +1,245 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/moxicom/user_test/internal/models"
+	"github.com/moxicom/user_test/internal/services"
+	"github.com/moxicom/user_test/internal/utils"
+)
+
+type invoiceInput struct {
+	ProjectID *uint  `json:"project_id"`
+	Client    string `json:"client"`
+	From      string `json:"from" binding:"required" example:"2024-07-01"`
+	To        string `json:"to" binding:"required" example:"2024-07-31"`
+}
+
+// invoiceErrors are caused by invalid invoice selection and reported as bad request
+var invoiceErrors = []error{
+	services.ErrInvalidInvoiceTarget,
+	services.ErrInvalidRange,
+	services.ErrNothingToInvoice,
+}
+
+func isInvoiceError(err error) bool {
+	for _, target := range invoiceErrors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateInvoice invoices unbilled time
+// @Summary Create an invoice
+// @Description Bill unbilled billable finished periods of a project or of all projects of a client started within the range. Time is grouped into items by task and hourly rate effective on each day. Invoice gets the next number of the year and its periods can not be billed again, edited or deleted
+// @Tags invoices
+// @Accept json
+// @Produce json
+// @Param invoice body invoiceInput true "Exactly one of project_id and client, range in RFC3339 or YYYY-MM-DD format, to date includes the whole day"
+// @Param tz query string false "IANA time zone of dates without offset, server time zone by default"
+// @Success 200 {object} models.Invoice "Created invoice"
+// @Failure 400 {object} Message "Invalid input data or nothing to invoice"
+// @Failure 500 {object} Message "Failed to create invoice"
+// @Router /invoices [post]
+func (h *Handler) CreateInvoice(c *gin.Context) {
+	log := h.log.With(slog.String("op", "handler.CreateInvoice"))
+	var input invoiceInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		log.Error("error while parsing json ", slog.Any("err", err))
+		c.JSON(http.StatusBadRequest, Message{"invalid body data"})
+		return
+	}
+
+	loc, ok := queryLocation(c, log)
+	if !ok {
+		return
+	}
+
+	sel := models.InvoiceSelection{Client: input.Client}
+	if input.ProjectID != nil {
+		sel.ProjectID = *input.ProjectID
+	}
+	var err error
+	if sel.From, err = utils.ParseTime(input.From, loc, false); err != nil {
+		log.Warn("Invalid range start", slog.String("from", input.From), slog.Any("err", err))
+		c.JSON(http.StatusBadRequest, Message{"Invalid from"})
+		return
+	}
+	if sel.To, err = utils.ParseTime(input.To, loc, true); err != nil {
+		log.Warn("Invalid range end", slog.String("to", input.To), slog.Any("err", err))
+		c.JSON(http.StatusBadRequest, Message{"Invalid to"})
+		return
+	}
+
+	invoice, err := h.service.Invoice.CreateInvoice(sel, loc)
+	if err != nil {
+		if isInvoiceError(err) {
+			log.Warn("Failed to create invoice", slog.Any("err", err))
+			c.JSON(http.StatusBadRequest, Message{err.Error()})
+			return
+		}
+		log.Error("failed to create invoice", slog.Any("err", err))
+		c.JSON(http.StatusInternalServerError, Message{"failed to create invoice"})
+		return
+	}
+
+	log.Info("Invoice created successfully", slog.Uint64("invoice_id", uint64(invoice.ID)), slog.String("number", invoice.Number))
+	c.JSON(http.StatusOK, invoice)
+}
+
+// GetInvoices retrieves invoices
+// @Summary Get invoices
+// @Description Retrieve invoices with their items, latest first
+// @Tags invoices
+// @Accept json
+// @Produce json
+// @Param project_id query int false "Project ID"
+// @Param client query string false "Client, case insensitive"
+// @Success 200 {array} models.Invoice "List of invoices"
+// @Failure 400 {object} Message "Invalid input data"
+// @Failure 500 {object} Message "Failed to get invoices"
+// @Router /invoices [get]
+func (h *Handler) GetInvoices(c *gin.Context) {
+	log := h.log.With(slog.String("op", "handler.GetInvoices"))
+
+	filters := models.InvoiceFilters{Client: c.Query("client")}
+	if v := c.Query("project_id"); v != "" {
+		projectID, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			log.Warn("Invalid project ID format", slog.String("project_id", v), slog.Any("err", err))
+			c.JSON(http.StatusBadRequest, Message{"project_id should be integer"})
+			return
+		}
+		filters.ProjectID = uint(projectID)
+	}
+
+	invoices, err := h.service.Invoice.GetInvoices(filters)
+	if err != nil {
+		log.Error("failed to get invoices", slog.Any("err", err))
+		c.JSON(http.StatusInternalServerError, Message{"failed to get invoices"})
+		return
+	}
+
+	c.JSON(http.StatusOK, invoices)
+}
+
+// GetInvoice retrieves an invoice
+// @Summary Get an invoice
+// @Description Retrieve an invoice with its items by ID
+// @Tags invoices
+// @Accept json
+// @Produce json
+// @Param id path int true "Invoice ID"
+// @Success 200 {object} models.Invoice "Invoice found"
+// @Failure 400 {object} Message "ID should be an integer"
+// @Failure 500 {object} Message "Failed to get invoice"
+// @Router /invoices/{id} [get]
+func (h *Handler) GetInvoice(c *gin.Context) {
+	log := h.log.With(slog.String("op", "handler.GetInvoice"))
+	id64, ok := invoiceID(c, log)
+	if !ok {
+		return
+	}
+
+	invoice, err := h.service.Invoice.GetInvoice(uint(id64))
+	if err != nil {
+		log.Error("failed to get invoice", slog.Uint64("invoice_id", id64), slog.Any("err", err))
+		c.JSON(http.StatusInternalServerError, Message{"failed to get invoice"})
+		return
+	}
+
+	c.JSON(http.StatusOK, invoice)
+}
+
+// GetInvoiceHTML renders printable invoice
+// @Summary Get printable invoice
+// @Description Render HTML invoice with its items and total. It has a print stylesheet for A4
+// @Tags invoices
+// @Produce html
+// @Param id path int true "Invoice ID"
+// @Success 200 {string} string "HTML invoice"
+// @Failure 400 {object} Message "ID should be an integer"
+// @Failure 500 {object} Message "Failed to get invoice"
+// @Router /invoices/{id}/invoice.html [get]
+func (h *Handler) GetInvoiceHTML(c *gin.Context) {
+	h.printableInvoice(c, "handler.GetInvoiceHTML", "text/html; charset=utf-8", h.service.Invoice.GetInvoiceHTML)
+}
+
+// GetInvoicePDF renders invoice as PDF
+// @Summary Get invoice PDF
+// @Description Render PDF invoice with its items and total. Text is set in embedded DejaVu Sans
+// @Tags invoices
+// @Produce application/pdf
+// @Param id path int true "Invoice ID"
+// @Success 200 {file} file "PDF invoice"
+// @Failure 400 {object} Message "ID should be an integer"
+// @Failure 500 {object} Message "Failed to get invoice"
+// @Router /invoices/{id}/invoice.pdf [get]
+func (h *Handler) GetInvoicePDF(c *gin.Context) {
+	h.printableInvoice(c, "handler.GetInvoicePDF", "application/pdf", h.service.Invoice.GetInvoicePDF)
+}
+
+// printableInvoice writes document of the invoice rendered by render
+func (h *Handler) printableInvoice(c *gin.Context, op string, contentType string, render func(uint) ([]byte, error)) {
+	log := h.log.With(slog.String("op", op))
+	id64, ok := invoiceID(c, log)
+	if !ok {
+		return
+	}
+
+	data, err := render(uint(id64))
+	if err != nil {
+		log.Error("Failed to get invoice", slog.Uint64("invoice_id", id64), slog.Any("err", err))
+		c.JSON(http.StatusInternalServerError, Message{"failed to get invoice"})
+		return
+	}
+
+	c.Data(http.StatusOK, contentType, data)
+}
+
+// DeleteInvoice deletes an invoice
+// @Summary Delete an invoice
+// @Description Delete an invoice by ID. Its periods become unbilled and can be invoiced again
+// @Tags invoices
+// @Accept json
+// @Produce json
+// @Param id path int true "Invoice ID"
+// @Success 200 {object} Message "Invoice deleted"
+// @Failure 400 {object} Message "ID should be an integer"
+// @Failure 500 {object} Message "Failed to delete invoice"
+// @Router /invoices/{id} [delete]
+func (h *Handler) DeleteInvoice(c *gin.Context) {
+	log := h.log.With(slog.String("op", "handler.DeleteInvoice"))
+	id64, ok := invoiceID(c, log)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Invoice.DeleteInvoice(uint(id64)); err != nil {
+		log.Error("Failed to delete invoice", slog.Uint64("invoice_id", id64), slog.Any("err", err))
+		c.JSON(http.StatusInternalServerError, Message{"failed to delete invoice"})
+		return
+	}
+
+	log.Info("Invoice deleted successfully", slog.Uint64("invoice_id", id64))
+	c.JSON(http.StatusOK, Message{"invoice deleted"})
+}
+
+// invoiceID parses invoice ID path parameter. It writes bad request and
+// returns false on failure
+func invoiceID(c *gin.Context, log *slog.Logger) (uint64, bool) {
+	id := c.Param("id")
+	id64, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		log.Warn("Invalid invoice ID format", slog.String("id", id), slog.Any("err", err))
+		c.JSON(http.StatusBadRequest, Message{"id should be integer"})
+		return 0, false
+	}
+	return id64, true
+}
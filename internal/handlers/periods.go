@@ -0,0 +1,271 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/moxicom/user_test/internal/services"
+	"github.com/moxicom/user_test/internal/storage"
+)
+
+type periodInput struct {
+	StartTime time.Time `json:"start_time" binding:"required"`
+	EndTime   time.Time `json:"end_time" binding:"required"`
+	Billable  *bool     `json:"billable"` // billable by default, ignored on update
+}
+
+type billableInput struct {
+	Billable *bool `json:"billable" binding:"required"`
+}
+
+// periodErrors are caused by invalid period data and reported as bad request
+var periodErrors = []error{
+	services.ErrInvalidPeriod,
+	services.ErrPeriodInFuture,
+	services.ErrTaskClosed,
+	storage.ErrPeriodOverlap,
+	storage.ErrPeriodNotFinished,
+	storage.ErrPeriodLocked,
+	storage.ErrPeriodInvoiced,
+}
+
+func isPeriodError(err error) bool {
+	for _, target := range periodErrors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetTaskPeriods gets periods of a task
+// @Summary Get task periods
+// @Description Get all periods of a task ordered by start time
+// @Tags periods
+// @Accept json
+// @Produce json
+// @Param id path int true "Task ID"
+// @Success 200 {array} models.TaskPeriod "Periods found"
+// @Failure 400 {object} Message "ID should be an integer"
+// @Failure 500 {object} Message "Failed to get periods"
+// @Router /tasks/{id}/periods [get]
+func (h *Handler) GetTaskPeriods(c *gin.Context) {
+	log := h.log.With(slog.String("op", "Handler.GetTaskPeriods"))
+
+	id := c.Param("id")
+	id64, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		log.Warn("failed to parse task id", slog.Any("err", err))
+		c.JSON(http.StatusBadRequest, Message{"id should be integer"})
+		return
+	}
+
+	periods, err := h.service.Task.GetTaskPeriods(uint(id64))
+	if err != nil {
+		log.Error("failed to get periods", slog.Uint64("task_id", id64), slog.Any("err", err))
+		c.JSON(http.StatusInternalServerError, Message{"failed to get periods"})
+		return
+	}
+
+	c.JSON(http.StatusOK, periods)
+}
+
+// AddPeriod adds a period with explicit bounds to a task
+// @Summary Add a period manually
+// @Description Add a finished period to a task, billable unless billable is false. Start should be before end, period can not be in the future, overlap other periods of the task or be within an approved timesheet
+// @Tags periods
+// @Accept json
+// @Produce json
+// @Param id path int true "Task ID"
+// @Param period body periodInput true "Period bounds in RFC3339 format"
+// @Success 200 {object} Message "Period ID"
+// @Failure 400 {object} Message "Invalid input data"
+// @Failure 500 {object} Message "Failed to add period"
+// @Router /tasks/{id}/periods [post]
+func (h *Handler) AddPeriod(c *gin.Context) {
+	log := h.log.With(slog.String("op", "Handler.AddPeriod"))
+
+	id := c.Param("id")
+	id64, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		log.Warn("failed to parse task id", slog.Any("err", err))
+		c.JSON(http.StatusBadRequest, Message{"id should be integer"})
+		return
+	}
+
+	var input periodInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		log.Error("error while parsing json ", slog.Any("err", err))
+		c.JSON(http.StatusBadRequest, Message{"invalid body data"})
+		return
+	}
+
+	billable := input.Billable == nil || *input.Billable
+	periodID, err := h.service.Task.AddPeriod(uint(id64), input.StartTime, input.EndTime, billable)
+	if err != nil {
+		if isPeriodError(err) {
+			log.Warn("Failed to add period", slog.Uint64("task_id", id64), slog.Any("err", err))
+			c.JSON(http.StatusBadRequest, Message{err.Error()})
+			return
+		}
+		log.Error("failed to add period", slog.Uint64("task_id", id64), slog.Any("err", err))
+		c.JSON(http.StatusInternalServerError, Message{"failed to add period"})
+		return
+	}
+
+	log.Info("Period added", slog.Uint64("task_id", id64), slog.Uint64("period_id", uint64(periodID)))
+	c.JSON(http.StatusOK, Message{fmt.Sprint(periodID)})
+}
+
+// UpdatePeriod changes bounds of a period
+// @Summary Update a period
+// @Description Change bounds of a finished period with the same rules as on adding. Period within an approved timesheet or invoiced can not be moved
+// @Tags periods
+// @Accept json
+// @Produce json
+// @Param id path int true "Period ID"
+// @Param period body periodInput true "Period bounds in RFC3339 format"
+// @Success 200 {object} Message "Period updated"
+// @Failure 400 {object} Message "Invalid input data"
+// @Failure 404 {object} Message "Period not found"
+// @Failure 500 {object} Message "Failed to update period"
+// @Router /periods/{id} [put]
+func (h *Handler) UpdatePeriod(c *gin.Context) {
+	log := h.log.With(slog.String("op", "Handler.UpdatePeriod"))
+
+	id := c.Param("id")
+	id64, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		log.Warn("failed to parse period id", slog.Any("err", err))
+		c.JSON(http.StatusBadRequest, Message{"id should be integer"})
+		return
+	}
+
+	var input periodInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		log.Error("error while parsing json ", slog.Any("err", err))
+		c.JSON(http.StatusBadRequest, Message{"invalid body data"})
+		return
+	}
+
+	err = h.service.Task.UpdatePeriod(uint(id64), input.StartTime, input.EndTime)
+	if err != nil {
+		if errors.Is(err, storage.ErrPeriodNotFound) {
+			log.Warn("Failed to update period", slog.Uint64("period_id", id64), slog.Any("err", err))
+			c.JSON(http.StatusNotFound, Message{err.Error()})
+			return
+		}
+		if isPeriodError(err) {
+			log.Warn("Failed to update period", slog.Uint64("period_id", id64), slog.Any("err", err))
+			c.JSON(http.StatusBadRequest, Message{err.Error()})
+			return
+		}
+		log.Error("failed to update period", slog.Uint64("period_id", id64), slog.Any("err", err))
+		c.JSON(http.StatusInternalServerError, Message{"failed to update period"})
+		return
+	}
+
+	log.Info("Period updated", slog.Uint64("period_id", id64))
+	c.JSON(http.StatusOK, Message{"period updated"})
+}
+
+// SetPeriodBillable marks a period billable or non-billable
+// @Summary Mark period billable
+// @Description Mark a period billable or non-billable. Only billable time counts in billable amount of reports and is invoiced. Period within an approved timesheet or invoiced can not be changed
+// @Tags periods
+// @Accept json
+// @Produce json
+// @Param id path int true "Period ID"
+// @Param period body billableInput true "Billable flag"
+// @Success 200 {object} Message "Period updated"
+// @Failure 400 {object} Message "Invalid input data"
+// @Failure 404 {object} Message "Period not found"
+// @Failure 500 {object} Message "Failed to update period"
+// @Router /periods/{id} [patch]
+func (h *Handler) SetPeriodBillable(c *gin.Context) {
+	log := h.log.With(slog.String("op", "Handler.SetPeriodBillable"))
+
+	id := c.Param("id")
+	id64, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		log.Warn("failed to parse period id", slog.Any("err", err))
+		c.JSON(http.StatusBadRequest, Message{"id should be integer"})
+		return
+	}
+
+	var input billableInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		log.Error("error while parsing json ", slog.Any("err", err))
+		c.JSON(http.StatusBadRequest, Message{"invalid body data"})
+		return
+	}
+
+	err = h.service.Task.SetPeriodBillable(uint(id64), *input.Billable)
+	if err != nil {
+		if errors.Is(err, storage.ErrPeriodNotFound) {
+			log.Warn("Failed to update period", slog.Uint64("period_id", id64), slog.Any("err", err))
+			c.JSON(http.StatusNotFound, Message{err.Error()})
+			return
+		}
+		if isPeriodError(err) {
+			log.Warn("Failed to update period", slog.Uint64("period_id", id64), slog.Any("err", err))
+			c.JSON(http.StatusBadRequest, Message{err.Error()})
+			return
+		}
+		log.Error("failed to update period", slog.Uint64("period_id", id64), slog.Any("err", err))
+		c.JSON(http.StatusInternalServerError, Message{"failed to update period"})
+		return
+	}
+
+	log.Info("Period billable changed", slog.Uint64("period_id", id64), slog.Bool("billable", *input.Billable))
+	c.JSON(http.StatusOK, Message{"period updated"})
+}
+
+// DeletePeriod deletes a period
+// @Summary Delete a period
+// @Description Delete a finished period by ID. Period within an approved timesheet or invoiced can not be deleted
+// @Tags periods
+// @Accept json
+// @Produce json
+// @Param id path int true "Period ID"
+// @Success 200 {object} Message "Period deleted"
+// @Failure 400 {object} Message "ID should be an integer or period can not be deleted"
+// @Failure 404 {object} Message "Period not found"
+// @Failure 500 {object} Message "Failed to delete period"
+// @Router /periods/{id} [delete]
+func (h *Handler) DeletePeriod(c *gin.Context) {
+	log := h.log.With(slog.String("op", "Handler.DeletePeriod"))
+
+	id := c.Param("id")
+	id64, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		log.Warn("failed to parse period id", slog.Any("err", err))
+		c.JSON(http.StatusBadRequest, Message{"id should be integer"})
+		return
+	}
+
+	err = h.service.Task.DeletePeriod(uint(id64))
+	if err != nil {
+		if errors.Is(err, storage.ErrPeriodNotFound) {
+			log.Warn("Failed to delete period", slog.Uint64("period_id", id64), slog.Any("err", err))
+			c.JSON(http.StatusNotFound, Message{err.Error()})
+			return
+		}
+		if isPeriodError(err) {
+			log.Warn("Failed to delete period", slog.Uint64("period_id", id64), slog.Any("err", err))
+			c.JSON(http.StatusBadRequest, Message{err.Error()})
+			return
+		}
+		log.Error("failed to delete period", slog.Uint64("period_id", id64), slog.Any("err", err))
+		c.JSON(http.StatusInternalServerError, Message{"failed to delete period"})
+		return
+	}
+
+	log.Info("Period deleted", slog.Uint64("period_id", id64))
+	c.JSON(http.StatusOK, Message{"period deleted"})
+}
@@ -0,0 +1,310 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/moxicom/user_test/internal/models"
+	"github.com/moxicom/user_test/internal/services"
+	"github.com/moxicom/user_test/internal/utils"
+)
+
+// CreateProject creates a new project
+// @Summary Create a new project
+// @Description Create a new project. Tasks are assigned to projects with project_id. Optional budget_hours and budget_amount (cost at hourly rates) are shown in burn-down
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Param project body models.Project true "Project object"
+// @Success 200 {object} Message "Project ID"
+// @Failure 400 {object} Message "Invalid body data or negative budget"
+// @Failure 500 {object} Message "Failed to create project"
+// @Router /projects [post]
+func (h *Handler) CreateProject(c *gin.Context) {
+	log := h.log.With(slog.String("op", "handler.CreateProject"))
+	var project models.Project
+	if err := c.ShouldBindJSON(&project); err != nil {
+		log.Error("error while parsing json ", slog.Any("err", err))
+		c.JSON(http.StatusBadRequest, Message{"invalid body data"})
+		return
+	}
+
+	projectID, err := h.service.Project.CreateProject(project)
+	if err != nil {
+		if errors.Is(err, services.ErrNegativeBudget) {
+			log.Warn("Failed to create project", slog.Any("err", err))
+			c.JSON(http.StatusBadRequest, Message{err.Error()})
+			return
+		}
+		log.Error("failed to create project", slog.Any("err", err))
+		c.JSON(http.StatusInternalServerError, Message{"failed to create project"})
+		return
+	}
+
+	log.Info("Project created successfully", slog.Uint64("project_id", uint64(projectID)))
+	c.JSON(http.StatusOK, Message{fmt.Sprint(projectID)})
+}
+
+// GetProjects retrieves projects based on filters
+// @Summary Get projects
+// @Description Retrieve projects based on filters
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Param name query string false "Name"
+// @Param client query string false "Client"
+// @Success 200 {array} models.Project "List of projects"
+// @Failure 500 {object} Message "Failed to get projects"
+// @Router /projects [get]
+func (h *Handler) GetProjects(c *gin.Context) {
+	log := h.log.With(slog.String("op", "handler.GetProjects"))
+
+	projects, err := h.service.Project.GetProjects(utils.GetProjectFilters(c))
+	if err != nil {
+		log.Error("failed to get projects")
+		c.JSON(http.StatusInternalServerError, Message{"failed to get projects"})
+		return
+	}
+
+	c.JSON(http.StatusOK, projects)
+}
+
+// GetProject retrieves a project
+// @Summary Get a project
+// @Description Retrieve a project by ID
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Param id path int true "Project ID"
+// @Success 200 {object} models.Project "Project"
+// @Failure 400 {object} Message "ID should be an integer"
+// @Failure 500 {object} Message "Failed to get project"
+// @Router /projects/{id} [get]
+func (h *Handler) GetProject(c *gin.Context) {
+	log := h.log.With(slog.String("op", "handler.GetProject"))
+	id := c.Param("id")
+	id64, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		log.Warn("Invalid project ID format", slog.String("id", id), slog.Any("err", err))
+		c.JSON(http.StatusBadRequest, Message{"id should be integer"})
+		return
+	}
+
+	project, err := h.service.Project.GetProject(uint(id64))
+	if err != nil {
+		log.Error("failed to get project", slog.Uint64("project_id", id64))
+		c.JSON(http.StatusInternalServerError, Message{"failed to get project"})
+		return
+	}
+
+	c.JSON(http.StatusOK, project)
+}
+
+// UpdateProject updates a project
+// @Summary Update a project
+// @Description Update a project with the provided data
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Param id path int true "Project ID"
+// @Param name query string false "Name"
+// @Param client query string false "Client"
+// @Param description query string false "Description"
+// @Param budget_hours query number false "Hour budget, 0 removes it"
+// @Param budget_amount query number false "Money budget of cost at hourly rates, 0 removes it"
+// @Success 200 {object} Message "Project updated"
+// @Failure 400 {object} Message "Incorrect ID or invalid input data"
+// @Failure 500 {object} Message "Failed to update project"
+// @Router /projects/{id} [put]
+func (h *Handler) UpdateProject(c *gin.Context) {
+	log := h.log.With(slog.String("op", "handler.UpdateProject"))
+	filt := utils.GetProjectFilters(c)
+	id64, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		log.Warn("Failed to parse project ID", slog.String("id", c.Param("id")), slog.Any("err", err))
+		c.JSON(http.StatusBadRequest, Message{"incorrect id"})
+		return
+	}
+
+	if v := c.Query("budget_hours"); v != "" {
+		hours, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			log.Warn("Invalid budget", slog.String("budget_hours", v), slog.Any("err", err))
+			c.JSON(http.StatusBadRequest, Message{"budget_hours should be a number"})
+			return
+		}
+		filt.BudgetHours = &hours
+	}
+	if v := c.Query("budget_amount"); v != "" {
+		amount, err := models.ParseCents(v)
+		if err != nil {
+			log.Warn("Invalid budget", slog.String("budget_amount", v), slog.Any("err", err))
+			c.JSON(http.StatusBadRequest, Message{"budget_amount should be a number"})
+			return
+		}
+		filt.BudgetAmount = &amount
+	}
+
+	if filt.Name == "" && filt.Client == "" && filt.Description == "" && filt.BudgetHours == nil && filt.BudgetAmount == nil {
+		log.Warn("No data to update for project", slog.Uint64("project_id", id64))
+		c.JSON(http.StatusBadRequest, Message{"no data to update. use name, client, description, budget_hours, budget_amount"})
+		return
+	}
+
+	err = h.service.Project.UpdateProject(uint(id64), filt)
+	if err != nil {
+		if errors.Is(err, services.ErrNegativeBudget) {
+			log.Warn("Failed to update project", slog.Uint64("project_id", id64), slog.Any("err", err))
+			c.JSON(http.StatusBadRequest, Message{err.Error()})
+			return
+		}
+		log.Error("Failed to update project")
+		c.JSON(http.StatusInternalServerError, Message{"failed to update project"})
+		return
+	}
+
+	log.Info("Project updated successfully", slog.Uint64("project_id", id64))
+	c.JSON(http.StatusOK, Message{"project updated"})
+}
+
+// DeleteProject deletes a project
+// @Summary Delete a project
+// @Description Delete a project by ID. Its tasks are kept without project
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Param id path int true "Project ID"
+// @Success 200 {object} Message "Project deleted"
+// @Failure 400 {object} Message "ID should be an integer"
+// @Failure 500 {object} Message "Failed to delete project"
+// @Router /projects/{id} [delete]
+func (h *Handler) DeleteProject(c *gin.Context) {
+	log := h.log.With(slog.String("op", "handler.DeleteProject"))
+	id := c.Param("id")
+	id64, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		log.Warn("Invalid project ID format", slog.String("id", id), slog.Any("err", err))
+		c.JSON(http.StatusBadRequest, Message{"id should be integer"})
+		return
+	}
+
+	err = h.service.Project.DeleteProject(uint(id64))
+	if err != nil {
+		log.Error("Failed to delete project", slog.String("id", id))
+		c.JSON(http.StatusInternalServerError, Message{"failed to delete project"})
+		return
+	}
+
+	log.Info("Project deleted successfully", slog.String("id", id))
+	c.JSON(http.StatusOK, Message{"project deleted"})
+}
+
+// GetUserProjects gets time of a user aggregated by project
+// @Summary Get user time by project
+// @Description Get total time of user tasks within a specified date range grouped by project
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param start_date query string true "Start date in RFC3339 or YYYY-MM-DD format"
+// @Param end_date query string true "End date in RFC3339 or YYYY-MM-DD format, date includes the whole day"
+// @Param tz query string false "IANA time zone of dates without offset and of day boundaries, user time zone by default"
+// @Success 200 {array} models.ProjectWithTotalTime "Projects found"
+// @Failure 400 {object} Message "Invalid input data"
+// @Failure 404 {object} Message "User not found"
+// @Failure 500 {object} Message "Failed to get projects for user"
+// @Router /users/{id}/projects [get]
+func (h *Handler) GetUserProjects(c *gin.Context) {
+	log := h.log.With(slog.String("op", "handler.GetUserProjects"))
+	id := c.Param("id")
+	id64, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		log.Warn("Invalid user ID format", slog.String("id", id), slog.Any("err", err))
+		c.JSON(http.StatusBadRequest, Message{"id should be integer"})
+		return
+	}
+
+	loc, ok := h.userLocation(c, log, uint(id64))
+	if !ok {
+		return
+	}
+
+	startDate, endDate, ok := parseDateRange(c, log, loc, "start_date", "end_date")
+	if !ok {
+		return
+	}
+
+	projects, err := h.service.Project.GetUserProjects(uint(id64), startDate, endDate)
+	if err != nil {
+		log.Error("Failed to get projects for user", slog.Uint64("user_id", id64))
+		c.JSON(http.StatusInternalServerError, Message{"Failed to get projects for user"})
+		return
+	}
+
+	c.JSON(http.StatusOK, projects)
+}
+
+// GetProjectBurndown returns burn-down of a project
+// @Summary Get project burn-down
+// @Description Get time and cost of a project per day with cumulative totals, including time tracked before the range, and remaining hour and money budgets. Cost values time at hourly rates, running timers count until now
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Param id path int true "Project ID"
+// @Param from query string false "Range start in RFC3339 or YYYY-MM-DD format, project creation day by default"
+// @Param to query string false "Range end in RFC3339 or YYYY-MM-DD format, date includes the whole day, today by default"
+// @Param tz query string false "IANA time zone of days, server time zone by default"
+// @Success 200 {object} models.Burndown "Burn-down"
+// @Failure 400 {object} Message "Invalid input data"
+// @Failure 500 {object} Message "Failed to get burn-down"
+// @Router /projects/{id}/burndown [get]
+func (h *Handler) GetProjectBurndown(c *gin.Context) {
+	log := h.log.With(slog.String("op", "handler.GetProjectBurndown"))
+	id := c.Param("id")
+	id64, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		log.Warn("Invalid project ID format", slog.String("id", id), slog.Any("err", err))
+		c.JSON(http.StatusBadRequest, Message{"id should be integer"})
+		return
+	}
+
+	loc, ok := queryLocation(c, log)
+	if !ok {
+		return
+	}
+
+	var from, to time.Time
+	if v := c.Query("from"); v != "" {
+		if from, err = utils.ParseTime(v, loc, false); err != nil {
+			log.Warn("Invalid range start", slog.String("from", v), slog.Any("err", err))
+			c.JSON(http.StatusBadRequest, Message{"Invalid from"})
+			return
+		}
+	}
+	if v := c.Query("to"); v != "" {
+		if to, err = utils.ParseTime(v, loc, true); err != nil {
+			log.Warn("Invalid range end", slog.String("to", v), slog.Any("err", err))
+			c.JSON(http.StatusBadRequest, Message{"Invalid to"})
+			return
+		}
+	}
+
+	burndown, err := h.service.Project.GetProjectBurndown(uint(id64), from, to, loc)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidRange) || errors.Is(err, services.ErrRangeTooLong) {
+			log.Warn("Invalid burn-down range", slog.Any("err", err))
+			c.JSON(http.StatusBadRequest, Message{err.Error()})
+			return
+		}
+		log.Error("Failed to get burn-down", slog.Uint64("project_id", id64), slog.Any("err", err))
+		c.JSON(http.StatusInternalServerError, Message{"failed to get burn-down"})
+		return
+	}
+
+	c.JSON(http.StatusOK, burndown)
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/moxicom/user_test/internal/models"
+	"github.com/moxicom/user_test/internal/services"
+	"github.com/moxicom/user_test/internal/storage"
+	"github.com/moxicom/user_test/internal/utils"
+)
+
+type rateInput struct {
+	UserID        *uint         `json:"user_id"`
+	ProjectID     *uint         `json:"project_id"`
+	TaskID        *uint         `json:"task_id"`
+	HourlyRate    *models.Cents `json:"hourly_rate" binding:"required" swaggertype:"number"`
+	EffectiveFrom string        `json:"effective_from" binding:"required" example:"2024-07-01"`
+}
+
+// CreateRate sets an hourly rate
+// @Summary Create a rate
+// @Description Set hourly rate of exactly one of user, project or task from a date (UTC) until the next rate of the same target. Task rate overrides project rate which overrides user rate
+// @Tags rates
+// @Accept json
+// @Produce json
+// @Param rate body rateInput true "Rate target, hourly rate and effective date in YYYY-MM-DD format"
+// @Success 200 {object} Message "Rate ID"
+// @Failure 400 {object} Message "Invalid body data"
+// @Failure 500 {object} Message "Failed to create rate"
+// @Router /rates [post]
+func (h *Handler) CreateRate(c *gin.Context) {
+	log := h.log.With(slog.String("op", "handler.CreateRate"))
+	var input rateInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		log.Error("error while parsing json ", slog.Any("err", err))
+		c.JSON(http.StatusBadRequest, Message{"invalid body data"})
+		return
+	}
+
+	effectiveFrom, err := utils.ParseTime(input.EffectiveFrom, time.UTC, false)
+	if err != nil {
+		log.Warn("Invalid effective date", slog.String("effective_from", input.EffectiveFrom), slog.Any("err", err))
+		c.JSON(http.StatusBadRequest, Message{"Invalid effective_from"})
+		return
+	}
+
+	rateID, err := h.service.Rate.CreateRate(models.Rate{
+		UserID:        input.UserID,
+		ProjectID:     input.ProjectID,
+		TaskID:        input.TaskID,
+		HourlyRate:    *input.HourlyRate,
+		EffectiveFrom: effectiveFrom,
+	})
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidRateTarget) || errors.Is(err, services.ErrNegativeRate) {
+			log.Warn("Invalid rate", slog.Any("err", err))
+			c.JSON(http.StatusBadRequest, Message{err.Error()})
+			return
+		}
+		log.Error("failed to create rate", slog.Any("err", err))
+		c.JSON(http.StatusInternalServerError, Message{"failed to create rate"})
+		return
+	}
+
+	log.Info("Rate created successfully", slog.Uint64("rate_id", uint64(rateID)))
+	c.JSON(http.StatusOK, Message{fmt.Sprint(rateID)})
+}
+
+// GetRates retrieves rates
+// @Summary Get rates
+// @Description Retrieve rates of a user, project or task, latest effective date first
+// @Tags rates
+// @Accept json
+// @Produce json
+// @Param user_id query int false "User ID"
+// @Param project_id query int false "Project ID"
+// @Param task_id query int false "Task ID"
+// @Success 200 {array} models.Rate "List of rates"
+// @Failure 400 {object} Message "Invalid input data"
+// @Failure 500 {object} Message "Failed to get rates"
+// @Router /rates [get]
+func (h *Handler) GetRates(c *gin.Context) {
+	log := h.log.With(slog.String("op", "handler.GetRates"))
+
+	var filters models.RateFilters
+	for key, target := range map[string]*uint{
+		"user_id":    &filters.UserID,
+		"project_id": &filters.ProjectID,
+		"task_id":    &filters.TaskID,
+	} {
+		v := c.Query(key)
+		if v == "" {
+			continue
+		}
+		id64, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			log.Warn("Invalid ID format", slog.String(key, v), slog.Any("err", err))
+			c.JSON(http.StatusBadRequest, Message{key + " should be integer"})
+			return
+		}
+		*target = uint(id64)
+	}
+
+	rates, err := h.service.Rate.GetRates(filters)
+	if err != nil {
+		log.Error("failed to get rates", slog.Any("err", err))
+		c.JSON(http.StatusInternalServerError, Message{"failed to get rates"})
+		return
+	}
+
+	c.JSON(http.StatusOK, rates)
+}
+
+// DeleteRate deletes a rate
+// @Summary Delete a rate
+// @Description Delete a rate by ID. The previous rate of the target applies again
+// @Tags rates
+// @Accept json
+// @Produce json
+// @Param id path int true "Rate ID"
+// @Success 200 {object} Message "Rate deleted"
+// @Failure 400 {object} Message "ID should be an integer"
+// @Failure 404 {object} Message "Rate not found"
+// @Failure 500 {object} Message "Failed to delete rate"
+// @Router /rates/{id} [delete]
+func (h *Handler) DeleteRate(c *gin.Context) {
+	log := h.log.With(slog.String("op", "handler.DeleteRate"))
+	id := c.Param("id")
+	id64, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		log.Warn("Invalid rate ID format", slog.String("id", id), slog.Any("err", err))
+		c.JSON(http.StatusBadRequest, Message{"id should be integer"})
+		return
+	}
+
+	if err := h.service.Rate.DeleteRate(uint(id64)); err != nil {
+		if errors.Is(err, storage.ErrRateNotFound) {
+			log.Warn("Rate not found", slog.String("id", id))
+			c.JSON(http.StatusNotFound, Message{"rate not found"})
+			return
+		}
+		log.Error("Failed to delete rate", slog.String("id", id), slog.Any("err", err))
+		c.JSON(http.StatusInternalServerError, Message{"failed to delete rate"})
+		return
+	}
+
+	log.Info("Rate deleted successfully", slog.String("id", id))
+	c.JSON(http.StatusOK, Message{"rate deleted"})
+}
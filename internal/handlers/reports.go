@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/moxicom/user_test/internal/models"
+	"github.com/moxicom/user_test/internal/services"
+)
+
+// GetTimeReport gets time worked by all users grouped by user, task or project
+// @Summary Get time report
+// @Description Get time worked within the range across all users or members of a team, grouped by user, task or project. Periods are clipped to the range
+// @Tags reports
+// @Accept json
+// @Produce json
+// @Param from query string true "Range start in RFC3339 or YYYY-MM-DD format"
+// @Param to query string true "Range end in RFC3339 or YYYY-MM-DD format, date includes the whole day"
+// @Param group_by query string false "Grouping, can be 'user', 'task' or 'project'. Default is 'user'"
+// @Param team_id query int false "Only count members of the team"
+// @Param sort query string false "Sort order by time, can be 'asc' or 'desc'. Default is 'desc'"
+// @Param limit query int false "Return only the first N rows"
+// @Param tz query string false "IANA time zone of dates without offset, server time zone by default"
+// @Success 200 {array} models.TimeReportRow "Time report"
+// @Failure 400 {object} Message "Invalid input data"
+// @Failure 500 {object} Message "Failed to get time report"
+// @Router /reports/time [get]
+func (h *Handler) GetTimeReport(c *gin.Context) {
+	log := h.log.With(slog.String("op", "handler.GetTimeReport"))
+
+	filters := models.TimeReportFilters{
+		GroupBy: models.ReportGroupBy(c.DefaultQuery("group_by", string(models.ReportByUser))),
+		Asc:     c.Query("sort") == asc,
+	}
+
+	if v := c.Query("team_id"); v != "" {
+		teamID, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			log.Warn("Invalid team ID format", slog.String("team_id", v), slog.Any("err", err))
+			c.JSON(http.StatusBadRequest, Message{"team_id should be integer"})
+			return
+		}
+		filters.TeamID = uint(teamID)
+	}
+
+	if v := c.Query("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			log.Warn("Invalid limit", slog.String("limit", v))
+			c.JSON(http.StatusBadRequest, Message{"limit should be positive integer"})
+			return
+		}
+		filters.Limit = limit
+	}
+
+	loc, ok := queryLocation(c, log)
+	if !ok {
+		return
+	}
+
+	from, to, ok := parseDateRange(c, log, loc, "from", "to")
+	if !ok {
+		return
+	}
+	filters.From, filters.To = from, to
+
+	report, err := h.service.Report.GetTimeReport(filters)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidReportGroupBy) || errors.Is(err, services.ErrInvalidRange) {
+			log.Warn("Invalid report parameters", slog.Any("err", err))
+			c.JSON(http.StatusBadRequest, Message{err.Error()})
+			return
+		}
+		log.Error("Failed to get time report", slog.Any("err", err))
+		c.JSON(http.StatusInternalServerError, Message{"Failed to get time report"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
@@ -9,18 +9,20 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/moxicom/user_test/internal/models"
+	"github.com/moxicom/user_test/internal/services"
 	"github.com/moxicom/user_test/internal/storage"
 )
 
 // CreateTask creates a new task
 // @Summary Create a new task
-// @Description Create a new task for a user
+// @Description Create a new task for a user. Tags are matched by name, missing tags are created. Optional estimate_seconds enables remaining and overrun in task reports and estimate alerts
 // @Tags tasks
 // @Accept json
 // @Produce json
 // @Param task body models.Task true "Task object"
 // @Success 200 {object} Message "Task created successfully"
-// @Failure 400 {object} Message "Invalid body data"
+// @Failure 400 {object} Message "Invalid body data, negative estimate or user is not active"
+// @Failure 404 {object} Message "User not found"
 // @Failure 500 {object} Message "Failed to create task"
 // @Router /tasks [post]
 func (h *Handler) CreateTask(c *gin.Context) {
@@ -34,6 +36,22 @@ func (h *Handler) CreateTask(c *gin.Context) {
 
 	taskID, err := h.service.Task.CreateTask(task)
 	if err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) {
+			log.Warn("Failed to create task. User not found", slog.Uint64("user_id", uint64(task.UserID)))
+			c.JSON(http.StatusNotFound, Message{err.Error()})
+			return
+		}
+		if errors.Is(err, services.ErrUserNotActive) {
+			log.Warn("Failed to create task. User is not active", slog.Uint64("user_id", uint64(task.UserID)))
+			c.JSON(http.StatusBadRequest, Message{err.Error()})
+			return
+		}
+		if errors.Is(err, services.ErrNegativeEstimate) {
+			log.Warn("Failed to create task", slog.Any("err", err))
+			c.JSON(http.StatusBadRequest, Message{err.Error()})
+			return
+		}
+		log.Error("failed to create task", slog.Any("err", err))
 		c.JSON(http.StatusInternalServerError, Message{"failed to create task"})
 		return
 	}
@@ -41,15 +59,184 @@ func (h *Handler) CreateTask(c *gin.Context) {
 	c.JSON(http.StatusOK, Message{fmt.Sprint(taskID)})
 }
 
+// GetTasks retrieves tasks based on filters
+// @Summary Get tasks
+// @Description Retrieve tasks with their tags based on filters
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Param tag query string false "Tag name"
+// @Param user_id query int false "User ID"
+// @Param project_id query int false "Project ID"
+// @Param sort query string false "Sort order by creation time, can be 'asc' or 'desc'"
+// @Success 200 {array} models.Task "List of tasks"
+// @Failure 400 {object} Message "Invalid input data"
+// @Failure 500 {object} Message "Failed to get tasks"
+// @Router /tasks [get]
+func (h *Handler) GetTasks(c *gin.Context) {
+	log := h.log.With(slog.String("op", "Handler.GetTasks"))
+
+	filters := models.TaskFilters{
+		Asc: c.Query("sort") == asc,
+		Tag: c.Query("tag"),
+	}
+	if userID := c.Query("user_id"); userID != "" {
+		userID64, err := strconv.ParseUint(userID, 10, 32)
+		if err != nil {
+			log.Warn("failed to parse user id", slog.Any("err", err))
+			c.JSON(http.StatusBadRequest, Message{"user_id should be integer"})
+			return
+		}
+		filters.UserID = uint(userID64)
+	}
+	if projectID := c.Query("project_id"); projectID != "" {
+		projectID64, err := strconv.ParseUint(projectID, 10, 32)
+		if err != nil {
+			log.Warn("failed to parse project id", slog.Any("err", err))
+			c.JSON(http.StatusBadRequest, Message{"project_id should be integer"})
+			return
+		}
+		filters.ProjectID = uint(projectID64)
+	}
+
+	tasks, err := h.service.Task.GetTasks(filters)
+	if err != nil {
+		log.Error("failed to get tasks", slog.Any("err", err))
+		c.JSON(http.StatusInternalServerError, Message{"failed to get tasks"})
+		return
+	}
+
+	c.JSON(http.StatusOK, tasks)
+}
+
+// UpdateTask updates a task
+// @Summary Update a task
+// @Description Update name, description, tags or estimate of a task. Omitted fields are kept, tags are replaced. Zero estimate removes it, changed estimate resets its alerts
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Param id path int true "Task ID"
+// @Param task body models.TaskUpdate true "Task fields to update"
+// @Success 200 {object} Message "Task updated"
+// @Failure 400 {object} Message "Invalid input data"
+// @Failure 500 {object} Message "Failed to update task"
+// @Router /tasks/{id} [patch]
+func (h *Handler) UpdateTask(c *gin.Context) {
+	log := h.log.With(slog.String("op", "Handler.UpdateTask"))
+
+	id := c.Param("id")
+	id64, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		log.Warn("failed to parse task id", slog.Any("err", err))
+		c.JSON(http.StatusBadRequest, Message{"id should be integer"})
+		return
+	}
+
+	var update models.TaskUpdate
+	if err := c.ShouldBindJSON(&update); err != nil {
+		log.Error("error while parsing json ", slog.Any("err", err))
+		c.JSON(http.StatusBadRequest, Message{"invalid body data"})
+		return
+	}
+
+	if update.TaskName == nil && update.Description == nil && update.Tags == nil && update.Estimate == nil {
+		log.Warn("No data to update for task", slog.Uint64("task_id", id64))
+		c.JSON(http.StatusBadRequest, Message{"no data to update. use task_name, description, tags, estimate_seconds"})
+		return
+	}
+
+	err = h.service.Task.UpdateTask(uint(id64), update)
+	if err != nil {
+		if errors.Is(err, services.ErrEmptyTaskName) || errors.Is(err, services.ErrNegativeEstimate) {
+			log.Warn("Failed to update task", slog.Uint64("task_id", id64), slog.Any("err", err))
+			c.JSON(http.StatusBadRequest, Message{err.Error()})
+			return
+		}
+		log.Error("failed to update task", slog.Uint64("task_id", id64), slog.Any("err", err))
+		c.JSON(http.StatusInternalServerError, Message{"failed to update task"})
+		return
+	}
+
+	log.Info("Task updated", slog.Uint64("task_id", id64))
+	c.JSON(http.StatusOK, Message{"task updated"})
+}
+
+// GetTaskAlerts retrieves estimate alerts
+// @Summary Get estimate alerts
+// @Description Retrieve alerts raised when tracked time of a task crossed a threshold percent of its estimate (80, 100 and 150 by default), latest first. Alerts are recorded by a periodic check and are pushed only to ESTIMATE_WEBHOOK_URL if set, so poll this endpoint otherwise
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Param task_id query int false "Task ID"
+// @Param user_id query int false "User ID"
+// @Param project_id query int false "Project ID"
+// @Success 200 {array} models.TaskAlert "List of alerts"
+// @Failure 400 {object} Message "Invalid input data"
+// @Failure 500 {object} Message "Failed to get alerts"
+// @Router /alerts [get]
+func (h *Handler) GetTaskAlerts(c *gin.Context) {
+	log := h.log.With(slog.String("op", "Handler.GetTaskAlerts"))
+
+	var filters models.AlertFilters
+	for key, target := range map[string]*uint{
+		"task_id":    &filters.TaskID,
+		"user_id":    &filters.UserID,
+		"project_id": &filters.ProjectID,
+	} {
+		v := c.Query(key)
+		if v == "" {
+			continue
+		}
+		id64, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			log.Warn("Invalid ID format", slog.String(key, v), slog.Any("err", err))
+			c.JSON(http.StatusBadRequest, Message{key + " should be integer"})
+			return
+		}
+		*target = uint(id64)
+	}
+
+	alerts, err := h.service.Task.GetTaskAlerts(filters)
+	if err != nil {
+		log.Error("failed to get alerts", slog.Any("err", err))
+		c.JSON(http.StatusInternalServerError, Message{"failed to get alerts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, alerts)
+}
+
+// GetTags retrieves all tags
+// @Summary Get tags
+// @Description Retrieve all task tags
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Success 200 {array} models.Tag "List of tags"
+// @Failure 500 {object} Message "Failed to get tags"
+// @Router /tags [get]
+func (h *Handler) GetTags(c *gin.Context) {
+	log := h.log.With(slog.String("op", "Handler.GetTags"))
+
+	tags, err := h.service.Task.GetTags()
+	if err != nil {
+		log.Error("failed to get tags", slog.Any("err", err))
+		c.JSON(http.StatusInternalServerError, Message{"failed to get tags"})
+		return
+	}
+
+	c.JSON(http.StatusOK, tags)
+}
+
 // DeleteTask deletes a task
 // @Summary Delete a task
-// @Description Delete a task by ID
+// @Description Delete a task by ID. Task with periods within an approved timesheet can not be deleted
 // @Tags tasks
 // @Accept json
 // @Produce json
 // @Param id path int true "Task ID"
 // @Success 200 {object} Message "Task deleted"
-// @Failure 400 {object} Message "ID should be an integer"
+// @Failure 400 {object} Message "ID should be an integer or task has approved or invoiced periods"
 // @Failure 500 {object} Message "Failed to delete task"
 // @Router /tasks/{id} [delete]
 func (h *Handler) DeleteTask(c *gin.Context) {
@@ -65,6 +252,11 @@ func (h *Handler) DeleteTask(c *gin.Context) {
 
 	err = h.service.Task.DeleteTask(uint(id64))
 	if err != nil {
+		if errors.Is(err, storage.ErrPeriodLocked) || errors.Is(err, storage.ErrPeriodInvoiced) {
+			log.Warn("Failed to delete task", slog.Uint64("task_id", id64), slog.Any("err", err))
+			c.JSON(http.StatusBadRequest, Message{err.Error()})
+			return
+		}
 		log.Error("failed to delete task", slog.Any("err", err))
 		c.JSON(http.StatusInternalServerError, Message{"failed to delete task"})
 		return
@@ -76,13 +268,13 @@ func (h *Handler) DeleteTask(c *gin.Context) {
 
 // FinishTask marks a task as finished
 // @Summary Finish a task
-// @Description Mark a task as finished by ID
+// @Description Mark a task as done by ID. Ongoing period is ended
 // @Tags tasks
 // @Accept json
 // @Produce json
 // @Param id path int true "Task ID"
 // @Success 200 {object} Message "Task ended"
-// @Failure 400 {object} Message "ID should be an integer"
+// @Failure 400 {object} Message "ID should be an integer or task can not be finished"
 // @Failure 500 {object} Message "Failed to finish task"
 // @Router /tasks/{id}/finish [post]
 func (h *Handler) FinishTask(c *gin.Context) {
@@ -98,6 +290,11 @@ func (h *Handler) FinishTask(c *gin.Context) {
 
 	err = h.service.Task.FinishTask(uint(id64))
 	if err != nil {
+		if errors.Is(err, services.ErrInvalidTaskTransition) || errors.Is(err, storage.ErrPeriodLocked) {
+			log.Warn("Failed to finish task", slog.Uint64("task_id", id64), slog.Any("err", err))
+			c.JSON(http.StatusBadRequest, Message{err.Error()})
+			return
+		}
 		log.Error("failed to finish task", slog.Any("err", err))
 		c.JSON(http.StatusInternalServerError, Message{"failed to finish task"})
 		return
@@ -106,6 +303,63 @@ func (h *Handler) FinishTask(c *gin.Context) {
 	c.JSON(http.StatusOK, Message{"task ended"})
 }
 
+// ReopenTask returns a finished task to work
+// @Summary Reopen a task
+// @Description Reopen a done or archived task. Task with tracked periods becomes paused, otherwise todo
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Param id path int true "Task ID"
+// @Success 200 {object} Message "Task reopened"
+// @Failure 400 {object} Message "ID should be an integer or task is not done"
+// @Failure 500 {object} Message "Failed to reopen task"
+// @Router /tasks/{id}/reopen [post]
+func (h *Handler) ReopenTask(c *gin.Context) {
+	h.changeTaskStatus(c, "Handler.ReopenTask", h.service.Task.ReopenTask, "task reopened")
+}
+
+// ArchiveTask archives a finished task
+// @Summary Archive a task
+// @Description Archive a done task
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Param id path int true "Task ID"
+// @Success 200 {object} Message "Task archived"
+// @Failure 400 {object} Message "ID should be an integer or task is not done"
+// @Failure 500 {object} Message "Failed to archive task"
+// @Router /tasks/{id}/archive [post]
+func (h *Handler) ArchiveTask(c *gin.Context) {
+	h.changeTaskStatus(c, "Handler.ArchiveTask", h.service.Task.ArchiveTask, "task archived")
+}
+
+func (h *Handler) changeTaskStatus(c *gin.Context, op string, change func(uint) error, msg string) {
+	log := h.log.With(slog.String("op", op))
+
+	id := c.Param("id")
+	id64, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		log.Warn("failed to parse task id", slog.Any("err", err))
+		c.JSON(http.StatusBadRequest, Message{"id should be integer"})
+		return
+	}
+
+	err = change(uint(id64))
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidTaskTransition) {
+			log.Warn("Failed to change task status", slog.Uint64("task_id", id64), slog.Any("err", err))
+			c.JSON(http.StatusBadRequest, Message{err.Error()})
+			return
+		}
+		log.Error("failed to change task status", slog.Uint64("task_id", id64), slog.Any("err", err))
+		c.JSON(http.StatusInternalServerError, Message{"failed to change task status"})
+		return
+	}
+
+	log.Info("Task status changed", slog.Uint64("task_id", id64))
+	c.JSON(http.StatusOK, Message{msg})
+}
+
 // StartPeriod starts a period for a task
 // @Summary Start a period for a task
 // @Description Start a period for a task by ID
@@ -115,7 +369,7 @@ func (h *Handler) FinishTask(c *gin.Context) {
 // @Param id path int true "Task ID"
 // @Success 200 {object} Message "Period started"
 // @Failure 400 {object} Message "ID should be an integer"
-// @Failure 400 {object} Message "Failed to start period. Period not finished"
+// @Failure 400 {object} Message "Failed to start period. Period not finished, another timer is running, task is done or user is not active"
 // @Failure 500 {object} Message "Failed to start"
 // @Router /tasks/{id}/start [post]
 func (h *Handler) StartPeriod(c *gin.Context) {
@@ -131,8 +385,11 @@ func (h *Handler) StartPeriod(c *gin.Context) {
 
 	err = h.service.StartPeriod(uint(id64))
 	if err != nil {
-		if errors.Is(err, storage.ErrPeriodNotFinished) {
-			log.Warn("Failed to start period. Period not finished", slog.Uint64("task_id", id64), slog.Any("err", err))
+		if errors.Is(err, storage.ErrPeriodNotFinished) ||
+			errors.Is(err, storage.ErrTimerRunning) ||
+			errors.Is(err, services.ErrUserNotActive) ||
+			errors.Is(err, services.ErrInvalidTaskTransition) {
+			log.Warn("Failed to start period", slog.Uint64("task_id", id64), slog.Any("err", err))
 			c.JSON(http.StatusBadRequest, Message{err.Error()})
 			return
 		}
@@ -154,7 +411,7 @@ func (h *Handler) StartPeriod(c *gin.Context) {
 // @Param id path int true "Task ID"
 // @Success 200 {object} Message "Period ended"
 // @Failure 400 {object} Message "ID should be an integer"
-// @Failure 400 {object} Message "Failed to end period. Period not started"
+// @Failure 400 {object} Message "Failed to end period. Period not started or within an approved timesheet"
 // @Failure 500 {object} Message "Failed to end"
 // @Router /tasks/{id}/end [post]
 func (h *Handler) EndPeriod(c *gin.Context) {
@@ -170,8 +427,8 @@ func (h *Handler) EndPeriod(c *gin.Context) {
 
 	err = h.service.EndPeriod(uint(id64))
 	if err != nil {
-		if errors.Is(err, storage.ErrPeriodNotStarted) {
-			log.Warn("Failed to end period. Period not started", slog.Uint64("task_id", id64), slog.Any("err", err))
+		if errors.Is(err, storage.ErrPeriodNotStarted) || errors.Is(err, storage.ErrPeriodLocked) {
+			log.Warn("Failed to end period", slog.Uint64("task_id", id64), slog.Any("err", err))
 			c.JSON(http.StatusBadRequest, Message{err.Error()})
 			return
 		}
@@ -183,3 +440,43 @@ func (h *Handler) EndPeriod(c *gin.Context) {
 	log.Info("Period ended", slog.Uint64("task_id", id64))
 	c.JSON(http.StatusOK, Message{"period ended"})
 }
+
+// SwitchTask switches the running timer to a task
+// @Summary Switch to a task
+// @Description End all running periods of the task owner and start a period for the task atomically
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Param id path int true "Task ID"
+// @Success 200 {object} Message "Switched to task"
+// @Failure 400 {object} Message "ID should be an integer, task is done or user is not active"
+// @Failure 500 {object} Message "Failed to switch"
+// @Router /tasks/{id}/switch [post]
+func (h *Handler) SwitchTask(c *gin.Context) {
+	log := h.log.With(slog.String("op", "Handler.SwitchTask"))
+
+	id := c.Param("id")
+	id64, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		log.Warn("failed to parse id", slog.Any("err", err))
+		c.JSON(http.StatusBadRequest, Message{"id should be integer"})
+		return
+	}
+
+	err = h.service.Task.SwitchTask(uint(id64))
+	if err != nil {
+		if errors.Is(err, services.ErrUserNotActive) ||
+			errors.Is(err, services.ErrInvalidTaskTransition) ||
+			errors.Is(err, storage.ErrPeriodLocked) {
+			log.Warn("Failed to switch to task", slog.Uint64("task_id", id64), slog.Any("err", err))
+			c.JSON(http.StatusBadRequest, Message{err.Error()})
+			return
+		}
+		log.Error("failed to switch to task", slog.Uint64("task_id", id64), slog.Any("err", err))
+		c.JSON(http.StatusInternalServerError, Message{"failed to switch"})
+		return
+	}
+
+	log.Info("Switched to task", slog.Uint64("task_id", id64))
+	c.JSON(http.StatusOK, Message{"switched to task"})
+}
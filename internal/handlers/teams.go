@@ -0,0 +1,325 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/moxicom/user_test/internal/models"
+	"github.com/moxicom/user_test/internal/services"
+	"github.com/moxicom/user_test/internal/storage"
+	"github.com/moxicom/user_test/internal/utils"
+)
+
+type addTeamMember struct {
+	UserID uint            `json:"user_id" binding:"required"`
+	Role   models.TeamRole `json:"role"`
+}
+
+// CreateTeam creates a new team
+// @Summary Create a new team
+// @Description Create a new team with the provided name
+// @Tags teams
+// @Accept json
+// @Produce json
+// @Param team body models.Team true "Team object"
+// @Success 200 {object} Message "Team ID"
+// @Failure 400 {object} Message "Invalid body data"
+// @Failure 500 {object} Message "Failed to create team"
+// @Router /teams [post]
+func (h *Handler) CreateTeam(c *gin.Context) {
+	log := h.log.With(slog.String("op", "handler.CreateTeam"))
+	var team models.Team
+	if err := c.ShouldBindJSON(&team); err != nil {
+		log.Error("error while parsing json ", slog.Any("err", err))
+		c.JSON(http.StatusBadRequest, Message{"invalid body data"})
+		return
+	}
+
+	teamID, err := h.service.Team.CreateTeam(team)
+	if err != nil {
+		log.Error("failed to create team", slog.Any("err", err))
+		c.JSON(http.StatusInternalServerError, Message{"failed to create team"})
+		return
+	}
+
+	log.Info("Team created successfully", slog.Uint64("team_id", uint64(teamID)))
+	c.JSON(http.StatusOK, Message{fmt.Sprint(teamID)})
+}
+
+// GetTeams retrieves all teams
+// @Summary Get teams
+// @Description Retrieve all teams
+// @Tags teams
+// @Accept json
+// @Produce json
+// @Success 200 {array} models.Team "List of teams"
+// @Failure 500 {object} Message "Failed to get teams"
+// @Router /teams [get]
+func (h *Handler) GetTeams(c *gin.Context) {
+	log := h.log.With(slog.String("op", "handler.GetTeams"))
+
+	teams, err := h.service.Team.GetTeams()
+	if err != nil {
+		log.Error("failed to get teams")
+		c.JSON(http.StatusInternalServerError, Message{"failed to get teams"})
+		return
+	}
+
+	c.JSON(http.StatusOK, teams)
+}
+
+// DeleteTeam deletes a team
+// @Summary Delete a team
+// @Description Delete a team by ID. Users are kept
+// @Tags teams
+// @Accept json
+// @Produce json
+// @Param id path int true "Team ID"
+// @Success 200 {object} Message "Team deleted"
+// @Failure 400 {object} Message "ID should be an integer"
+// @Failure 500 {object} Message "Failed to delete team"
+// @Router /teams/{id} [delete]
+func (h *Handler) DeleteTeam(c *gin.Context) {
+	log := h.log.With(slog.String("op", "handler.DeleteTeam"))
+	id := c.Param("id")
+	id64, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		log.Warn("Invalid team ID format", slog.String("id", id), slog.Any("err", err))
+		c.JSON(http.StatusBadRequest, Message{"id should be integer"})
+		return
+	}
+
+	err = h.service.Team.DeleteTeam(uint(id64))
+	if err != nil {
+		log.Error("Failed to delete team", slog.String("id", id))
+		c.JSON(http.StatusInternalServerError, Message{"failed to delete team"})
+		return
+	}
+
+	log.Info("Team deleted successfully", slog.String("id", id))
+	c.JSON(http.StatusOK, Message{"team deleted"})
+}
+
+// GetTeamMembers gets members of a team
+// @Summary Get team members
+// @Description Get users of a team with their roles
+// @Tags teams
+// @Accept json
+// @Produce json
+// @Param id path int true "Team ID"
+// @Success 200 {array} models.TeamMemberInfo "Team members"
+// @Failure 400 {object} Message "ID should be an integer"
+// @Failure 500 {object} Message "Failed to get team members"
+// @Router /teams/{id}/members [get]
+func (h *Handler) GetTeamMembers(c *gin.Context) {
+	log := h.log.With(slog.String("op", "handler.GetTeamMembers"))
+	id := c.Param("id")
+	id64, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		log.Warn("Invalid team ID format", slog.String("id", id), slog.Any("err", err))
+		c.JSON(http.StatusBadRequest, Message{"id should be integer"})
+		return
+	}
+
+	members, err := h.service.Team.GetTeamMembers(uint(id64))
+	if err != nil {
+		log.Error("Failed to get team members", slog.Uint64("team_id", id64))
+		c.JSON(http.StatusInternalServerError, Message{"failed to get team members"})
+		return
+	}
+
+	c.JSON(http.StatusOK, members)
+}
+
+// AddTeamMember adds a user to a team
+// @Summary Add a team member
+// @Description Add a user to a team as 'member' or 'manager'. Updates the role if user is already in the team
+// @Tags teams
+// @Accept json
+// @Produce json
+// @Param id path int true "Team ID"
+// @Param member body addTeamMember true "Member"
+// @Success 200 {object} Message "Member added"
+// @Failure 400 {object} Message "Invalid input data"
+// @Failure 404 {object} Message "Team or user not found"
+// @Failure 500 {object} Message "Failed to add team member"
+// @Router /teams/{id}/members [post]
+func (h *Handler) AddTeamMember(c *gin.Context) {
+	log := h.log.With(slog.String("op", "handler.AddTeamMember"))
+	id := c.Param("id")
+	id64, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		log.Warn("Invalid team ID format", slog.String("id", id), slog.Any("err", err))
+		c.JSON(http.StatusBadRequest, Message{"id should be integer"})
+		return
+	}
+
+	var member addTeamMember
+	if err := c.ShouldBindJSON(&member); err != nil {
+		log.Error("error while parsing json ", slog.Any("err", err))
+		c.JSON(http.StatusBadRequest, Message{"invalid body data"})
+		return
+	}
+
+	err = h.service.Team.AddTeamMember(models.TeamMember{
+		TeamID: uint(id64),
+		UserID: member.UserID,
+		Role:   member.Role,
+	})
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidTeamRole) {
+			log.Warn("Invalid team role", slog.String("role", string(member.Role)))
+			c.JSON(http.StatusBadRequest, Message{err.Error()})
+			return
+		}
+		if errors.Is(err, storage.ErrTeamNotFound) || errors.Is(err, storage.ErrUserNotFound) {
+			log.Warn("Failed to add team member", slog.Uint64("team_id", id64), slog.Any("err", err))
+			c.JSON(http.StatusNotFound, Message{err.Error()})
+			return
+		}
+		log.Error("Failed to add team member", slog.Uint64("team_id", id64), slog.Any("err", err))
+		c.JSON(http.StatusInternalServerError, Message{"failed to add team member"})
+		return
+	}
+
+	log.Info("Team member added", slog.Uint64("team_id", id64), slog.Uint64("user_id", uint64(member.UserID)))
+	c.JSON(http.StatusOK, Message{"member added"})
+}
+
+// RemoveTeamMember removes a user from a team
+// @Summary Remove a team member
+// @Description Remove a user from a team
+// @Tags teams
+// @Accept json
+// @Produce json
+// @Param id path int true "Team ID"
+// @Param user_id path int true "User ID"
+// @Success 200 {object} Message "Member removed"
+// @Failure 400 {object} Message "ID should be an integer"
+// @Failure 500 {object} Message "Failed to remove team member"
+// @Router /teams/{id}/members/{user_id} [delete]
+func (h *Handler) RemoveTeamMember(c *gin.Context) {
+	log := h.log.With(slog.String("op", "handler.RemoveTeamMember"))
+	teamID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		log.Warn("Invalid team ID format", slog.String("id", c.Param("id")), slog.Any("err", err))
+		c.JSON(http.StatusBadRequest, Message{"id should be integer"})
+		return
+	}
+
+	userID, err := strconv.ParseUint(c.Param("user_id"), 10, 32)
+	if err != nil {
+		log.Warn("Invalid user ID format", slog.String("user_id", c.Param("user_id")), slog.Any("err", err))
+		c.JSON(http.StatusBadRequest, Message{"user_id should be integer"})
+		return
+	}
+
+	err = h.service.Team.RemoveTeamMember(uint(teamID), uint(userID))
+	if err != nil {
+		log.Error("Failed to remove team member", slog.Uint64("team_id", teamID), slog.Any("err", err))
+		c.JSON(http.StatusInternalServerError, Message{"failed to remove team member"})
+		return
+	}
+
+	log.Info("Team member removed", slog.Uint64("team_id", teamID), slog.Uint64("user_id", userID))
+	c.JSON(http.StatusOK, Message{"member removed"})
+}
+
+// GetTeamUsers retrieves users of a team based on filters
+// @Summary Get team users
+// @Description Retrieve users of a team based on filters
+// @Tags teams
+// @Accept json
+// @Produce json
+// @Param id path int true "Team ID"
+// @Param passport_number query string false "Passport Number"
+// @Param surname query string false "Surname"
+// @Param name query string false "Name"
+// @Param patronymic query string false "Patronymic"
+// @Param address query string false "Address"
+// @Param status query string false "Status: active, suspended or terminated"
+// @Success 200 {array} models.User "List of users"
+// @Failure 400 {object} Message "ID should be an integer"
+// @Failure 500 {object} Message "Failed to get users"
+// @Router /teams/{id}/users [get]
+func (h *Handler) GetTeamUsers(c *gin.Context) {
+	log := h.log.With(slog.String("op", "handler.GetTeamUsers"))
+	id := c.Param("id")
+	id64, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		log.Warn("Invalid team ID format", slog.String("id", id), slog.Any("err", err))
+		c.JSON(http.StatusBadRequest, Message{"id should be integer"})
+		return
+	}
+
+	users, err := h.service.Team.GetTeamUsers(uint(id64), utils.GetFilters(c))
+	if err != nil {
+		log.Error("failed to get team users", slog.Uint64("team_id", id64))
+		c.JSON(http.StatusInternalServerError, Message{"failed to get users"})
+		return
+	}
+
+	c.JSON(http.StatusOK, users)
+}
+
+// GetTeamTasks gets the tasks of every team member
+// @Summary Get team tasks
+// @Description Get tasks of every team member within a specified date range and with optional sorting
+// @Tags teams
+// @Accept json
+// @Produce json
+// @Param id path int true "Team ID"
+// @Param start_date query string true "Start date in RFC3339 or YYYY-MM-DD format"
+// @Param end_date query string true "End date in RFC3339 or YYYY-MM-DD format, date includes the whole day"
+// @Param tz query string false "IANA time zone of dates without offset and of day boundaries, server time zone by default"
+// @Param sort query string false "Sort order, can be 'asc' or 'desc'"
+// @Param project_id query int false "Project ID"
+// @Success 200 {array} models.UserWithTasks "Tasks found"
+// @Failure 400 {object} Message "Invalid input data"
+// @Failure 500 {object} Message "Failed to get tasks for team"
+// @Router /teams/{id}/tasks [get]
+func (h *Handler) GetTeamTasks(c *gin.Context) {
+	log := h.log.With(slog.String("op", "handler.GetTeamTasks"))
+	id := c.Param("id")
+	id64, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		log.Warn("Invalid team ID format", slog.String("id", id), slog.Any("err", err))
+		c.JSON(http.StatusBadRequest, Message{"id should be integer"})
+		return
+	}
+
+	filters := models.TaskFilters{Asc: c.Query("sort") == asc}
+	if projectID := c.Query("project_id"); projectID != "" {
+		projectID64, err := strconv.ParseUint(projectID, 10, 32)
+		if err != nil {
+			log.Warn("Invalid project ID format", slog.String("project_id", projectID), slog.Any("err", err))
+			c.JSON(http.StatusBadRequest, Message{"project_id should be integer"})
+			return
+		}
+		filters.ProjectID = uint(projectID64)
+	}
+
+	loc, ok := queryLocation(c, log)
+	if !ok {
+		return
+	}
+
+	startDate, endDate, ok := parseDateRange(c, log, loc, "start_date", "end_date")
+	if !ok {
+		return
+	}
+
+	tasks, err := h.service.Team.GetTeamTasks(uint(id64), startDate, endDate, filters)
+	if err != nil {
+		log.Error("Failed to get tasks for team", slog.Uint64("team_id", id64))
+		c.JSON(http.StatusInternalServerError, Message{"Failed to get tasks for team"})
+		return
+	}
+
+	log.Info("Successfully found tasks for team", slog.Uint64("team_id", id64))
+	c.JSON(http.StatusOK, tasks)
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetActiveTimers lists running timers of all users
+// @Summary Get running timers
+// @Description List every running period with its task, user and elapsed time
+// @Tags timers
+// @Accept json
+// @Produce json
+// @Success 200 {array} models.RunningPeriod "Running timers"
+// @Failure 500 {object} Message "Failed to get running timers"
+// @Router /timers/active [get]
+func (h *Handler) GetActiveTimers(c *gin.Context) {
+	log := h.log.With(slog.String("op", "handler.GetActiveTimers"))
+
+	timers, err := h.service.Task.GetRunningTimers(0)
+	if err != nil {
+		log.Error("Failed to get running timers", slog.Any("err", err))
+		c.JSON(http.StatusInternalServerError, Message{"failed to get running timers"})
+		return
+	}
+
+	c.JSON(http.StatusOK, timers)
+}
+
+// GetUserActiveTimers lists running timers of a user
+// @Summary Get running timers of a user
+// @Description List running periods of a user with task and elapsed time
+// @Tags timers
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {array} models.RunningPeriod "Running timers"
+// @Failure 400 {object} Message "ID should be an integer"
+// @Failure 500 {object} Message "Failed to get running timers"
+// @Router /users/{id}/timers/active [get]
+func (h *Handler) GetUserActiveTimers(c *gin.Context) {
+	log := h.log.With(slog.String("op", "handler.GetUserActiveTimers"))
+	id := c.Param("id")
+	id64, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		log.Warn("Invalid user ID format", slog.String("id", id), slog.Any("err", err))
+		c.JSON(http.StatusBadRequest, Message{"id should be integer"})
+		return
+	}
+
+	timers, err := h.service.Task.GetRunningTimers(uint(id64))
+	if err != nil {
+		log.Error("Failed to get running timers", slog.Uint64("user_id", id64), slog.Any("err", err))
+		c.JSON(http.StatusInternalServerError, Message{"failed to get running timers"})
+		return
+	}
+
+	c.JSON(http.StatusOK, timers)
+}
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/moxicom/user_test/internal/services"
+	"github.com/moxicom/user_test/internal/utils"
+)
+
+const xlsxContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+// GetUserTimesheetXLSX exports weekly timesheet of a user as XLSX
+// @Summary Export weekly timesheet as XLSX
+// @Description Export workbook with hours of a user per task and day of the week (from Monday), totals and a summary sheet
+// @Tags timesheets
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param id path int true "User ID"
+// @Param week query string false "Any date of the week in RFC3339 or YYYY-MM-DD format, current week by default"
+// @Param tz query string false "IANA time zone of the week days, user time zone by default"
+// @Success 200 {file} file "XLSX workbook"
+// @Failure 400 {object} Message "Invalid input data"
+// @Failure 404 {object} Message "User not found"
+// @Failure 500 {object} Message "Failed to get timesheet"
+// @Router /users/{id}/timesheet.xlsx [get]
+func (h *Handler) GetUserTimesheetXLSX(c *gin.Context) {
+	log := h.log.With(slog.String("op", "handler.GetUserTimesheetXLSX"))
+	id := c.Param("id")
+	id64, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		log.Warn("Invalid user ID format", slog.String("id", id), slog.Any("err", err))
+		c.JSON(http.StatusBadRequest, Message{"id should be integer"})
+		return
+	}
+
+	loc, ok := h.userLocation(c, log, uint(id64))
+	if !ok {
+		return
+	}
+
+	day := time.Now()
+	if v := c.Query("week"); v != "" {
+		if day, err = utils.ParseTime(v, loc, false); err != nil {
+			log.Warn("Invalid week", slog.String("week", v), slog.Any("err", err))
+			c.JSON(http.StatusBadRequest, Message{"Invalid week"})
+			return
+		}
+	}
+
+	data, err := h.service.User.GetUserTimesheetXLSX(uint(id64), day, loc)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidRange) || errors.Is(err, services.ErrRangeTooLong) {
+			c.JSON(http.StatusBadRequest, Message{err.Error()})
+			return
+		}
+		log.Error("Failed to get timesheet", slog.Uint64("user_id", id64), slog.Any("err", err))
+		c.JSON(http.StatusInternalServerError, Message{"Failed to get timesheet"})
+		return
+	}
+
+	year, week := day.In(loc).ISOWeek()
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="timesheet-%d-%d-W%02d.xlsx"`, id64, year, week))
+	c.Data(http.StatusOK, xlsxContentType, data)
+}
+
+// GetUserTimesheetHTML renders printable timesheet of a user
+// @Summary Get printable timesheet
+// @Description Render HTML timesheet of a user with task totals of the range, grand total and signatures block. It has a print stylesheet for A4
+// @Tags timesheets
+// @Produce html
+// @Param id path int true "User ID"
+// @Param from query string true "Range start in RFC3339 or YYYY-MM-DD format"
+// @Param to query string true "Range end in RFC3339 or YYYY-MM-DD format, date includes the whole day"
+// @Param tz query string false "IANA time zone of dates without offset, user time zone by default"
+// @Success 200 {string} string "HTML timesheet"
+// @Failure 400 {object} Message "Invalid input data"
+// @Failure 404 {object} Message "User not found"
+// @Failure 500 {object} Message "Failed to get timesheet"
+// @Router /users/{id}/timesheet.html [get]
+func (h *Handler) GetUserTimesheetHTML(c *gin.Context) {
+	h.printableTimesheet(c, "handler.GetUserTimesheetHTML", "text/html; charset=utf-8", h.service.User.GetUserTimesheetHTML)
+}
+
+// GetUserTimesheetPDF renders timesheet of a user as PDF
+// @Summary Get timesheet PDF
+// @Description Render PDF timesheet of a user with task totals of the range, grand total and signatures block. Text is set in embedded DejaVu Sans
+// @Tags timesheets
+// @Produce application/pdf
+// @Param id path int true "User ID"
+// @Param from query string true "Range start in RFC3339 or YYYY-MM-DD format"
+// @Param to query string true "Range end in RFC3339 or YYYY-MM-DD format, date includes the whole day"
+// @Param tz query string false "IANA time zone of dates without offset, user time zone by default"
+// @Success 200 {file} file "PDF timesheet"
+// @Failure 400 {object} Message "Invalid input data"
+// @Failure 404 {object} Message "User not found"
+// @Failure 500 {object} Message "Failed to get timesheet"
+// @Router /users/{id}/timesheet.pdf [get]
+func (h *Handler) GetUserTimesheetPDF(c *gin.Context) {
+	h.printableTimesheet(c, "handler.GetUserTimesheetPDF", "application/pdf", h.service.User.GetUserTimesheetPDF)
+}
+
+// printableTimesheet parses user and range and writes document rendered by render
+func (h *Handler) printableTimesheet(c *gin.Context, op string, contentType string,
+	render func(uint, time.Time, time.Time, *time.Location) ([]byte, error)) {
+	log := h.log.With(slog.String("op", op))
+	id := c.Param("id")
+	id64, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		log.Warn("Invalid user ID format", slog.String("id", id), slog.Any("err", err))
+		c.JSON(http.StatusBadRequest, Message{"id should be integer"})
+		return
+	}
+
+	loc, ok := h.userLocation(c, log, uint(id64))
+	if !ok {
+		return
+	}
+
+	from, to, ok := parseDateRange(c, log, loc, "from", "to")
+	if !ok {
+		return
+	}
+
+	data, err := render(uint(id64), from, to, loc)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidRange) || errors.Is(err, services.ErrRangeTooLong) {
+			c.JSON(http.StatusBadRequest, Message{err.Error()})
+			return
+		}
+		log.Error("Failed to get timesheet", slog.Uint64("user_id", id64), slog.Any("err", err))
+		c.JSON(http.StatusInternalServerError, Message{"Failed to get timesheet"})
+		return
+	}
+
+	c.Data(http.StatusOK, contentType, data)
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -9,6 +10,8 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/moxicom/user_test/internal/models"
+	"github.com/moxicom/user_test/internal/services"
+	"github.com/moxicom/user_test/internal/storage"
 	"github.com/moxicom/user_test/internal/utils"
 )
 
@@ -71,6 +74,10 @@ func (h *Handler) CreateUser(c *gin.Context) {
 // @Param name query string false "Name"
 // @Param patronymic query string false "Patronymic"
 // @Param address query string false "Address"
+// @Param email query string false "Email"
+// @Param status query string false "Status: active, suspended or terminated"
+// @Param team_id query int false "Team ID"
+// @Param single_timer query bool false "Single timer policy"
 // @Success 200 {array} models.User "List of users"
 // @Failure 500 {object} Message "Failed to get users"
 // @Router /users [get]
@@ -101,6 +108,10 @@ func (h *Handler) GetUsers(c *gin.Context) {
 // @Param name query string false "Name"
 // @Param patronymic query string false "Patronymic"
 // @Param address query string false "Address"
+// @Param email query string false "Email"
+// @Param single_timer query bool false "Allow at most one running timer across all user tasks"
+// @Param workday_end query string false "Workday end in HH:MM format. Running periods are auto-closed after it. Empty or null clears it"
+// @Param time_zone query string false "IANA time zone of user reports and workday end. Empty or null clears it"
 // @Success 200 {object} Message "User updated"
 // @Failure 400 {object} Message "Incorrect ID or invalid input data"
 // @Failure 500 {object} Message "Failed to update user"
@@ -115,12 +126,28 @@ func (h *Handler) UpdateUser(c *gin.Context) {
 		return
 	}
 
-	if filt.Address == "" && filt.Name == "" && filt.Patronymic == "" && filt.PassportNumber == "" && filt.Surname == "" {
+	if filt.Address == "" && filt.Name == "" && filt.Patronymic == "" && filt.PassportNumber == "" && filt.Surname == "" && filt.Email == "" && filt.SingleTimer == nil && filt.WorkdayEnd == nil && filt.TimeZone == nil {
 		log.Warn("No data to update for user", slog.Uint64("user_id", id64))
-		c.JSON(http.StatusBadRequest, Message{"no data to update. use passport_number, surname, name, patronymic, address"})
+		c.JSON(http.StatusBadRequest, Message{"no data to update. use passport_number, surname, name, patronymic, address, email, single_timer, workday_end, time_zone"})
 		return
 	}
 
+	if filt.TimeZone != nil && *filt.TimeZone != "" {
+		if _, err := time.LoadLocation(*filt.TimeZone); err != nil {
+			log.Warn("Invalid time zone", slog.String("time_zone", *filt.TimeZone))
+			c.JSON(http.StatusBadRequest, Message{"invalid time zone. use IANA name like Europe/Moscow"})
+			return
+		}
+	}
+
+	if filt.WorkdayEnd != nil && *filt.WorkdayEnd != "" {
+		if _, err := time.Parse(models.WorkdayEndLayout, *filt.WorkdayEnd); err != nil {
+			log.Warn("Invalid workday end", slog.String("workday_end", *filt.WorkdayEnd))
+			c.JSON(http.StatusBadRequest, Message{"invalid workday end. use HH:MM"})
+			return
+		}
+	}
+
 	// Validate password number
 	if filt.PassportNumber != "" {
 		ok := utils.ValidatePassword(filt.PassportNumber)
@@ -145,13 +172,14 @@ func (h *Handler) UpdateUser(c *gin.Context) {
 
 // DeleteUser deletes a user
 // @Summary Delete a user
-// @Description Delete a user by ID
+// @Description Delete a user by ID with tasks and periods. User with approved timesheets can not be deleted
 // @Tags users
 // @Accept json
 // @Produce json
 // @Param id path int true "User ID"
 // @Success 200 {object} Message "User deleted"
-// @Failure 400 {object} Message "ID should be an integer"
+// @Failure 400 {object} Message "ID should be an integer or user has approved timesheets"
+// @Failure 404 {object} Message "User not found"
 // @Failure 500 {object} Message "Failed to delete user"
 // @Router /users/{id} [delete]
 func (h *Handler) DeleteUser(c *gin.Context) {
@@ -165,6 +193,16 @@ func (h *Handler) DeleteUser(c *gin.Context) {
 	}
 
 	err = h.service.User.DeleteUser(uint(id64))
+	if errors.Is(err, storage.ErrUserHasApproved) {
+		log.Warn("Failed to delete user", slog.String("id", id), slog.Any("err", err))
+		c.JSON(http.StatusBadRequest, Message{err.Error()})
+		return
+	}
+	if errors.Is(err, storage.ErrUserNotFound) {
+		log.Warn("User not found", slog.String("id", id))
+		c.JSON(http.StatusNotFound, Message{err.Error()})
+		return
+	}
 	if err != nil {
 		log.Error("Failed to delete user", slog.String("id", id))
 		c.JSON(http.StatusInternalServerError, Message{"failed to delete user"})
@@ -177,16 +215,20 @@ func (h *Handler) DeleteUser(c *gin.Context) {
 
 // GetUsersWithTasks gets the tasks for a user
 // @Summary Get user tasks
-// @Description Get tasks for a user within a specified date range and with optional sorting
+// @Description Get tasks a user worked on within a specified date range and with optional sorting. Only time inside the range is counted
 // @Tags users
 // @Accept json
 // @Produce json
 // @Param id path int true "User ID"
-// @Param start_date query string true "Start date in RFC3339 format"
-// @Param end_date query string true "End date in RFC3339 format"
+// @Param start_date query string true "Start date in RFC3339 or YYYY-MM-DD format"
+// @Param end_date query string true "End date in RFC3339 or YYYY-MM-DD format, date includes the whole day"
+// @Param tz query string false "IANA time zone of dates without offset and of day boundaries, user time zone by default"
 // @Param sort query string false "Sort order, can be 'asc' or 'desc'"
+// @Param project_id query int false "Project ID"
+// @Param tag query string false "Tag name"
 // @Success 200 {array} models.Task "Tasks found"
 // @Failure 400 {object} Message "Invalid input data"
+// @Failure 404 {object} Message "User not found"
 // @Failure 500 {object} Message "Failed to get tasks for user"
 // @Router /users/{id}/tasks [get]
 func (h *Handler) GetUsersWithTasks(c *gin.Context) {
@@ -205,18 +247,24 @@ func (h *Handler) GetUsersWithTasks(c *gin.Context) {
 	} else {
 		filters.Asc = false
 	}
+	if projectID := c.Query("project_id"); projectID != "" {
+		projectID64, err := strconv.ParseUint(projectID, 10, 32)
+		if err != nil {
+			log.Warn("Invalid project ID format", slog.String("project_id", projectID), slog.Any("err", err))
+			c.JSON(http.StatusBadRequest, Message{"project_id should be integer"})
+			return
+		}
+		filters.ProjectID = uint(projectID64)
+	}
+	filters.Tag = c.Query("tag")
 
-	startDate, err := time.Parse(time.RFC3339, c.Query("start_date"))
-	if err != nil {
-		log.Warn("No start date to get tasks for user", slog.Uint64("user_id", id64), slog.Any("err", err))
-		c.JSON(http.StatusBadRequest, Message{"Invalid start date"})
+	loc, ok := h.userLocation(c, log, uint(id64))
+	if !ok {
 		return
 	}
 
-	endDate, err := time.Parse(time.RFC3339, c.Query("end_date"))
-	if err != nil {
-		log.Warn("No end date to get tasks for user", slog.Uint64("user_id", id64))
-		c.JSON(http.StatusBadRequest, Message{"Invalid end date"})
+	startDate, endDate, ok := parseDateRange(c, log, loc, "start_date", "end_date")
+	if !ok {
 		return
 	}
 
@@ -230,3 +278,224 @@ func (h *Handler) GetUsersWithTasks(c *gin.Context) {
 	log.Info("Successfully found tasks for user", slog.Uint64("user_id", id64))
 	c.JSON(http.StatusOK, tasks)
 }
+
+// SuspendUser suspends an active user
+// @Summary Suspend a user
+// @Description Suspend an active user. Suspended users can not create tasks or start periods
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param effective_date query string false "Effective date in RFC3339 format, defaults to now. Can not be in the future"
+// @Success 200 {object} Message "User suspended"
+// @Failure 400 {object} Message "Invalid input data or invalid status transition"
+// @Failure 404 {object} Message "User not found"
+// @Failure 500 {object} Message "Failed to suspend user"
+// @Router /users/{id}/suspend [post]
+func (h *Handler) SuspendUser(c *gin.Context) {
+	h.changeUserStatus(c, "handler.SuspendUser", h.service.User.SuspendUser, "user suspended")
+}
+
+// ReactivateUser reactivates a suspended user
+// @Summary Reactivate a user
+// @Description Reactivate a suspended user
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param effective_date query string false "Effective date in RFC3339 format, defaults to now. Can not be in the future"
+// @Success 200 {object} Message "User reactivated"
+// @Failure 400 {object} Message "Invalid input data or invalid status transition"
+// @Failure 404 {object} Message "User not found"
+// @Failure 500 {object} Message "Failed to reactivate user"
+// @Router /users/{id}/reactivate [post]
+func (h *Handler) ReactivateUser(c *gin.Context) {
+	h.changeUserStatus(c, "handler.ReactivateUser", h.service.User.ReactivateUser, "user reactivated")
+}
+
+// TerminateUser terminates a user keeping tasks history
+// @Summary Terminate a user
+// @Description Terminate an active or suspended user. Unlike delete, tasks and periods are kept
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param effective_date query string false "Effective date in RFC3339 format, defaults to now. Can not be in the future"
+// @Success 200 {object} Message "User terminated"
+// @Failure 400 {object} Message "Invalid input data or invalid status transition"
+// @Failure 404 {object} Message "User not found"
+// @Failure 500 {object} Message "Failed to terminate user"
+// @Router /users/{id}/terminate [post]
+func (h *Handler) TerminateUser(c *gin.Context) {
+	h.changeUserStatus(c, "handler.TerminateUser", h.service.User.TerminateUser, "user terminated")
+}
+
+func (h *Handler) changeUserStatus(c *gin.Context, op string, change func(uint, time.Time) error, msg string) {
+	log := h.log.With(slog.String("op", op))
+	id := c.Param("id")
+	id64, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		log.Warn("Invalid user ID format", slog.String("id", id), slog.Any("err", err))
+		c.JSON(http.StatusBadRequest, Message{"id should be integer"})
+		return
+	}
+
+	effective := time.Now()
+	if date := c.Query("effective_date"); date != "" {
+		effective, err = time.Parse(time.RFC3339, date)
+		if err != nil {
+			log.Warn("Invalid effective date", slog.String("effective_date", date), slog.Any("err", err))
+			c.JSON(http.StatusBadRequest, Message{"Invalid effective date"})
+			return
+		}
+	}
+
+	err = change(uint(id64), effective)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidStatusTransition) || errors.Is(err, services.ErrFutureEffectiveDate) {
+			log.Warn("Invalid status change", slog.Uint64("user_id", id64), slog.Any("err", err))
+			c.JSON(http.StatusBadRequest, Message{err.Error()})
+			return
+		}
+		if errors.Is(err, storage.ErrUserNotFound) {
+			log.Warn("User not found", slog.Uint64("user_id", id64))
+			c.JSON(http.StatusNotFound, Message{err.Error()})
+			return
+		}
+		log.Error("Failed to change user status", slog.Uint64("user_id", id64), slog.Any("err", err))
+		c.JSON(http.StatusInternalServerError, Message{"failed to change user status"})
+		return
+	}
+
+	log.Info("User status changed", slog.Uint64("user_id", id64))
+	c.JSON(http.StatusOK, Message{msg})
+}
+
+// GetManagedUsers gets users managed by a user
+// @Summary Get subordinates
+// @Description Get members of all teams where the user is a manager
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {array} models.User "List of users"
+// @Failure 400 {object} Message "ID should be an integer"
+// @Failure 500 {object} Message "Failed to get subordinates"
+// @Router /users/{id}/subordinates [get]
+func (h *Handler) GetManagedUsers(c *gin.Context) {
+	log := h.log.With(slog.String("op", "handler.GetManagedUsers"))
+	id := c.Param("id")
+	id64, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		log.Warn("Invalid user ID format", slog.String("id", id), slog.Any("err", err))
+		c.JSON(http.StatusBadRequest, Message{"id should be integer"})
+		return
+	}
+
+	users, err := h.service.Team.GetManagedUsers(uint(id64))
+	if err != nil {
+		log.Error("Failed to get subordinates", slog.Uint64("user_id", id64))
+		c.JSON(http.StatusInternalServerError, Message{"failed to get subordinates"})
+		return
+	}
+
+	c.JSON(http.StatusOK, users)
+}
+
+// GetUserTags gets time of a user aggregated by tag
+// @Summary Get user time by tag
+// @Description Get total time of user tasks within a specified date range grouped by tag. Task with several tags counts in each of them
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param start_date query string true "Start date in RFC3339 or YYYY-MM-DD format"
+// @Param end_date query string true "End date in RFC3339 or YYYY-MM-DD format, date includes the whole day"
+// @Param tz query string false "IANA time zone of dates without offset and of day boundaries, user time zone by default"
+// @Success 200 {array} models.TagWithTotalTime "Tags found"
+// @Failure 400 {object} Message "Invalid input data"
+// @Failure 404 {object} Message "User not found"
+// @Failure 500 {object} Message "Failed to get tags for user"
+// @Router /users/{id}/tags [get]
+func (h *Handler) GetUserTags(c *gin.Context) {
+	log := h.log.With(slog.String("op", "handler.GetUserTags"))
+	id := c.Param("id")
+	id64, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		log.Warn("Invalid user ID format", slog.String("id", id), slog.Any("err", err))
+		c.JSON(http.StatusBadRequest, Message{"id should be integer"})
+		return
+	}
+
+	loc, ok := h.userLocation(c, log, uint(id64))
+	if !ok {
+		return
+	}
+
+	startDate, endDate, ok := parseDateRange(c, log, loc, "start_date", "end_date")
+	if !ok {
+		return
+	}
+
+	tags, err := h.service.User.GetUserTags(uint(id64), startDate, endDate)
+	if err != nil {
+		log.Error("Failed to get tags for user", slog.Uint64("user_id", id64))
+		c.JSON(http.StatusInternalServerError, Message{"Failed to get tags for user"})
+		return
+	}
+
+	c.JSON(http.StatusOK, tags)
+}
+
+// GetUserSummary gets time worked by a user per day, week or month
+// @Summary Get user time summary
+// @Description Get time worked by a user in every day, week (from Monday) or month of the range, in total and per task. Periods crossing a boundary are split between buckets
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param group_by query string false "Bucket size, can be 'day', 'week' or 'month'. Default is 'day'"
+// @Param from query string true "Range start in RFC3339 or YYYY-MM-DD format"
+// @Param to query string true "Range end in RFC3339 or YYYY-MM-DD format, date includes the whole day. Range is at most 3660 days"
+// @Param tz query string false "IANA time zone of dates without offset and of day boundaries, user time zone by default"
+// @Success 200 {array} models.SummaryBucket "Summary"
+// @Failure 400 {object} Message "Invalid input data or too long range"
+// @Failure 404 {object} Message "User not found"
+// @Failure 500 {object} Message "Failed to get summary for user"
+// @Router /users/{id}/summary [get]
+func (h *Handler) GetUserSummary(c *gin.Context) {
+	log := h.log.With(slog.String("op", "handler.GetUserSummary"))
+	id := c.Param("id")
+	id64, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		log.Warn("Invalid user ID format", slog.String("id", id), slog.Any("err", err))
+		c.JSON(http.StatusBadRequest, Message{"id should be integer"})
+		return
+	}
+
+	groupBy := models.GroupBy(c.DefaultQuery("group_by", string(models.GroupByDay)))
+
+	loc, ok := h.userLocation(c, log, uint(id64))
+	if !ok {
+		return
+	}
+
+	from, to, ok := parseDateRange(c, log, loc, "from", "to")
+	if !ok {
+		return
+	}
+
+	summary, err := h.service.User.GetUserSummary(uint(id64), from, to, groupBy, loc)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidGroupBy) || errors.Is(err, services.ErrInvalidRange) || errors.Is(err, services.ErrRangeTooLong) {
+			log.Warn("Invalid summary parameters", slog.Uint64("user_id", id64), slog.Any("err", err))
+			c.JSON(http.StatusBadRequest, Message{err.Error()})
+			return
+		}
+		log.Error("Failed to get summary for user", slog.Uint64("user_id", id64), slog.Any("err", err))
+		c.JSON(http.StatusInternalServerError, Message{"Failed to get summary for user"})
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
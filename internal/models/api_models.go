@@ -1,13 +1,99 @@
 package models
 
+import "time"
+
 type UserFilters struct {
 	PassportNumber string
 	Surname        string
 	Name           string
 	Patronymic     string
 	Address        string
+	Email          string
+	Status         UserStatus
+	TeamID         uint
+	SingleTimer    *bool
+	WorkdayEnd     *string // empty clears it, only on update
+	TimeZone       *string // empty clears it, only on update
 }
 
 type TaskFilters struct {
-	Asc bool
+	Asc       bool
+	ProjectID uint
+	UserID    uint
+	Tag       string
+}
+
+type TaskUpdate struct {
+	TaskName    *string   `json:"task_name"`
+	Description *string   `json:"description"`
+	Tags        *[]string `json:"tags"`
+	Estimate    *int64    `json:"estimate_seconds"` // zero removes the estimate
+}
+
+type ProjectFilters struct {
+	Name         string
+	Client       string
+	Description  string
+	BudgetHours  *float64 // zero removes the budget, only on update
+	BudgetAmount *Cents   // zero removes the budget, only on update
+}
+
+// CalendarImportRules select calendar events to import. Empty rule matches
+// every event
+type CalendarImportRules struct {
+	Category  string
+	Prefix    string // stripped from task name
+	ProjectID *uint
+}
+
+// CSVImportOptions of time entries import. Empty Format is detected from
+// header, empty TimeZone means time zone of each user
+type CSVImportOptions struct {
+	Format   CSVFormat
+	DryRun   bool
+	TimeZone string
+}
+
+// SubmissionFilters select timesheet submissions. ManagerID selects
+// submissions of users managed by the manager
+type SubmissionFilters struct {
+	UserID    uint
+	ManagerID uint
+	Status    SubmissionStatus
+}
+
+// RateFilters select rates of a user, project or task
+type RateFilters struct {
+	UserID    uint
+	ProjectID uint
+	TaskID    uint
+}
+
+// InvoiceSelection selects unbilled billable periods of the project, or of
+// projects of the client, started within [From, To)
+type InvoiceSelection struct {
+	ProjectID uint
+	Client    string
+	From      time.Time
+	To        time.Time
+}
+
+type AlertFilters struct {
+	TaskID    uint
+	UserID    uint
+	ProjectID uint
+}
+
+type InvoiceFilters struct {
+	ProjectID uint
+	Client    string
+}
+
+type TimeReportFilters struct {
+	From    time.Time
+	To      time.Time
+	GroupBy ReportGroupBy
+	TeamID  uint
+	Asc     bool
+	Limit   int
 }
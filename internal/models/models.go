@@ -2,35 +2,482 @@ package models
 
 import "time"
 
+type UserStatus string
+
+const (
+	UserActive     UserStatus = "active"
+	UserSuspended  UserStatus = "suspended"
+	UserTerminated UserStatus = "terminated"
+)
+
+// userTransitions lists statuses reachable from each user status.
+// Termination is final
+var userTransitions = map[UserStatus][]UserStatus{
+	UserActive:    {UserSuspended, UserTerminated},
+	UserSuspended: {UserActive, UserTerminated},
+}
+
+// CanTransitionTo reports whether user in status s can be moved to status to
+func (s UserStatus) CanTransitionTo(to UserStatus) bool {
+	for _, next := range userTransitions[s] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// WorkdayEndLayout is the time layout of User.WorkdayEnd
+const WorkdayEndLayout = "15:04"
+
 type User struct {
-	ID             uint   `gorm:"primarykey"`
-	PassportNumber string `gorm:"uniqueIndex" json:"passport_number"`
-	Surname        string `json:"surname"`
-	Name           string `json:"name"`
-	Patronymic     string `json:"patronymic"`
-	Address        string `json:"address"`
-	Tasks          []Task `json:"-" gorm:"constraint:OnDelete:CASCADE;"` // Establish the relationship and enable cascading deletes
+	ID              uint                  `gorm:"primarykey"`
+	PassportNumber  string                `gorm:"uniqueIndex" json:"passport_number"`
+	Surname         string                `json:"surname"`
+	Name            string                `json:"name"`
+	Patronymic      string                `json:"patronymic"`
+	Address         string                `json:"address"`
+	Email           string                `json:"email" gorm:"index"`
+	Status          UserStatus            `json:"status" gorm:"default:active;index"`
+	StatusChangedAt *time.Time            `json:"status_changed_at"`
+	TerminatedAt    *time.Time            `json:"terminated_at"`
+	SingleTimer     bool                  `json:"single_timer"`                          // at most one running period across all user tasks
+	WorkdayEnd      string                `json:"workday_end"`                           // HH:MM after which running periods are auto-closed
+	TimeZone        string                `json:"time_zone"`                             // IANA name, server time zone if empty
+	CalendarToken   *string               `json:"-" gorm:"uniqueIndex"`                  // secret of the calendar feed URL
+	Tasks           []Task                `json:"-" gorm:"constraint:OnDelete:CASCADE;"` // Establish the relationship and enable cascading deletes
+	Teams           []TeamMember          `json:"-" gorm:"constraint:OnDelete:CASCADE;"`
+	Timesheets      []TimesheetSubmission `json:"-" gorm:"constraint:OnDelete:CASCADE;"`
+	Rates           []Rate                `json:"-" gorm:"constraint:OnDelete:CASCADE;"`
+}
+
+type TaskStatus string
+
+const (
+	TaskTodo       TaskStatus = "todo"
+	TaskInProgress TaskStatus = "in_progress"
+	TaskPaused     TaskStatus = "paused"
+	TaskDone       TaskStatus = "done"
+	TaskArchived   TaskStatus = "archived"
+)
+
+// taskTransitions lists statuses reachable from each task status
+var taskTransitions = map[TaskStatus][]TaskStatus{
+	TaskTodo:       {TaskInProgress, TaskDone},
+	TaskInProgress: {TaskPaused, TaskDone},
+	TaskPaused:     {TaskInProgress, TaskDone},
+	TaskDone:       {TaskArchived, TaskTodo, TaskPaused},
+	TaskArchived:   {TaskTodo, TaskPaused},
+}
+
+// CanTransitionTo reports whether task in status s can be moved to status to
+func (s TaskStatus) CanTransitionTo(to TaskStatus) bool {
+	for _, next := range taskTransitions[s] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// IsClosed reports whether no periods can be tracked for task in status s
+func (s TaskStatus) IsClosed() bool {
+	return s == TaskDone || s == TaskArchived
 }
 
 type Task struct {
-	ID         uint         `json:"id" gorm:"primarykey"`
-	UserID     uint         `json:"user_id" binding:"required" gorm:"index"`
-	TaskName   string       `json:"task_name" binding:"required"`
-	CreatedAt  time.Time    `json:"created_at"`
-	IsFinished bool         `json:"is_finished"`
-	Periods    []TaskPeriod `json:"-" gorm:"constraint:OnDelete:CASCADE;"`
+	ID          uint         `json:"id" gorm:"primarykey"`
+	UserID      uint         `json:"user_id" binding:"required" gorm:"index"`
+	ProjectID   *uint        `json:"project_id" gorm:"index"`
+	TaskName    string       `json:"task_name" binding:"required"`
+	Description string       `json:"description"`
+	CreatedAt   time.Time    `json:"created_at"`
+	Status      TaskStatus   `json:"status" gorm:"default:todo;index"`
+	Estimate    *int64       `json:"estimate_seconds"` // planned time, no estimate if null
+	Tags        []Tag        `json:"tags" gorm:"many2many:task_tags;constraint:OnDelete:CASCADE;"`
+	Periods     []TaskPeriod `json:"-" gorm:"constraint:OnDelete:CASCADE;"`
+	DailyTotals []DailyTotal `json:"-" gorm:"constraint:OnDelete:CASCADE;"`
+	Rates       []Rate       `json:"-" gorm:"constraint:OnDelete:CASCADE;"`
+	Alerts      []TaskAlert  `json:"-" gorm:"constraint:OnDelete:CASCADE;"`
 }
 
-type TaskWithTotalTime struct {
-	Task
-	TotalSeconds    string `json:"-"`
+type Tag struct {
+	ID   uint   `json:"id" gorm:"primarykey"`
+	Name string `json:"name" gorm:"uniqueIndex"`
+}
+
+// Duration is tracked time in whole seconds, as ISO 8601 duration and split
+// into hours, minutes and seconds
+type Duration struct {
+	TotalSeconds    int64  `json:"total_seconds"`
+	Duration        string `json:"duration" example:"PT1H30M"`
 	DurationHours   int    `json:"duration_hours"`
 	DurationMinutes int    `json:"duration_minutes"`
+	DurationSeconds int    `json:"duration_seconds"`
+}
+
+// Money is value of tracked time at hourly rates. Cost counts all time,
+// BillableAmount only billable periods. Time without a rate is worth nothing.
+// Amounts use exact time, not rounded durations, and are rounded to cents
+// per task
+type Money struct {
+	BillableSeconds int64 `json:"billable_seconds"`
+	Cost            Cents `json:"cost" swaggertype:"number"`
+	BillableAmount  Cents `json:"billable_amount" swaggertype:"number"`
+}
+
+type TaskWithTotalTime struct {
+	Task
+	Duration
+	Money
+	EstimateProgress
+}
+
+// EstimateProgress compares all time tracked on a task, not only within the
+// report range, with its estimate. Remaining and overrun are null for tasks
+// without an estimate
+type EstimateProgress struct {
+	TrackedSeconds   int64    `json:"tracked_seconds"`
+	RemainingSeconds *int64   `json:"remaining_seconds"`
+	OverrunSeconds   *int64   `json:"overrun_seconds"`
+	EstimateUsed     *float64 `json:"estimate_used"` // percent of estimate tracked
+}
+
+// TaskAlert records that tracked time of a task crossed a threshold percent
+// of its estimate. Each threshold fires once per estimate
+type TaskAlert struct {
+	ID              uint      `json:"id" gorm:"primarykey"`
+	TaskID          uint      `json:"task_id" gorm:"uniqueIndex:idx_task_alerts_threshold"`
+	UserID          uint      `json:"user_id" gorm:"index"`
+	Threshold       int       `json:"threshold" gorm:"uniqueIndex:idx_task_alerts_threshold"`
+	EstimateSeconds int64     `json:"estimate_seconds"`
+	TrackedSeconds  int64     `json:"tracked_seconds"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// EstimatedTask is an open task with an estimate, its tracked time and the
+// highest threshold already alerted
+type EstimatedTask struct {
+	ID             uint
+	UserID         uint
+	TaskName       string
+	Estimate       int64
+	TrackedSeconds int64
+	Alerted        int
 }
 
 type TaskPeriod struct {
-	ID        uint       `json:"id" gorm:"primarykey"`
-	TaskID    uint       `json:"task_id" gorm:"index"`
-	StartTime *time.Time `json:"start_time"`
-	EndTime   *time.Time `json:"end_time"`
+	ID         uint       `json:"id" gorm:"primarykey"`
+	TaskID     uint       `json:"task_id" gorm:"index"`
+	StartTime  *time.Time `json:"start_time"`
+	EndTime    *time.Time `json:"end_time"`
+	AutoClosed bool       `json:"auto_closed"`
+	Billable   *bool      `json:"billable" gorm:"not null;default:true"`
+	InvoiceID  *uint      `json:"invoice_id" gorm:"index"`            // set once the period is billed
+	ExternalID *string    `json:"external_id,omitempty" gorm:"index"` // source id of imported period
+}
+
+// DailyTotal is time of finished periods of a task within a UTC day.
+// It is maintained by storage whenever periods change
+type DailyTotal struct {
+	Day             time.Time `gorm:"primaryKey"`
+	TaskID          uint      `gorm:"primaryKey;index"`
+	UserID          uint      `gorm:"index"`
+	Seconds         float64
+	BillableSeconds float64
+}
+
+// Rate is hourly rate of a user, project or task effective from a UTC day
+// until the next rate of the same target. Exactly one target is set. Task
+// rate overrides project rate which overrides user rate
+type Rate struct {
+	ID            uint      `json:"id" gorm:"primarykey"`
+	UserID        *uint     `json:"user_id" gorm:"index"`
+	ProjectID     *uint     `json:"project_id" gorm:"index"`
+	TaskID        *uint     `json:"task_id" gorm:"index"`
+	HourlyRate    Cents     `json:"hourly_rate" gorm:"type:numeric(12,2)" swaggertype:"number"`
+	EffectiveFrom time.Time `json:"effective_from"`
+}
+
+// ReportPeriod is a period with its task used to build reports
+type ReportPeriod struct {
+	TaskPeriod
+	TaskName  string `json:"task_name"`
+	UserID    uint   `json:"user_id"`
+	ProjectID *uint  `json:"project_id"`
+}
+
+// RunningPeriod is a period without end with its task and owner
+type RunningPeriod struct {
+	TaskPeriod
+	TaskName       string `json:"task_name"`
+	UserID         uint   `json:"user_id"`
+	Surname        string `json:"surname"`
+	Name           string `json:"name"`
+	WorkdayEnd     string `json:"-"`
+	TimeZone       string `json:"-"`
+	ElapsedSeconds int64  `json:"elapsed_seconds" gorm:"-"`
+}
+
+type TeamRole string
+
+const (
+	TeamMemberRole  TeamRole = "member"
+	TeamManagerRole TeamRole = "manager"
+)
+
+type Team struct {
+	ID      uint         `json:"id" gorm:"primarykey"`
+	Name    string       `json:"name" binding:"required" gorm:"uniqueIndex"`
+	Members []TeamMember `json:"-" gorm:"constraint:OnDelete:CASCADE;"`
+}
+
+type TeamMember struct {
+	TeamID uint     `json:"team_id" gorm:"primaryKey"`
+	UserID uint     `json:"user_id" binding:"required" gorm:"primaryKey;index"`
+	Role   TeamRole `json:"role" gorm:"default:member"`
+}
+
+type TeamMemberInfo struct {
+	User
+	Role TeamRole `json:"role"`
+}
+
+type UserWithTasks struct {
+	User  User                `json:"user"`
+	Tasks []TaskWithTotalTime `json:"tasks"`
+}
+
+type Project struct {
+	ID           uint      `json:"id" gorm:"primarykey"`
+	Name         string    `json:"name" binding:"required" gorm:"uniqueIndex"`
+	Client       string    `json:"client"`
+	Description  string    `json:"description"`
+	BudgetHours  *float64  `json:"budget_hours" gorm:"type:numeric(10,2)"`                       // no hour budget if null
+	BudgetAmount *Cents    `json:"budget_amount" gorm:"type:numeric(12,2)" swaggertype:"number"` // budget of cost, no money budget if null
+	CreatedAt    time.Time `json:"created_at"`
+	Tasks        []Task    `json:"-" gorm:"constraint:OnDelete:SET NULL;"`
+	Rates        []Rate    `json:"-" gorm:"constraint:OnDelete:CASCADE;"`
+}
+
+// ProjectDayTotal is time tracked on a project within a day of a burn-down
+// with its cost. DayIndex is 1-based position of the day
+type ProjectDayTotal struct {
+	DayIndex int
+	Seconds  int64
+	Cost     Cents
+}
+
+// Burndown is cumulative time and cost of a project per local day against
+// its budgets. Cumulative figures include time tracked before the range
+type Burndown struct {
+	ProjectID    uint          `json:"project_id"`
+	BudgetHours  *float64      `json:"budget_hours"`
+	BudgetAmount *Cents        `json:"budget_amount" swaggertype:"number"`
+	TimeZone     string        `json:"time_zone"`
+	Days         []BurndownDay `json:"days"`
+}
+
+type BurndownDay struct {
+	Date              string   `json:"date" example:"2024-07-01"`
+	Seconds           int64    `json:"seconds"`
+	Cost              Cents    `json:"cost" swaggertype:"number"`
+	CumulativeSeconds int64    `json:"cumulative_seconds"`
+	CumulativeHours   float64  `json:"cumulative_hours"`
+	CumulativeCost    Cents    `json:"cumulative_cost" swaggertype:"number"`
+	RemainingHours    *float64 `json:"remaining_hours"`                       // negative once over budget
+	RemainingAmount   *Cents   `json:"remaining_amount" swaggertype:"number"` // negative once over budget
+}
+
+type ProjectWithTotalTime struct {
+	ProjectID   *uint  `json:"project_id"`
+	ProjectName string `json:"project_name"`
+	TasksCount  int    `json:"tasks_count"`
+	Duration
+	Money
+}
+
+type TagWithTotalTime struct {
+	Tag        string `json:"tag"`
+	TasksCount int    `json:"tasks_count"`
+	Duration
+	Money
+}
+
+type GroupBy string
+
+const (
+	GroupByDay   GroupBy = "day"
+	GroupByWeek  GroupBy = "week"
+	GroupByMonth GroupBy = "month"
+)
+
+// SummaryBucket is time worked within [Start, End) split by task
+type SummaryBucket struct {
+	Start time.Time     `json:"start"`
+	End   time.Time     `json:"end"`
+	Tasks []SummaryTask `json:"tasks"`
+	Duration
+}
+
+type SummaryTask struct {
+	TaskID   uint   `json:"task_id"`
+	TaskName string `json:"task_name"`
+	Duration
+}
+
+type ReportGroupBy string
+
+const (
+	ReportByUser    ReportGroupBy = "user"
+	ReportByTask    ReportGroupBy = "task"
+	ReportByProject ReportGroupBy = "project"
+)
+
+// TaskTotal is time worked on a task within a report range
+type TaskTotal struct {
+	TaskID       uint
+	TaskName     string
+	UserID       uint
+	Surname      string
+	Name         string
+	ProjectID    *uint
+	ProjectName  string
+	TotalSeconds int64
+	Money
+}
+
+// TimeReportRow is time worked by a user, on a task or in a project.
+// ID is null for the group of tasks without project
+type TimeReportRow struct {
+	ID         *uint  `json:"id"`
+	Name       string `json:"name"`
+	TasksCount int    `json:"tasks_count"`
+	UsersCount int    `json:"users_count"`
+	Duration
+	Money
+}
+
+// ImportEntry is a finished period from an external source. Task is found
+// by name among open tasks of the user or created
+type ImportEntry struct {
+	UserID     uint      `json:"user_id"`
+	ExternalID string    `json:"external_id"`
+	TaskName   string    `json:"task_name"`
+	ProjectID  *uint     `json:"project_id"`
+	StartTime  time.Time `json:"start_time"`
+	EndTime    time.Time `json:"end_time"`
+	Billable   *bool     `json:"billable,omitempty"` // billable if not set
+}
+
+type ImportResult struct {
+	Imported     int `json:"imported"`
+	Duplicates   int `json:"duplicates"`  // already imported external ids
+	Overlapping  int `json:"overlapping"` // overlap periods of the task
+	Ignored      int `json:"ignored"`     // not matching import rules or invalid
+	Locked       int `json:"locked"`      // within weeks of approved timesheets
+	TasksCreated int `json:"tasks_created"`
+}
+
+type CSVFormat string
+
+const (
+	CSVToggl    CSVFormat = "toggl"
+	CSVClockify CSVFormat = "clockify"
+)
+
+// CSVImportResult is result of time entries import or its preview on dry run
+type CSVImportResult struct {
+	ImportResult
+	Format        CSVFormat     `json:"format"`
+	DryRun        bool          `json:"dry_run"`
+	UnknownPeople []string      `json:"unknown_people"` // emails and passports without user
+	Errors        []string      `json:"errors"`
+	Entries       []ImportEntry `json:"entries,omitempty"` // periods to import, only on dry run
+}
+
+// Timesheet is time worked by a user per task and day within [Start, End)
+type Timesheet struct {
+	User      User           `json:"user"`
+	Start     time.Time      `json:"start"`
+	End       time.Time      `json:"end"`
+	Days      []time.Time    `json:"days"`
+	Rows      []TimesheetRow `json:"rows"`
+	DayTotals []Duration     `json:"day_totals"`
+	Total     Duration       `json:"total"`
+}
+
+type SubmissionStatus string
+
+const (
+	SubmissionSubmitted SubmissionStatus = "submitted"
+	SubmissionApproved  SubmissionStatus = "approved"
+	SubmissionRejected  SubmissionStatus = "rejected"
+)
+
+// TimesheetSubmission is a week of user periods sent for approval. Periods
+// within weeks of approved submissions are locked. Rejected submissions are
+// kept as history and the week can be submitted again
+type TimesheetSubmission struct {
+	ID           uint             `json:"id" gorm:"primarykey"`
+	UserID       uint             `json:"user_id" gorm:"index"`
+	WeekStart    time.Time        `json:"week_start" gorm:"index"`
+	WeekEnd      time.Time        `json:"week_end"`
+	TimeZone     string           `json:"time_zone"`
+	Status       SubmissionStatus `json:"status" gorm:"index"`
+	TotalSeconds int64            `json:"total_seconds"` // time within the week on submission, updated on approval
+	SubmittedAt  time.Time        `json:"submitted_at"`
+	ReviewerID   *uint            `json:"reviewer_id"`
+	ReviewedAt   *time.Time       `json:"reviewed_at"`
+	Comment      string           `json:"comment"`
+}
+
+// Invoice bills billable periods of a project or of all projects of a client
+// started within [From, To). Its periods are marked invoiced so they are
+// not billed twice. Deleting the invoice releases them
+type Invoice struct {
+	ID           uint          `json:"id" gorm:"primarykey"`
+	Number       string        `json:"number" gorm:"uniqueIndex"` // YYYY-NNNN, sequential within a year
+	Client       string        `json:"client"`
+	ProjectID    *uint         `json:"project_id" gorm:"index"`
+	From         time.Time     `json:"from"`
+	To           time.Time     `json:"to"`
+	IssuedAt     time.Time     `json:"issued_at"`
+	TimeZone     string        `json:"time_zone"` // dates of the range are printed in it
+	Currency     string        `json:"currency"`
+	TotalSeconds int64         `json:"total_seconds"`
+	Total        Cents         `json:"total" gorm:"type:numeric(12,2)" swaggertype:"number"`
+	Items        []InvoiceItem `json:"items" gorm:"constraint:OnDelete:CASCADE;"`
+	Periods      []TaskPeriod  `json:"-" gorm:"constraint:OnDelete:SET NULL;"`
+}
+
+// InvoiceItem is billable time of a task at one hourly rate
+type InvoiceItem struct {
+	ID          uint    `json:"-" gorm:"primarykey"`
+	InvoiceID   uint    `json:"-" gorm:"index"`
+	Position    int     `json:"position"`
+	TaskID      uint    `json:"task_id"`
+	Description string  `json:"description"`
+	Seconds     int64   `json:"seconds"`
+	Hours       float64 `json:"hours" gorm:"type:numeric(12,2)"`
+	HourlyRate  Cents   `json:"hourly_rate" gorm:"type:numeric(12,2)" swaggertype:"number"`
+	Amount      Cents   `json:"amount" gorm:"type:numeric(12,2)" swaggertype:"number"`
+}
+
+// InvoiceLine is unbilled billable time of a task at one hourly rate
+type InvoiceLine struct {
+	TaskID      uint
+	TaskName    string
+	Surname     string
+	Name        string
+	ProjectName string
+	HourlyRate  Cents
+	Seconds     int64
+}
+
+type TimesheetRow struct {
+	TaskID   uint       `json:"task_id"`
+	TaskName string     `json:"task_name"`
+	Days     []Duration `json:"days"`
+	Total    Duration   `json:"total"`
 }
@@ -0,0 +1,57 @@
+package models
+
+import "testing"
+
+func TestUserStatusCanTransitionTo(t *testing.T) {
+	tests := []struct {
+		from     UserStatus
+		to       UserStatus
+		expected bool
+	}{
+		{UserActive, UserSuspended, true},
+		{UserActive, UserTerminated, true},
+		{UserActive, UserActive, false},
+		{UserSuspended, UserActive, true},
+		{UserSuspended, UserTerminated, true},
+		{UserSuspended, UserSuspended, false},
+		{UserTerminated, UserActive, false}, // termination is final
+		{UserTerminated, UserSuspended, false},
+		{UserTerminated, UserTerminated, false},
+	}
+
+	for _, test := range tests {
+		result := test.from.CanTransitionTo(test.to)
+		if result != test.expected {
+			t.Errorf("%q.CanTransitionTo(%q) = %v; expected %v", test.from, test.to, result, test.expected)
+		}
+	}
+}
+
+func TestTaskStatusCanTransitionTo(t *testing.T) {
+	tests := []struct {
+		from     TaskStatus
+		to       TaskStatus
+		expected bool
+	}{
+		{TaskTodo, TaskInProgress, true},
+		{TaskTodo, TaskDone, true},
+		{TaskTodo, TaskPaused, false},
+		{TaskInProgress, TaskPaused, true},
+		{TaskInProgress, TaskDone, true},
+		{TaskInProgress, TaskInProgress, false},
+		{TaskPaused, TaskInProgress, true},
+		{TaskDone, TaskInProgress, false}, // no periods on done tasks
+		{TaskDone, TaskArchived, true},
+		{TaskDone, TaskPaused, true},
+		{TaskArchived, TaskInProgress, false},
+		{TaskArchived, TaskTodo, true},
+		{TaskArchived, TaskDone, false},
+	}
+
+	for _, test := range tests {
+		result := test.from.CanTransitionTo(test.to)
+		if result != test.expected {
+			t.Errorf("%q.CanTransitionTo(%q) = %v; expected %v", test.from, test.to, result, test.expected)
+		}
+	}
+}
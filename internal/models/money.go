@@ -0,0 +1,91 @@
+package models
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+)
+
+var ErrInvalidAmount = errors.New("invalid amount")
+
+// Cents is an amount of money in hundredths of the currency unit, so sums of
+// amounts are exact. It is written as a decimal number to JSON and numeric
+// columns
+type Cents int64
+
+// ParseCents parses a decimal amount rounding it half away from zero to cents
+func ParseCents(s string) (Cents, error) {
+	amount, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return 0, ErrInvalidAmount
+	}
+	amount.Mul(amount, big.NewRat(100, 1))
+
+	cents, rest := new(big.Int).QuoRem(amount.Num(), amount.Denom(), new(big.Int))
+	if rest.Abs(rest).Lsh(rest, 1).Cmp(amount.Denom()) >= 0 {
+		cents.Add(cents, big.NewInt(int64(amount.Sign())))
+	}
+	if !cents.IsInt64() {
+		return 0, ErrInvalidAmount
+	}
+	return Cents(cents.Int64()), nil
+}
+
+// CentsOf rounds amount to cents
+func CentsOf(amount float64) Cents {
+	return Cents(math.Round(amount * 100))
+}
+
+// String formats the amount with two decimals
+func (c Cents) String() string {
+	sign, value := "", int64(c)
+	if value < 0 {
+		sign, value = "-", -value
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, value/100, value%100)
+}
+
+func (c Cents) MarshalJSON() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+// UnmarshalJSON accepts JSON numbers only
+func (c *Cents) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	if len(data) == 0 || data[0] == '"' {
+		return ErrInvalidAmount
+	}
+	cents, err := ParseCents(string(data))
+	if err != nil {
+		return err
+	}
+	*c = cents
+	return nil
+}
+
+func (c Cents) Value() (driver.Value, error) {
+	return c.String(), nil
+}
+
+func (c *Cents) Scan(src any) error {
+	var err error
+	switch v := src.(type) {
+	case nil:
+		*c = 0
+	case int64:
+		*c = Cents(v * 100)
+	case float64:
+		*c = CentsOf(v)
+	case []byte:
+		*c, err = ParseCents(string(v))
+	case string:
+		*c, err = ParseCents(v)
+	default:
+		err = fmt.Errorf("can not scan %T into cents", src)
+	}
+	return err
+}
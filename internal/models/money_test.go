@@ -0,0 +1,93 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestParseCents(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Cents
+		err      error
+	}{
+		{"12", 1200, nil},
+		{"12.5", 1250, nil},
+		{"0.1", 10, nil},
+		{"0.005", 1, nil}, // half away from zero
+		{"-0.005", -1, nil},
+		{"0.0049", 0, nil},
+		{"1e2", 10000, nil},
+		{"abc", 0, ErrInvalidAmount},
+		{"1e30", 0, ErrInvalidAmount},
+	}
+
+	for _, test := range tests {
+		result, err := ParseCents(test.input)
+		if result != test.expected || !errors.Is(err, test.err) {
+			t.Errorf("ParseCents(%q) = %d, %v; expected %d, %v", test.input, result, err, test.expected, test.err)
+		}
+	}
+}
+
+func TestCentsString(t *testing.T) {
+	tests := []struct {
+		cents    Cents
+		expected string
+	}{
+		{0, "0.00"},
+		{5, "0.05"},
+		{1234, "12.34"},
+		{-5, "-0.05"},
+		{-1234, "-12.34"},
+	}
+
+	for _, test := range tests {
+		if result := test.cents.String(); result != test.expected {
+			t.Errorf("Cents(%d).String() = %q; expected %q", int64(test.cents), result, test.expected)
+		}
+	}
+}
+
+func TestCentsJSON(t *testing.T) {
+	var v struct {
+		Amount Cents  `json:"amount"`
+		Budget *Cents `json:"budget"`
+	}
+	if err := json.Unmarshal([]byte(`{"amount": 0.3, "budget": 100.01}`), &v); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if v.Amount != 30 || v.Budget == nil || *v.Budget != 10001 {
+		t.Errorf("amounts = %d, %v; expected 30, 10001", v.Amount, v.Budget)
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil || string(data) != `{"amount":0.30,"budget":100.01}` {
+		t.Errorf("Marshal = %s, %v", data, err)
+	}
+
+	if err := json.Unmarshal([]byte(`{"amount": "0.3"}`), &v); err == nil {
+		t.Errorf("string amount is accepted")
+	}
+}
+
+func TestCentsScan(t *testing.T) {
+	tests := []struct {
+		src      any
+		expected Cents
+	}{
+		{nil, 0},
+		{int64(3), 300},
+		{0.1 + 0.2, 30},
+		{[]byte("1234.56"), 123456},
+		{"-0.01", -1},
+	}
+
+	for _, test := range tests {
+		var c Cents
+		if err := c.Scan(test.src); err != nil || c != test.expected {
+			t.Errorf("Scan(%v) = %d, %v; expected %d", test.src, c, err, test.expected)
+		}
+	}
+}
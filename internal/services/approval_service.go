@@ -0,0 +1,98 @@
+package services
+
+import (
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/moxicom/user_test/internal/models"
+	"github.com/moxicom/user_test/internal/storage"
+)
+
+type ApprovalService struct {
+	s   storage.Storage
+	log *slog.Logger
+}
+
+func newApprovalService(s storage.Storage, log *slog.Logger) *ApprovalService {
+	return &ApprovalService{s, log}
+}
+
+// SubmitTimesheet sends the finished week containing day for approval. Week
+// starts on local Monday midnight in loc
+func (s *ApprovalService) SubmitTimesheet(userID uint, day time.Time, loc *time.Location) (uint, error) {
+	start, end, err := submissionWeek(day, loc, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := s.s.GetUser(userID); err != nil {
+		return 0, err
+	}
+
+	return s.s.SubmitTimesheet(models.TimesheetSubmission{
+		UserID:      userID,
+		WeekStart:   start,
+		WeekEnd:     end,
+		TimeZone:    loc.String(),
+		Status:      models.SubmissionSubmitted,
+		SubmittedAt: time.Now(),
+	})
+}
+
+func (s *ApprovalService) GetSubmissions(filters models.SubmissionFilters) ([]models.TimesheetSubmission, error) {
+	switch filters.Status {
+	case "", models.SubmissionSubmitted, models.SubmissionApproved, models.SubmissionRejected:
+	default:
+		return nil, ErrInvalidSubmissionStatus
+	}
+	return s.s.GetSubmissions(filters)
+}
+
+// ApproveTimesheet approves submitted timesheet locking its periods
+func (s *ApprovalService) ApproveTimesheet(submissionID uint, reviewerID uint, comment string) error {
+	return s.review(submissionID, models.SubmissionApproved, reviewerID, comment)
+}
+
+// RejectTimesheet returns submitted timesheet to the user. Comment is required
+func (s *ApprovalService) RejectTimesheet(submissionID uint, reviewerID uint, comment string) error {
+	if strings.TrimSpace(comment) == "" {
+		return ErrEmptyComment
+	}
+	return s.review(submissionID, models.SubmissionRejected, reviewerID, comment)
+}
+
+// review checks that reviewer manages the owner of the submission
+func (s *ApprovalService) review(submissionID uint, status models.SubmissionStatus, reviewerID uint, comment string) error {
+	sub, err := s.s.GetSubmission(submissionID)
+	if err != nil {
+		return err
+	}
+
+	managed, err := s.s.GetManagedUsers(reviewerID)
+	if err != nil {
+		return err
+	}
+
+	for _, user := range managed {
+		if user.ID == sub.UserID {
+			return s.s.ReviewSubmission(submissionID, status, reviewerID, strings.TrimSpace(comment), time.Now())
+		}
+	}
+
+	s.log.Warn("reviewer does not manage the user",
+		slog.Uint64("reviewer_id", uint64(reviewerID)),
+		slog.Uint64("user_id", uint64(sub.UserID)))
+	return ErrNotManager
+}
+
+// submissionWeek returns bounds of the week containing day. The week should
+// be over by now
+func submissionWeek(day time.Time, loc *time.Location, now time.Time) (time.Time, time.Time, error) {
+	start := bucketStart(day, models.GroupByWeek, loc)
+	end := nextBucket(start, models.GroupByWeek)
+	if end.After(now) {
+		return time.Time{}, time.Time{}, ErrWeekNotOver
+	}
+	return start, end, nil
+}
@@ -0,0 +1,36 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSubmissionWeek(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Moscow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	day := time.Date(2024, 7, 4, 15, 0, 0, 0, loc)
+	now := time.Date(2024, 7, 10, 0, 0, 0, 0, loc)
+
+	start, end, err := submissionWeek(day, loc, now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := time.Date(2024, 7, 1, 0, 0, 0, 0, loc); !start.Equal(expected) {
+		t.Errorf("start = %v; expected %v", start, expected)
+	}
+	if expected := time.Date(2024, 7, 8, 0, 0, 0, 0, loc); !end.Equal(expected) {
+		t.Errorf("end = %v; expected %v", end, expected)
+	}
+}
+
+func TestSubmissionWeekNotOver(t *testing.T) {
+	day := time.Date(2024, 7, 4, 15, 0, 0, 0, time.UTC)
+	now := time.Date(2024, 7, 7, 23, 59, 0, 0, time.UTC)
+
+	if _, _, err := submissionWeek(day, time.UTC, now); !errors.Is(err, ErrWeekNotOver) {
+		t.Errorf("err = %v; expected %v", err, ErrWeekNotOver)
+	}
+}
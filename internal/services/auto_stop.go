@@ -0,0 +1,110 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/moxicom/user_test/internal/models"
+	"github.com/moxicom/user_test/internal/storage"
+)
+
+type AutoStopConfig struct {
+	MaxPeriod time.Duration // zero disables the limit
+	Interval  time.Duration
+}
+
+// AutoStop periodically closes running periods which exceed the maximum
+// duration or outlast the workday of their user
+type AutoStop struct {
+	s   storage.Storage
+	log *slog.Logger
+	cfg AutoStopConfig
+}
+
+func NewAutoStop(s storage.Storage, log *slog.Logger, cfg AutoStopConfig) *AutoStop {
+	return &AutoStop{s, log, cfg}
+}
+
+func (a *AutoStop) Run(ctx context.Context) {
+	ticker := time.NewTicker(a.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			a.closeRunaway(time.Now())
+		}
+	}
+}
+
+func (a *AutoStop) closeRunaway(now time.Time) {
+	log := a.log.With(slog.String("op", "AutoStop.closeRunaway"))
+
+	periods, err := a.s.GetRunningPeriods(0)
+	if err != nil {
+		log.Error("failed to get running periods", slog.Any("err", err))
+		return
+	}
+
+	closed := 0
+	for _, period := range periods {
+		loc, err := loadLocation(period.TimeZone)
+		if err != nil {
+			loc = time.Local
+		}
+		endTime, ok := autoStopTime(*period.StartTime, now, a.cfg.MaxPeriod, period.WorkdayEnd, loc)
+		if !ok {
+			continue
+		}
+		err = a.s.AutoClosePeriod(period.ID, endTime)
+		if errors.Is(err, storage.ErrPeriodLocked) {
+			log.Warn("period within an approved timesheet is not auto-closed", slog.Uint64("period_id", uint64(period.ID)))
+			continue
+		}
+		if err != nil {
+			log.Error("failed to auto-close period", slog.Uint64("period_id", uint64(period.ID)), slog.Any("err", err))
+			continue
+		}
+		closed++
+	}
+
+	if closed > 0 {
+		log.Info("Runaway periods auto-closed", slog.Int("count", closed))
+	}
+}
+
+// autoStopTime returns when a period started at start should have been closed:
+// after maxPeriod or at the first workday end in loc after start, whichever is earlier.
+// It reports false if the period may still run at now
+func autoStopTime(start, now time.Time, maxPeriod time.Duration, workdayEnd string, loc *time.Location) (time.Time, bool) {
+	var stop time.Time
+	found := false
+
+	if maxPeriod > 0 {
+		stop = start.Add(maxPeriod)
+		found = true
+	}
+
+	if workdayEnd != "" {
+		if end, err := time.Parse(models.WorkdayEndLayout, workdayEnd); err == nil {
+			local := start.In(loc)
+			dayEnd := time.Date(local.Year(), local.Month(), local.Day(), end.Hour(), end.Minute(), 0, 0, loc)
+			if !dayEnd.After(local) {
+				dayEnd = dayEnd.AddDate(0, 0, 1)
+			}
+			if !found || dayEnd.Before(stop) {
+				stop = dayEnd
+				found = true
+			}
+		}
+	}
+
+	if !found || stop.After(now) {
+		return time.Time{}, false
+	}
+	return stop, true
+}
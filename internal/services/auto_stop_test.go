@@ -0,0 +1,36 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAutoStopTime(t *testing.T) {
+	start := time.Date(2024, 7, 1, 9, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		name       string
+		now        time.Time
+		maxPeriod  time.Duration
+		workdayEnd string
+		expected   time.Time
+		ok         bool
+	}{
+		{"no limits", start.Add(48 * time.Hour), 0, "", time.Time{}, false},
+		{"max not reached", start.Add(time.Hour), 8 * time.Hour, "", time.Time{}, false},
+		{"max exceeded", start.Add(10 * time.Hour), 8 * time.Hour, "", start.Add(8 * time.Hour), true},
+		{"workday not ended", start.Add(time.Hour), 0, "18:00", time.Time{}, false},
+		{"workday ended", start.Add(10 * time.Hour), 0, "18:00", start.Add(9 * time.Hour), true},
+		{"workday ends before max", start.Add(20 * time.Hour), 12 * time.Hour, "18:00", start.Add(9 * time.Hour), true},
+		{"max before workday end", start.Add(20 * time.Hour), 2 * time.Hour, "18:00", start.Add(2 * time.Hour), true},
+		{"started after workday end", start.Add(24 * time.Hour), 0, "08:00", start.Add(23 * time.Hour), true},
+		{"invalid workday end", start.Add(20 * time.Hour), 0, "8pm", time.Time{}, false},
+	}
+
+	for _, test := range tests {
+		result, ok := autoStopTime(start, test.now, test.maxPeriod, test.workdayEnd, time.Local)
+		if ok != test.ok || !result.Equal(test.expected) {
+			t.Errorf("%s: autoStopTime() = %v, %v; expected %v, %v", test.name, result, ok, test.expected, test.ok)
+		}
+	}
+}
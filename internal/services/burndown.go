@@ -0,0 +1,94 @@
+package services
+
+import (
+	"time"
+
+	"github.com/moxicom/user_test/internal/models"
+)
+
+// GetProjectBurndown returns cumulative time and cost of the project per day
+// of [from, to) in loc against its budgets. Range starts on the project
+// creation day and ends today by default (zero from or to)
+func (s *ProjectService) GetProjectBurndown(projectID uint, from, to time.Time, loc *time.Location) (models.Burndown, error) {
+	project, err := s.s.GetProject(projectID)
+	if err != nil {
+		return models.Burndown{}, err
+	}
+
+	if from.IsZero() {
+		from = project.CreatedAt
+	}
+	if to.IsZero() {
+		to = nextBucket(bucketStart(time.Now(), models.GroupByDay, loc), models.GroupByDay)
+	}
+
+	bounds, err := burndownBounds(from, to, loc)
+	if err != nil {
+		return models.Burndown{}, err
+	}
+
+	before, days, err := s.s.GetProjectDays(projectID, bounds)
+	if err != nil {
+		return models.Burndown{}, err
+	}
+
+	return models.Burndown{
+		ProjectID:    project.ID,
+		BudgetHours:  project.BudgetHours,
+		BudgetAmount: project.BudgetAmount,
+		TimeZone:     loc.String(),
+		Days:         burndownDays(bounds, before, days, project.BudgetHours, project.BudgetAmount),
+	}, nil
+}
+
+// burndownBounds returns local midnights from the day of from until the
+// first one not before to. Ranges are limited like summaries, so bounds fit
+// in one query
+func burndownBounds(from, to time.Time, loc *time.Location) ([]time.Time, error) {
+	if err := checkRange(from, to); err != nil {
+		return nil, err
+	}
+
+	bounds := []time.Time{bucketStart(from, models.GroupByDay, loc)}
+	for bounds[len(bounds)-1].Before(to) {
+		bounds = append(bounds, nextBucket(bounds[len(bounds)-1], models.GroupByDay))
+	}
+	return bounds, nil
+}
+
+// burndownDays accumulates day totals starting with time tracked before the
+// range. Days missing from totals have no time
+func burndownDays(bounds []time.Time, before models.ProjectDayTotal, totals []models.ProjectDayTotal,
+	budgetHours *float64, budgetAmount *models.Cents) []models.BurndownDay {
+	byIndex := make(map[int]models.ProjectDayTotal, len(totals))
+	for _, t := range totals {
+		byIndex[t.DayIndex] = t
+	}
+
+	seconds, cost := before.Seconds, before.Cost
+	days := make([]models.BurndownDay, 0, len(bounds)-1)
+	for i, start := range bounds[:len(bounds)-1] {
+		t := byIndex[i+1]
+		seconds += t.Seconds
+		cost += t.Cost
+
+		day := models.BurndownDay{
+			Date:              start.Format("2006-01-02"),
+			Seconds:           t.Seconds,
+			Cost:              t.Cost,
+			CumulativeSeconds: seconds,
+			CumulativeHours:   roundCents(float64(seconds) / 3600),
+			CumulativeCost:    cost,
+		}
+		if budgetHours != nil {
+			remaining := roundCents(*budgetHours - float64(seconds)/3600)
+			day.RemainingHours = &remaining
+		}
+		if budgetAmount != nil {
+			remaining := *budgetAmount - cost
+			day.RemainingAmount = &remaining
+		}
+		days = append(days, day)
+	}
+	return days
+}
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/moxicom/user_test/internal/models"
+)
+
+func TestBurndownBounds(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// DST starts on 2024-03-31
+	from := time.Date(2024, 3, 30, 15, 0, 0, 0, loc)
+	to := time.Date(2024, 4, 1, 0, 0, 0, 0, loc)
+
+	bounds, err := burndownBounds(from, to, loc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []time.Time{
+		time.Date(2024, 3, 30, 0, 0, 0, 0, loc),
+		time.Date(2024, 3, 31, 0, 0, 0, 0, loc),
+		time.Date(2024, 4, 1, 0, 0, 0, 0, loc),
+	}
+	if len(bounds) != len(expected) {
+		t.Fatalf("bounds = %v; expected %v", bounds, expected)
+	}
+	for i := range expected {
+		if !bounds[i].Equal(expected[i]) {
+			t.Errorf("bounds[%d] = %v; expected %v", i, bounds[i], expected[i])
+		}
+	}
+
+	if _, err := burndownBounds(to, from, loc); !errors.Is(err, ErrInvalidRange) {
+		t.Errorf("err = %v; expected %v", err, ErrInvalidRange)
+	}
+	if _, err := burndownBounds(from, from.AddDate(20, 0, 0), loc); !errors.Is(err, ErrRangeTooLong) {
+		t.Errorf("err = %v; expected %v", err, ErrRangeTooLong)
+	}
+}
+
+func TestBurndownDays(t *testing.T) {
+	start := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+	bounds := []time.Time{start, start.AddDate(0, 0, 1), start.AddDate(0, 0, 2), start.AddDate(0, 0, 3)}
+	before := models.ProjectDayTotal{Seconds: 7200, Cost: 10000}
+	totals := []models.ProjectDayTotal{
+		{DayIndex: 1, Seconds: 3600, Cost: 5010},
+		{DayIndex: 3, Seconds: 5400, Cost: 7520},
+	}
+	budgetHours, budgetAmount := 4.0, models.Cents(20000)
+
+	days := burndownDays(bounds, before, totals, &budgetHours, &budgetAmount)
+
+	if len(days) != 3 {
+		t.Fatalf("days = %d; expected 3", len(days))
+	}
+	if d := days[0]; d.Date != "2024-07-01" || d.CumulativeSeconds != 10800 || d.CumulativeCost != 15010 || *d.RemainingHours != 1 || *d.RemainingAmount != 4990 {
+		t.Errorf("first day = %+v", d)
+	}
+	if d := days[1]; d.Seconds != 0 || d.CumulativeSeconds != 10800 || d.CumulativeHours != 3 {
+		t.Errorf("empty day = %+v", d)
+	}
+	if d := days[2]; d.CumulativeSeconds != 16200 || d.CumulativeCost != 22530 || *d.RemainingHours != -0.5 || *d.RemainingAmount != -2530 {
+		t.Errorf("over budget day = %+v", d)
+	}
+
+	if days := burndownDays(bounds, before, totals, nil, nil); days[0].RemainingHours != nil || days[0].RemainingAmount != nil {
+		t.Errorf("remaining without budgets = %+v", days[0])
+	}
+}
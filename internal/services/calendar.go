@@ -0,0 +1,130 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/moxicom/user_test/internal/models"
+)
+
+// calendarFeedPeriod is how far back the subscribable feed reaches
+const calendarFeedPeriod = 90 * 24 * time.Hour
+
+const icsTimeLayout = "20060102T150405Z"
+
+// GetUserCalendar renders finished periods of the user within [from, to] as iCalendar
+func (s *UserService) GetUserCalendar(userID uint, from, to time.Time) ([]byte, error) {
+	if !from.Before(to) {
+		return nil, ErrInvalidRange
+	}
+
+	user, err := s.s.GetUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	periods, err := s.s.GetUserPeriods(userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return renderCalendar(user, periods, time.Now()), nil
+}
+
+// CreateCalendarToken issues a new calendar feed token of the user. Previous
+// token stops working
+func (s *UserService) CreateCalendarToken(userID uint) (string, error) {
+	if _, err := s.s.GetUser(userID); err != nil {
+		return "", err
+	}
+
+	b := make([]byte, 20)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(b)
+
+	if err := s.s.SetCalendarToken(userID, token); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// GetCalendarFeed renders periods of the last calendarFeedPeriod of the token owner
+func (s *UserService) GetCalendarFeed(token string) ([]byte, error) {
+	user, err := s.s.GetUserByCalendarToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	periods, err := s.s.GetUserPeriods(user.ID, now.Add(-calendarFeedPeriod), now)
+	if err != nil {
+		return nil, err
+	}
+
+	return renderCalendar(user, periods, now), nil
+}
+
+// renderCalendar renders finished periods as VEVENTs of an RFC 5545 calendar
+func renderCalendar(user models.User, periods []models.ReportPeriod, now time.Time) []byte {
+	userName := strings.TrimSpace(user.Surname + " " + user.Name)
+	stamp := now.UTC().Format(icsTimeLayout)
+
+	var b strings.Builder
+	writeLine := func(name, value string) {
+		b.WriteString(foldLine(name + ":" + value))
+		b.WriteString("\r\n")
+	}
+
+	writeLine("BEGIN", "VCALENDAR")
+	writeLine("VERSION", "2.0")
+	writeLine("PRODID", "-//user_test//time tracker//EN")
+	writeLine("CALSCALE", "GREGORIAN")
+	writeLine("X-WR-CALNAME", escapeText(userName+" time"))
+	for _, p := range periods {
+		if p.EndTime == nil {
+			continue
+		}
+		writeLine("BEGIN", "VEVENT")
+		writeLine("UID", fmt.Sprintf("period-%d@user_test", p.ID))
+		writeLine("DTSTAMP", stamp)
+		writeLine("DTSTART", p.StartTime.UTC().Format(icsTimeLayout))
+		writeLine("DTEND", p.EndTime.UTC().Format(icsTimeLayout))
+		writeLine("SUMMARY", escapeText(p.TaskName))
+		writeLine("DESCRIPTION", escapeText(fmt.Sprintf("User: %s\nTask ID: %d", userName, p.TaskID)))
+		writeLine("END", "VEVENT")
+	}
+	writeLine("END", "VCALENDAR")
+
+	return []byte(b.String())
+}
+
+// escapeText escapes iCalendar TEXT value
+func escapeText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+// foldLine splits content line into lines of at most 75 octets without
+// breaking UTF-8 characters. Continuation lines start with a space
+func foldLine(line string) string {
+	const limit = 75
+
+	var b strings.Builder
+	width := 0
+	for _, r := range line {
+		size := utf8.RuneLen(r)
+		if width+size > limit {
+			b.WriteString("\r\n ")
+			width = 1
+		}
+		b.WriteRune(r)
+		width += size
+	}
+	return b.String()
+}
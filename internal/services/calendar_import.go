@@ -0,0 +1,331 @@
+package services
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/moxicom/user_test/internal/models"
+)
+
+type calendarEvent struct {
+	UID        string
+	Summary    string
+	Categories []string
+	Start      time.Time
+	End        time.Time
+	AllDay     bool
+	Recurring  bool // has recurrence rules or dates or is an instance of a series
+}
+
+// ImportCalendar creates tasks and periods of the user from events of the
+// calendars matching rules. Events already imported by UID, all-day, future
+// and malformed events are skipped. Recurring events are skipped as well, they
+// are not expanded to occurrences. Times without zone are read in loc
+func (s *UserService) ImportCalendar(userID uint, calendars []io.Reader, rules models.CalendarImportRules, loc *time.Location) (models.ImportResult, error) {
+	if _, err := s.s.GetUser(userID); err != nil {
+		return models.ImportResult{}, err
+	}
+
+	now := time.Now()
+	entries := make([]models.ImportEntry, 0)
+	ignored := 0
+	for _, r := range calendars {
+		events, err := parseCalendar(r, loc)
+		if err != nil {
+			return models.ImportResult{}, err
+		}
+
+		for _, e := range events {
+			entry, ok := calendarEntry(e, rules, now)
+			if !ok {
+				ignored++
+				continue
+			}
+			entry.UserID = userID
+			entries = append(entries, entry)
+		}
+	}
+
+	result, err := s.s.ImportPeriods(entries, false)
+	if err != nil {
+		return models.ImportResult{}, err
+	}
+	result.Ignored += ignored
+
+	return result, nil
+}
+
+// calendarEntry converts event matching rules to import entry
+func calendarEntry(e calendarEvent, rules models.CalendarImportRules, now time.Time) (models.ImportEntry, bool) {
+	if e.AllDay || e.Recurring || e.Start.IsZero() || !e.Start.Before(e.End) || e.End.After(now) {
+		return models.ImportEntry{}, false
+	}
+
+	if rules.Category != "" {
+		found := false
+		for _, c := range e.Categories {
+			if strings.EqualFold(c, rules.Category) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return models.ImportEntry{}, false
+		}
+	}
+
+	name := e.Summary
+	if rules.Prefix != "" {
+		if !strings.HasPrefix(name, rules.Prefix) {
+			return models.ImportEntry{}, false
+		}
+		name = strings.TrimPrefix(name, rules.Prefix)
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return models.ImportEntry{}, false
+	}
+
+	externalID := ""
+	if e.UID != "" {
+		externalID = "ics:" + e.UID
+	}
+
+	return models.ImportEntry{
+		ExternalID: externalID,
+		TaskName:   name,
+		ProjectID:  rules.ProjectID,
+		StartTime:  e.Start,
+		EndTime:    e.End,
+	}, true
+}
+
+// parseCalendar reads events of an RFC 5545 calendar. Events with
+// unparsable times are returned with zero start
+func parseCalendar(r io.Reader, loc *time.Location) ([]calendarEvent, error) {
+	lines, err := unfoldLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]calendarEvent, 0)
+	var components []string
+	var event calendarEvent
+	var duration time.Duration
+	hasDuration, seenCalendar := false, false
+	for _, line := range lines {
+		name, params, value, ok := parseContentLine(line)
+		if !ok {
+			continue
+		}
+
+		switch name {
+		case "BEGIN":
+			components = append(components, strings.ToUpper(value))
+			if strings.EqualFold(value, "VCALENDAR") {
+				seenCalendar = true
+			}
+			if strings.EqualFold(value, "VEVENT") {
+				event, duration, hasDuration = calendarEvent{}, 0, false
+			}
+			continue
+		case "END":
+			if len(components) > 0 {
+				components = components[:len(components)-1]
+			}
+			if strings.EqualFold(value, "VEVENT") {
+				if event.End.IsZero() && hasDuration && !event.Start.IsZero() {
+					event.End = event.Start.Add(duration)
+				}
+				events = append(events, event)
+			}
+			continue
+		}
+
+		if len(components) == 0 || components[len(components)-1] != "VEVENT" {
+			continue
+		}
+
+		switch name {
+		case "UID":
+			event.UID = value
+		case "SUMMARY":
+			event.Summary = unescapeText(value)
+		case "CATEGORIES":
+			for _, c := range splitText(value) {
+				event.Categories = append(event.Categories, strings.TrimSpace(c))
+			}
+		case "DTSTART":
+			event.Start, event.AllDay = parseCalendarTime(value, params, loc)
+		case "DTEND":
+			event.End, _ = parseCalendarTime(value, params, loc)
+		case "DURATION":
+			if d, err := parseCalendarDuration(value); err == nil {
+				duration, hasDuration = d, true
+			}
+		case "RRULE", "RDATE", "EXDATE", "RECURRENCE-ID":
+			event.Recurring = true
+		}
+	}
+
+	if !seenCalendar {
+		return nil, ErrInvalidCalendar
+	}
+
+	return events, nil
+}
+
+// unfoldLines joins continuation lines starting with space or tab
+func unfoldLines(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	lines := make([]string, 0)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	return lines, scanner.Err()
+}
+
+// parseContentLine splits "NAME;PARAM=VALUE:value" skipping quoted parameter values
+func parseContentLine(line string) (string, map[string]string, string, bool) {
+	inQuotes := false
+	colon := -1
+	for i, r := range line {
+		if r == '"' {
+			inQuotes = !inQuotes
+		}
+		if r == ':' && !inQuotes {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return "", nil, "", false
+	}
+
+	parts := strings.Split(line[:colon], ";")
+	params := make(map[string]string, len(parts)-1)
+	for _, p := range parts[1:] {
+		if k, v, ok := strings.Cut(p, "="); ok {
+			params[strings.ToUpper(k)] = strings.Trim(v, `"`)
+		}
+	}
+
+	return strings.ToUpper(parts[0]), params, line[colon+1:], true
+}
+
+// parseCalendarTime parses DATE or DATE-TIME value. Second result reports DATE
+func parseCalendarTime(value string, params map[string]string, loc *time.Location) (time.Time, bool) {
+	if params["VALUE"] == "DATE" || len(value) == len("20060102") {
+		t, err := time.ParseInLocation("20060102", value, loc)
+		if err != nil {
+			return time.Time{}, true
+		}
+		return t, true
+	}
+
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse(icsTimeLayout, value)
+		if err != nil {
+			return time.Time{}, false
+		}
+		return t, false
+	}
+
+	if tzid := params["TZID"]; tzid != "" {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			loc = l
+		}
+	}
+	t, err := time.ParseInLocation("20060102T150405", value, loc)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, false
+}
+
+// parseCalendarDuration parses positive RFC 5545 duration like P1DT2H30M or P1W
+func parseCalendarDuration(value string) (time.Duration, error) {
+	s := strings.TrimPrefix(value, "+")
+	if !strings.HasPrefix(s, "P") {
+		return 0, strconv.ErrSyntax
+	}
+	s = s[1:]
+
+	units := map[byte]time.Duration{'W': 7 * 24 * time.Hour, 'D': 24 * time.Hour}
+	timeUnits := map[byte]time.Duration{'H': time.Hour, 'M': time.Minute, 'S': time.Second}
+
+	var d time.Duration
+	number := ""
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c >= '0' && c <= '9':
+			number += string(c)
+		case c == 'T':
+			units = timeUnits
+		default:
+			unit, ok := units[c]
+			if !ok || number == "" {
+				return 0, strconv.ErrSyntax
+			}
+			n, err := strconv.Atoi(number)
+			if err != nil {
+				return 0, err
+			}
+			d += time.Duration(n) * unit
+			number = ""
+		}
+	}
+	if number != "" {
+		return 0, strconv.ErrSyntax
+	}
+
+	return d, nil
+}
+
+// unescapeText reverses escapeText
+func unescapeText(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+			switch s[i] {
+			case 'n', 'N':
+				b.WriteByte('\n')
+			default:
+				b.WriteByte(s[i])
+			}
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// splitText splits list of TEXT values at unescaped commas
+func splitText(s string) []string {
+	values := make([]string, 0)
+	start := 0
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' {
+			i++
+			continue
+		}
+		if s[i] == ',' {
+			values = append(values, unescapeText(s[start:i]))
+			start = i + 1
+		}
+	}
+	return append(values, unescapeText(s[start:]))
+}
@@ -0,0 +1,136 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/moxicom/user_test/internal/models"
+)
+
+const testCalendar = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:1@example.com\r\n" +
+	"SUMMARY:[work] Review\\, part 1\r\n" +
+	"CATEGORIES:Work,Meeting\r\n" +
+	"DTSTART:20240701T090000Z\r\n" +
+	"DTEND:20240701T103000Z\r\n" +
+	"BEGIN:VALARM\r\n" +
+	"SUMMARY:reminder\r\n" +
+	"END:VALARM\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:2@example.com\r\n" +
+	"SUMMARY:Long\r\n" +
+	"  title\r\n" +
+	"DTSTART;TZID=\"Europe/Moscow\":20240702T120000\r\n" +
+	"DURATION:PT1H15M\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:3@example.com\r\n" +
+	"SUMMARY:Holiday\r\n" +
+	"DTSTART;VALUE=DATE:20240703\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:4@example.com\r\n" +
+	"SUMMARY:Standup\r\n" +
+	"DTSTART:20240701T080000Z\r\n" +
+	"DTEND:20240701T081500Z\r\n" +
+	"RRULE:FREQ=DAILY;COUNT=5\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestParseCalendar(t *testing.T) {
+	events, err := parseCalendar(strings.NewReader(testCalendar), time.UTC)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(events) != 4 {
+		t.Fatalf("expected 4 events, got %d", len(events))
+	}
+
+	if events[0].Summary != "[work] Review, part 1" || len(events[0].Categories) != 2 {
+		t.Errorf("first event = %+v", events[0])
+	}
+	if !events[0].End.Equal(time.Date(2024, 7, 1, 10, 30, 0, 0, time.UTC)) {
+		t.Errorf("first event end = %v", events[0].End)
+	}
+
+	if events[1].Summary != "Long title" {
+		t.Errorf("second event summary = %q; expected unfolded %q", events[1].Summary, "Long title")
+	}
+	if start := time.Date(2024, 7, 2, 9, 0, 0, 0, time.UTC); !events[1].Start.Equal(start) || !events[1].End.Equal(start.Add(75*time.Minute)) {
+		t.Errorf("second event = %v - %v; expected start %v in TZID zone and end after duration", events[1].Start, events[1].End, start)
+	}
+
+	if !events[2].AllDay {
+		t.Errorf("third event should be all-day")
+	}
+
+	if events[0].Recurring || !events[3].Recurring {
+		t.Errorf("only the fourth event with RRULE should be recurring")
+	}
+}
+
+func TestParseCalendarInvalid(t *testing.T) {
+	if _, err := parseCalendar(strings.NewReader("not a calendar"), time.UTC); err != ErrInvalidCalendar {
+		t.Errorf("expected ErrInvalidCalendar, got %v", err)
+	}
+}
+
+func TestCalendarEntry(t *testing.T) {
+	now := time.Date(2024, 8, 1, 0, 0, 0, 0, time.UTC)
+	event := calendarEvent{
+		UID:        "1@example.com",
+		Summary:    "[work] Review",
+		Categories: []string{"Work"},
+		Start:      time.Date(2024, 7, 1, 9, 0, 0, 0, time.UTC),
+		End:        time.Date(2024, 7, 1, 10, 0, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		rules models.CalendarImportRules
+		event calendarEvent
+		name  string
+		ok    bool
+	}{
+		{models.CalendarImportRules{}, event, "[work] Review", true},
+		{models.CalendarImportRules{Prefix: "[work]"}, event, "Review", true},
+		{models.CalendarImportRules{Prefix: "[home]"}, event, "", false},
+		{models.CalendarImportRules{Category: "work"}, event, "[work] Review", true},
+		{models.CalendarImportRules{Category: "meeting"}, event, "", false},
+		{models.CalendarImportRules{}, calendarEvent{Summary: "x", Start: event.Start, End: now.Add(time.Hour)}, "", false},
+		{models.CalendarImportRules{}, calendarEvent{Summary: "x", Start: event.Start, End: event.End, Recurring: true}, "", false},
+	}
+
+	for _, test := range tests {
+		entry, ok := calendarEntry(test.event, test.rules, now)
+		if ok != test.ok || entry.TaskName != test.name {
+			t.Errorf("calendarEntry(%+v, %+v) = %q, %v; expected %q, %v", test.event, test.rules, entry.TaskName, ok, test.name, test.ok)
+		}
+	}
+}
+
+func TestParseCalendarDuration(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected time.Duration
+	}{
+		{"PT1H30M", 90 * time.Minute},
+		{"P1DT2H", 26 * time.Hour},
+		{"P1W", 7 * 24 * time.Hour},
+		{"PT45S", 45 * time.Second},
+	}
+
+	for _, test := range tests {
+		d, err := parseCalendarDuration(test.value)
+		if err != nil || d != test.expected {
+			t.Errorf("parseCalendarDuration(%q) = %v, %v; expected %v", test.value, d, err, test.expected)
+		}
+	}
+
+	if _, err := parseCalendarDuration("1H"); err == nil {
+		t.Errorf("expected error for duration without P")
+	}
+}
@@ -0,0 +1,52 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/moxicom/user_test/internal/models"
+)
+
+func TestRenderCalendar(t *testing.T) {
+	start := time.Date(2024, 7, 1, 9, 0, 0, 0, time.UTC)
+	end := start.Add(90 * time.Minute)
+	periods := []models.ReportPeriod{
+		{TaskPeriod: models.TaskPeriod{ID: 5, TaskID: 2, StartTime: &start, EndTime: &end}, TaskName: "Review, part 1"},
+		{TaskPeriod: models.TaskPeriod{ID: 6, TaskID: 2, StartTime: &end}, TaskName: "running"},
+	}
+
+	ics := string(renderCalendar(models.User{Surname: "Ivanov", Name: "Ivan"}, periods, end))
+
+	for _, line := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"UID:period-5@user_test\r\n",
+		"DTSTART:20240701T090000Z\r\n",
+		"DTEND:20240701T103000Z\r\n",
+		"SUMMARY:Review\\, part 1\r\n",
+		"DESCRIPTION:User: Ivanov Ivan\\nTask ID: 2\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(ics, line) {
+			t.Errorf("calendar does not contain %q:\n%s", line, ics)
+		}
+	}
+	if strings.Count(ics, "BEGIN:VEVENT") != 1 {
+		t.Errorf("expected only the finished period to be rendered:\n%s", ics)
+	}
+}
+
+func TestFoldLine(t *testing.T) {
+	line := "SUMMARY:" + strings.Repeat("я", 60)
+
+	folded := foldLine(line)
+
+	for _, part := range strings.Split(folded, "\r\n") {
+		if len(part) > 75 {
+			t.Errorf("line %q is longer than 75 octets", part)
+		}
+	}
+	if strings.ReplaceAll(folded, "\r\n ", "") != line {
+		t.Errorf("unfolded %q; expected %q", folded, line)
+	}
+}
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/moxicom/user_test/internal/models"
+)
+
+type RoundingMode string
+
+const (
+	RoundNearest RoundingMode = "nearest"
+	RoundUp      RoundingMode = "up"
+)
+
+// Rounding is applied to every reported task total. Aggregates are sums of
+// rounded totals so they add up
+type Rounding struct {
+	Minutes int // zero disables rounding
+	Mode    RoundingMode
+}
+
+// Round rounds seconds to the step of r.Minutes
+func (r Rounding) Round(seconds int64) int64 {
+	if r.Minutes <= 0 {
+		return seconds
+	}
+
+	step := int64(r.Minutes) * 60
+	switch r.Mode {
+	case RoundUp:
+		return (seconds + step - 1) / step * step
+	default:
+		return (seconds + step/2) / step * step
+	}
+}
+
+// Duration rounds seconds and represents them as models.Duration
+func (r Rounding) Duration(seconds int64) models.Duration {
+	return newDuration(r.Round(seconds))
+}
+
+func newDuration(seconds int64) models.Duration {
+	return models.Duration{
+		TotalSeconds:    seconds,
+		Duration:        iso8601Duration(seconds),
+		DurationHours:   int(seconds / 3600),
+		DurationMinutes: int(seconds % 3600 / 60),
+		DurationSeconds: int(seconds % 60),
+	}
+}
+
+// iso8601Duration formats seconds as ISO 8601 duration with hours as the largest unit
+func iso8601Duration(seconds int64) string {
+	if seconds <= 0 {
+		return "PT0S"
+	}
+
+	var b strings.Builder
+	b.WriteString("PT")
+	if h := seconds / 3600; h > 0 {
+		fmt.Fprintf(&b, "%dH", h)
+	}
+	if m := seconds % 3600 / 60; m > 0 {
+		fmt.Fprintf(&b, "%dM", m)
+	}
+	if s := seconds % 60; s > 0 {
+		fmt.Fprintf(&b, "%dS", s)
+	}
+	return b.String()
+}
+
+// roundTasks fills durations of tasks from their total seconds and progress
+// against their estimates
+func (r Rounding) roundTasks(tasks []models.TaskWithTotalTime) {
+	for i := range tasks {
+		tasks[i].Duration = r.Duration(tasks[i].TotalSeconds)
+		tasks[i].EstimateProgress = estimateProgress(tasks[i].Estimate, tasks[i].TrackedSeconds)
+	}
+}
+
+// roundSummary rounds task totals of every bucket and sums them into bucket totals
+func (r Rounding) roundSummary(buckets []models.SummaryBucket) {
+	for i := range buckets {
+		var total int64
+		for j := range buckets[i].Tasks {
+			buckets[i].Tasks[j].Duration = r.Duration(buckets[i].Tasks[j].TotalSeconds)
+			total += buckets[i].Tasks[j].TotalSeconds
+		}
+		buckets[i].Duration = newDuration(total)
+	}
+}
@@ -0,0 +1,52 @@
+package services
+
+import "testing"
+
+func TestRound(t *testing.T) {
+	tests := []struct {
+		rounding Rounding
+		seconds  int64
+		expected int64
+	}{
+		{Rounding{}, 125, 125},
+		{Rounding{Minutes: 1, Mode: RoundNearest}, 89, 60},
+		{Rounding{Minutes: 1, Mode: RoundNearest}, 90, 120},
+		{Rounding{Minutes: 5, Mode: RoundNearest}, 7 * 60, 5 * 60},
+		{Rounding{Minutes: 5, Mode: RoundNearest}, 8 * 60, 10 * 60},
+		{Rounding{Minutes: 15, Mode: RoundNearest}, 0, 0},
+		{Rounding{Minutes: 15, Mode: RoundUp}, 1, 15 * 60},
+		{Rounding{Minutes: 15, Mode: RoundUp}, 15 * 60, 15 * 60},
+		{Rounding{Minutes: 15, Mode: RoundUp}, 15*60 + 1, 30 * 60},
+	}
+
+	for _, test := range tests {
+		result := test.rounding.Round(test.seconds)
+		if result != test.expected {
+			t.Errorf("%+v.Round(%d) = %d; expected %d", test.rounding, test.seconds, result, test.expected)
+		}
+	}
+}
+
+func TestNewDuration(t *testing.T) {
+	tests := []struct {
+		seconds int64
+		iso     string
+		hours   int
+		minutes int
+		secs    int
+	}{
+		{0, "PT0S", 0, 0, 0},
+		{59, "PT59S", 0, 0, 59},
+		{3600, "PT1H", 1, 0, 0},
+		{5405, "PT1H30M5S", 1, 30, 5},
+		{90000, "PT25H", 25, 0, 0},
+	}
+
+	for _, test := range tests {
+		d := newDuration(test.seconds)
+		if d.TotalSeconds != test.seconds || d.Duration != test.iso ||
+			d.DurationHours != test.hours || d.DurationMinutes != test.minutes || d.DurationSeconds != test.secs {
+			t.Errorf("newDuration(%d) = %+v; expected %s %dh %dm %ds", test.seconds, d, test.iso, test.hours, test.minutes, test.secs)
+		}
+	}
+}
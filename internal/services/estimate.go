@@ -0,0 +1,152 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/moxicom/user_test/internal/models"
+	"github.com/moxicom/user_test/internal/storage"
+)
+
+const webhookTimeout = 10 * time.Second
+
+type EstimateAlertConfig struct {
+	Thresholds []int // percents of estimate, ascending
+	Interval   time.Duration
+	WebhookURL string // new alerts are posted to it, empty to only record them
+}
+
+// EstimateAlerts periodically records alerts for open tasks whose tracked
+// time crossed thresholds of their estimates and posts new ones to the
+// webhook. Alerts are not posted again if the webhook fails, they stay
+// available from GET /alerts
+type EstimateAlerts struct {
+	s      storage.Storage
+	log    *slog.Logger
+	cfg    EstimateAlertConfig
+	client *http.Client
+}
+
+func NewEstimateAlerts(s storage.Storage, log *slog.Logger, cfg EstimateAlertConfig) *EstimateAlerts {
+	return &EstimateAlerts{s, log, cfg, &http.Client{Timeout: webhookTimeout}}
+}
+
+func (e *EstimateAlerts) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			e.checkEstimates(time.Now())
+		}
+	}
+}
+
+func (e *EstimateAlerts) checkEstimates(now time.Time) {
+	log := e.log.With(slog.String("op", "EstimateAlerts.checkEstimates"))
+
+	tasks, err := e.s.GetEstimatedTasks()
+	if err != nil {
+		log.Error("failed to get estimated tasks", slog.Any("err", err))
+		return
+	}
+
+	var alerts []models.TaskAlert
+	for _, task := range tasks {
+		for _, threshold := range crossedThresholds(e.cfg.Thresholds, task.Estimate, task.TrackedSeconds, task.Alerted) {
+			alerts = append(alerts, models.TaskAlert{
+				TaskID:          task.ID,
+				UserID:          task.UserID,
+				Threshold:       threshold,
+				EstimateSeconds: task.Estimate,
+				TrackedSeconds:  task.TrackedSeconds,
+				CreatedAt:       now,
+			})
+			log.Warn("Task estimate threshold crossed",
+				slog.Uint64("task_id", uint64(task.ID)),
+				slog.String("task_name", task.TaskName),
+				slog.Uint64("user_id", uint64(task.UserID)),
+				slog.Int("threshold", threshold),
+				slog.Int64("tracked_seconds", task.TrackedSeconds),
+				slog.Int64("estimate_seconds", task.Estimate))
+		}
+	}
+
+	if err := e.s.CreateTaskAlerts(alerts); err != nil {
+		log.Error("failed to record task alerts", slog.Any("err", err))
+		return
+	}
+
+	// alerts recorded meanwhile by another instance are left without ID
+	created := make([]models.TaskAlert, 0, len(alerts))
+	for _, alert := range alerts {
+		if alert.ID != 0 {
+			created = append(created, alert)
+		}
+	}
+	if len(created) == 0 || e.cfg.WebhookURL == "" {
+		return
+	}
+	if err := e.notify(created); err != nil {
+		log.Error("failed to post task alerts to webhook", slog.Int("alerts", len(created)), slog.Any("err", err))
+	}
+}
+
+// notify posts alerts to the webhook as JSON array
+func (e *EstimateAlerts) notify(alerts []models.TaskAlert) error {
+	body, err := json.Marshal(alerts)
+	if err != nil {
+		return err
+	}
+
+	resp, err := e.client.Post(e.cfg.WebhookURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded %s", resp.Status)
+	}
+	return nil
+}
+
+// crossedThresholds returns thresholds above alerted which tracked time reached
+func crossedThresholds(thresholds []int, estimate, tracked int64, alerted int) []int {
+	if estimate <= 0 {
+		return nil
+	}
+
+	var crossed []int
+	for _, threshold := range thresholds {
+		if threshold > alerted && tracked*100 >= estimate*int64(threshold) {
+			crossed = append(crossed, threshold)
+		}
+	}
+	return crossed
+}
+
+// estimateProgress compares tracked seconds with estimate. Without an
+// estimate only tracked time is set
+func estimateProgress(estimate *int64, tracked int64) models.EstimateProgress {
+	progress := models.EstimateProgress{TrackedSeconds: tracked}
+	if estimate == nil || *estimate <= 0 {
+		return progress
+	}
+
+	remaining := max(*estimate-tracked, 0)
+	overrun := max(tracked-*estimate, 0)
+	used := roundCents(float64(tracked) * 100 / float64(*estimate))
+	progress.RemainingSeconds = &remaining
+	progress.OverrunSeconds = &overrun
+	progress.EstimateUsed = &used
+	return progress
+}
@@ -0,0 +1,96 @@
+package services
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/moxicom/user_test/internal/models"
+)
+
+func TestCrossedThresholds(t *testing.T) {
+	thresholds := []int{80, 100, 150}
+
+	tests := []struct {
+		name     string
+		estimate int64
+		tracked  int64
+		alerted  int
+		expected []int
+	}{
+		{"below first", 3600, 2879, 0, nil},
+		{"first reached", 3600, 2880, 0, []int{80}},
+		{"already alerted", 3600, 3000, 80, nil},
+		{"next crossed", 3600, 3600, 80, []int{100}},
+		{"several at once", 3600, 6000, 0, []int{80, 100, 150}},
+		{"no estimate", 0, 6000, 0, nil},
+	}
+
+	for _, test := range tests {
+		result := crossedThresholds(thresholds, test.estimate, test.tracked, test.alerted)
+		if !reflect.DeepEqual(result, test.expected) {
+			t.Errorf("%s: crossedThresholds() = %v; expected %v", test.name, result, test.expected)
+		}
+	}
+}
+
+func TestEstimateProgress(t *testing.T) {
+	if p := estimateProgress(nil, 100); p.TrackedSeconds != 100 || p.RemainingSeconds != nil || p.OverrunSeconds != nil || p.EstimateUsed != nil {
+		t.Errorf("without estimate = %+v", p)
+	}
+
+	estimate := int64(3600)
+	p := estimateProgress(&estimate, 1200)
+	if *p.RemainingSeconds != 2400 || *p.OverrunSeconds != 0 || *p.EstimateUsed != 33.33 {
+		t.Errorf("under estimate = %d, %d, %v; expected 2400, 0, 33.33", *p.RemainingSeconds, *p.OverrunSeconds, *p.EstimateUsed)
+	}
+
+	p = estimateProgress(&estimate, 5400)
+	if *p.RemainingSeconds != 0 || *p.OverrunSeconds != 1800 || *p.EstimateUsed != 150 {
+		t.Errorf("over estimate = %d, %d, %v; expected 0, 1800, 150", *p.RemainingSeconds, *p.OverrunSeconds, *p.EstimateUsed)
+	}
+}
+
+func TestCheckEstimatesPostsNewAlerts(t *testing.T) {
+	var posted [][]models.TaskAlert
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var alerts []models.TaskAlert
+		if err := json.NewDecoder(r.Body).Decode(&alerts); err != nil || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("webhook got invalid body: %v", err)
+		}
+		posted = append(posted, alerts)
+	}))
+	defer webhook.Close()
+
+	store := &fakeStorage{
+		estimated: []models.EstimatedTask{{ID: 1, UserID: 5, TaskName: "task", Estimate: 3600, TrackedSeconds: 3000}},
+		// recorded by another instance after the task was read
+		alerts: []models.TaskAlert{{ID: 1, TaskID: 1, Threshold: 100}},
+	}
+	cfg := EstimateAlertConfig{Thresholds: []int{80, 100}, WebhookURL: webhook.URL}
+	alerts := NewEstimateAlerts(store, discardLogger(), cfg)
+
+	store.estimated[0].TrackedSeconds = 3600
+	alerts.checkEstimates(time.Now())
+	if len(posted) != 1 || len(posted[0]) != 1 || posted[0][0].Threshold != 80 || posted[0][0].TaskID != 1 {
+		t.Fatalf("posted = %+v; expected new alert of 80%% only", posted)
+	}
+
+	// thresholds already alerted are not posted again
+	store.estimated[0].Alerted = 100
+	alerts.checkEstimates(time.Now())
+	if len(posted) != 1 {
+		t.Errorf("posted %d times; expected nothing new", len(posted))
+	}
+
+	// without webhook alerts are only recorded
+	store.estimated[0].Alerted, store.alerts = 0, nil
+	alerts.cfg.WebhookURL = ""
+	alerts.checkEstimates(time.Now())
+	if len(posted) != 1 || len(store.alerts) != 2 {
+		t.Errorf("posted %d times, recorded %d alerts; expected 1 and 2", len(posted), len(store.alerts))
+	}
+}
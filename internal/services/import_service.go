@@ -0,0 +1,305 @@
+package services
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/moxicom/user_test/internal/models"
+	"github.com/moxicom/user_test/internal/storage"
+)
+
+const untitledTask = "Untitled"
+
+var csvDateLayouts = map[models.CSVFormat][]string{
+	models.CSVToggl:    {"2006-01-02"},
+	models.CSVClockify: {"01/02/2006", "2006-01-02", "02.01.2006"},
+}
+
+var csvTimeLayouts = []string{"15:04:05", "03:04:05 PM", "3:04:05 PM", "15:04", "03:04 PM", "3:04 PM"}
+
+// csvEntry is a row of detailed report
+type csvEntry struct {
+	Line      int
+	Email     string
+	Passport  string
+	Project   string
+	TaskName  string
+	StartDate string
+	StartTime string
+	EndDate   string
+	EndTime   string
+	Billable  string
+}
+
+type ImportService struct {
+	s   storage.Storage
+	log *slog.Logger
+}
+
+func newImportService(s storage.Storage, log *slog.Logger) *ImportService {
+	return &ImportService{s, log}
+}
+
+// ImportTimeEntries imports Toggl or Clockify detailed report. People are
+// mapped to users by passport column if present, otherwise by email, and
+// projects by name. Rows with an email of several users are skipped as errors.
+// On dry run nothing is saved and entries are returned
+func (s *ImportService) ImportTimeEntries(r io.Reader, opts models.CSVImportOptions) (models.CSVImportResult, error) {
+	log := s.log.With(slog.String("op", "service.ImportTimeEntries"))
+
+	format, rows, err := parseTimeEntries(r, opts.Format)
+	if err != nil {
+		return models.CSVImportResult{}, err
+	}
+
+	var loc *time.Location
+	if opts.TimeZone != "" {
+		if loc, err = loadLocation(opts.TimeZone); err != nil {
+			return models.CSVImportResult{}, err
+		}
+	}
+
+	users, err := s.s.GetUsers(models.UserFilters{})
+	if err != nil {
+		return models.CSVImportResult{}, err
+	}
+	byEmail := make(map[string]models.User, len(users))
+	byPassport := make(map[string]models.User, len(users))
+	ambiguous := make(map[string]bool) // emails shared by several users
+	for _, u := range users {
+		if email := strings.ToLower(u.Email); email != "" {
+			if _, ok := byEmail[email]; ok {
+				ambiguous[email] = true
+			}
+			byEmail[email] = u
+		}
+		byPassport[u.PassportNumber] = u
+	}
+
+	projects, err := s.s.GetProjects(models.ProjectFilters{})
+	if err != nil {
+		return models.CSVImportResult{}, err
+	}
+	projectIDs := make(map[string]uint, len(projects))
+	for _, p := range projects {
+		projectIDs[strings.ToLower(p.Name)] = p.ID
+	}
+
+	result := models.CSVImportResult{Format: format, DryRun: opts.DryRun, UnknownPeople: []string{}, Errors: []string{}}
+	unknown := make(map[string]struct{})
+	entries := make([]models.ImportEntry, 0, len(rows))
+	now := time.Now()
+	for _, row := range rows {
+		identity, user, ok := row.Passport, byPassport[row.Passport], row.Passport != ""
+		if !ok {
+			identity, user, ok = row.Email, byEmail[strings.ToLower(row.Email)], row.Email != ""
+			if ambiguous[strings.ToLower(row.Email)] {
+				result.Ignored++
+				result.Errors = append(result.Errors, fmt.Sprintf("line %d: email %s belongs to several users", row.Line, row.Email))
+				continue
+			}
+		}
+		if !ok || user.ID == 0 {
+			result.Ignored++
+			if identity != "" {
+				unknown[identity] = struct{}{}
+			} else {
+				result.Errors = append(result.Errors, fmt.Sprintf("line %d: no email or passport", row.Line))
+			}
+			continue
+		}
+
+		rowLoc := loc
+		if rowLoc == nil {
+			if rowLoc, err = loadLocation(user.TimeZone); err != nil {
+				rowLoc = time.Local
+			}
+		}
+
+		start, end, err := entryTimes(row, format, rowLoc)
+		if err == nil && end.After(now) {
+			err = ErrPeriodInFuture
+		}
+		var billable *bool
+		if err == nil {
+			billable, err = parseBillable(row.Billable)
+		}
+		if err != nil {
+			result.Ignored++
+			result.Errors = append(result.Errors, fmt.Sprintf("line %d: %v", row.Line, err))
+			continue
+		}
+
+		// the same period is a duplicate whichever column matched the user
+		entry := models.ImportEntry{
+			UserID:     user.ID,
+			ExternalID: fmt.Sprintf("csv:%d:%s:%s", user.ID, start.UTC().Format(time.RFC3339), end.UTC().Format(time.RFC3339)),
+			TaskName:   row.TaskName,
+			StartTime:  start,
+			EndTime:    end,
+			Billable:   billable,
+		}
+		if id, ok := projectIDs[strings.ToLower(row.Project)]; ok && row.Project != "" {
+			entry.ProjectID = &id
+		}
+		entries = append(entries, entry)
+	}
+
+	for identity := range unknown {
+		result.UnknownPeople = append(result.UnknownPeople, identity)
+	}
+	sort.Strings(result.UnknownPeople)
+
+	imported, err := s.s.ImportPeriods(entries, opts.DryRun)
+	if err != nil {
+		log.Error("failed to import periods", slog.Any("err", err))
+		return models.CSVImportResult{}, err
+	}
+	imported.Ignored += result.Ignored
+	result.ImportResult = imported
+	if opts.DryRun {
+		result.Entries = entries
+	}
+
+	return result, nil
+}
+
+// parseTimeEntries reads rows of detailed report. Format is detected from
+// duration columns if not set
+func parseTimeEntries(r io.Reader, format models.CSVFormat) (models.CSVFormat, []csvEntry, error) {
+	switch format {
+	case "", models.CSVToggl, models.CSVClockify:
+	default:
+		return "", nil, ErrInvalidCSVFormat
+	}
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return "", nil, ErrInvalidCSV
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		columns[name] = i
+	}
+
+	if format == "" {
+		switch {
+		case hasColumn(columns, "duration (h)") || hasColumn(columns, "duration (decimal)"):
+			format = models.CSVClockify
+		case hasColumn(columns, "duration"):
+			format = models.CSVToggl
+		default:
+			return "", nil, ErrInvalidCSV
+		}
+	}
+
+	for _, required := range []string{"start date", "start time", "end date", "end time"} {
+		if !hasColumn(columns, required) {
+			return "", nil, ErrInvalidCSV
+		}
+	}
+	if !hasColumn(columns, "email") && !hasColumn(columns, "passport") && !hasColumn(columns, "passport number") {
+		return "", nil, ErrInvalidCSV
+	}
+
+	rows := make([]csvEntry, 0)
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return "", nil, ErrInvalidCSV
+		}
+
+		field := func(names ...string) string {
+			for _, name := range names {
+				if i, ok := columns[name]; ok && i < len(record) && strings.TrimSpace(record[i]) != "" {
+					return strings.TrimSpace(record[i])
+				}
+			}
+			return ""
+		}
+
+		name := field("description", "task")
+		if name == "" {
+			name = untitledTask
+		}
+
+		rows = append(rows, csvEntry{
+			Line:      line,
+			Email:     field("email"),
+			Passport:  field("passport", "passport number"),
+			Project:   field("project"),
+			TaskName:  name,
+			StartDate: field("start date"),
+			StartTime: field("start time"),
+			EndDate:   field("end date"),
+			EndTime:   field("end time"),
+			Billable:  field("billable"),
+		})
+	}
+
+	return format, rows, nil
+}
+
+func hasColumn(columns map[string]int, name string) bool {
+	_, ok := columns[name]
+	return ok
+}
+
+// entryTimes parses start and end of the row in loc
+func entryTimes(row csvEntry, format models.CSVFormat, loc *time.Location) (time.Time, time.Time, error) {
+	start, err := parseEntryTime(row.StartDate, row.StartTime, format, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid start %q %q", row.StartDate, row.StartTime)
+	}
+
+	end, err := parseEntryTime(row.EndDate, row.EndTime, format, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid end %q %q", row.EndDate, row.EndTime)
+	}
+
+	if !start.Before(end) {
+		return time.Time{}, time.Time{}, ErrInvalidPeriod
+	}
+
+	return start, end, nil
+}
+
+// parseBillable parses billable column. Empty value is nil
+func parseBillable(v string) (*bool, error) {
+	var billable bool
+	switch strings.ToLower(v) {
+	case "":
+		return nil, nil
+	case "yes", "true", "1":
+		billable = true
+	case "no", "false", "0":
+		billable = false
+	default:
+		return nil, fmt.Errorf("invalid billable %q", v)
+	}
+	return &billable, nil
+}
+
+func parseEntryTime(date, clock string, format models.CSVFormat, loc *time.Location) (time.Time, error) {
+	for _, dateLayout := range csvDateLayouts[format] {
+		for _, timeLayout := range csvTimeLayouts {
+			if t, err := time.ParseInLocation(dateLayout+" "+timeLayout, date+" "+clock, loc); err == nil {
+				return t, nil
+			}
+		}
+	}
+	return time.Time{}, ErrInvalidCSV
+}
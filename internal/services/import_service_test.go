@@ -0,0 +1,103 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/moxicom/user_test/internal/models"
+)
+
+const togglCSV = "\ufeffUser,Email,Client,Project,Task,Description,Billable,Start date,Start time,End date,End time,Duration,Tags\n" +
+	"Ivan,ivan@example.com,,Site,,Review,No,2024-07-01,09:00:00,2024-07-01,10:30:00,01:30:00,\n" +
+	"Petr,petr@example.com,,,Design,,No,2024-07-01,23:00:00,2024-07-02,01:00:00,02:00:00,\n"
+
+const clockifyCSV = "Project,Client,Description,Task,User,Group,Email,Tags,Billable,Start Date,Start Time,End Date,End Time,Duration (h),Duration (decimal)\n" +
+	"Site,,Review,,Ivan,,ivan@example.com,,No,07/01/2024,09:00:00 AM,07/01/2024,01:30:00 PM,04:30:00,4.50\n"
+
+func TestParseTimeEntries(t *testing.T) {
+	format, rows, err := parseTimeEntries(strings.NewReader(togglCSV), "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if format != models.CSVToggl || len(rows) != 2 {
+		t.Fatalf("got format %q and %d rows; expected toggl and 2 rows", format, len(rows))
+	}
+	if rows[0].Email != "ivan@example.com" || rows[0].TaskName != "Review" || rows[0].Project != "Site" || rows[0].Line != 2 {
+		t.Errorf("first row = %+v", rows[0])
+	}
+	if rows[1].TaskName != "Design" {
+		t.Errorf("second row task = %q; expected task column used without description", rows[1].TaskName)
+	}
+
+	start, end, err := entryTimes(rows[1], format, time.UTC)
+	if err != nil || !start.Equal(time.Date(2024, 7, 1, 23, 0, 0, 0, time.UTC)) || end.Sub(start) != 2*time.Hour {
+		t.Errorf("second row times = %v - %v, %v", start, end, err)
+	}
+
+	format, rows, err = parseTimeEntries(strings.NewReader(clockifyCSV), "")
+	if err != nil || format != models.CSVClockify || len(rows) != 1 {
+		t.Fatalf("got %q, %d rows, %v; expected clockify with 1 row", format, len(rows), err)
+	}
+	start, end, err = entryTimes(rows[0], format, time.UTC)
+	if err != nil || !start.Equal(time.Date(2024, 7, 1, 9, 0, 0, 0, time.UTC)) || end.Sub(start) != 270*time.Minute {
+		t.Errorf("clockify row times = %v - %v, %v", start, end, err)
+	}
+}
+
+func TestParseTimeEntriesInvalid(t *testing.T) {
+	if _, _, err := parseTimeEntries(strings.NewReader("a,b\n1,2\n"), ""); err != ErrInvalidCSV {
+		t.Errorf("expected ErrInvalidCSV, got %v", err)
+	}
+	if _, _, err := parseTimeEntries(strings.NewReader(togglCSV), "harvest"); err != ErrInvalidCSVFormat {
+		t.Errorf("expected ErrInvalidCSVFormat, got %v", err)
+	}
+}
+
+func TestImportTimeEntriesSkipsAmbiguousEmails(t *testing.T) {
+	fake := &fakeStorage{users: []models.User{
+		{ID: 1, PassportNumber: "1111 111111", Email: "ivan@example.com"},
+		{ID: 2, PassportNumber: "2222 222222", Email: "Ivan@Example.com"},
+		{ID: 3, PassportNumber: "3333 333333", Email: "petr@example.com"},
+	}}
+	service := newImportService(fake, discardLogger())
+
+	result, err := service.ImportTimeEntries(strings.NewReader(togglCSV), models.CSVImportOptions{TimeZone: "UTC"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(fake.imported) != 1 || fake.imported[0].UserID != 3 {
+		t.Errorf("imported %+v; expected only the entry of user 3", fake.imported)
+	}
+	if result.Ignored != 1 || len(result.Errors) != 1 || !strings.Contains(result.Errors[0], "line 2") {
+		t.Errorf("ignored %d with errors %q; expected line 2 reported", result.Ignored, result.Errors)
+	}
+}
+
+func TestImportTimeEntriesExternalID(t *testing.T) {
+	fake := &fakeStorage{users: []models.User{
+		{ID: 3, PassportNumber: "3333 333333", Email: "petr@example.com"},
+	}}
+	service := newImportService(fake, discardLogger())
+	byEmail := "Email,Billable,Start date,Start time,End date,End time,Duration\n" +
+		"petr@example.com,No,2024-07-01,09:00:00,2024-07-01,10:00:00,01:00:00\n"
+	byPassport := "Passport,Billable,Start date,Start time,End date,End time,Duration\n" +
+		"3333 333333,,2024-07-01,09:00:00,2024-07-01,10:00:00,01:00:00\n"
+
+	for _, csv := range []string{byEmail, byPassport} {
+		if _, err := service.ImportTimeEntries(strings.NewReader(csv), models.CSVImportOptions{TimeZone: "UTC"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if len(fake.imported) != 2 || fake.imported[0].ExternalID != fake.imported[1].ExternalID {
+		t.Fatalf("imported %+v; expected the same external id for both rows", fake.imported)
+	}
+	if b := fake.imported[0].Billable; b == nil || *b {
+		t.Errorf("billable of row with No = %v; expected false", b)
+	}
+	if b := fake.imported[1].Billable; b != nil {
+		t.Errorf("billable of row without value = %v; expected nil", *b)
+	}
+}
@@ -0,0 +1,54 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/moxicom/user_test/internal/models"
+)
+
+// printableInvoice is an invoice with its issuer and time zone of dates
+type printableInvoice struct {
+	models.Invoice
+	Issuer   string
+	Location *time.Location
+}
+
+// Period formats the range with inclusive last day
+func (inv printableInvoice) Period() string {
+	return inv.From.In(inv.Location).Format("2006-01-02") + " - " + inv.To.In(inv.Location).Add(-time.Nanosecond).Format("2006-01-02")
+}
+
+func (inv printableInvoice) Issued() string {
+	return inv.IssuedAt.In(inv.Location).Format("2006-01-02")
+}
+
+// formatHours formats hours with two decimals
+func formatHours(hours float64) string {
+	return fmt.Sprintf("%.2f", hours)
+}
+
+// document lays out the invoice with an item of each row
+func (inv printableInvoice) document() printDocument {
+	doc := printDocument{
+		Title:   "Invoice " + inv.Number,
+		Heading: "Invoice " + inv.Number,
+		Info: [][2]string{
+			{"Bill to:", inv.Client},
+			{"Issued:", inv.Issued()},
+			{"Period:", inv.Period()},
+			{"Currency:", inv.Currency},
+		},
+		Columns: []string{"#", "Description", "Hours", "Rate", "Amount"},
+		Rows:    make([][]string, 0, len(inv.Items)),
+		Total:   []string{"", "Total, " + inv.Currency, "", "", inv.Total.String()},
+	}
+	if inv.Issuer != "" {
+		doc.Info = append([][2]string{{"From:", inv.Issuer}}, doc.Info...)
+	}
+	for _, item := range inv.Items {
+		doc.Rows = append(doc.Rows, []string{fmt.Sprint(item.Position), item.Description,
+			formatHours(item.Hours), item.HourlyRate.String(), item.Amount.String()})
+	}
+	return doc
+}
@@ -0,0 +1,42 @@
+package services
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/moxicom/user_test/internal/models"
+)
+
+func TestInvoiceDocument(t *testing.T) {
+	invoice := buildInvoice([]models.InvoiceLine{
+		{TaskID: 1, TaskName: "Design", Surname: "Иванов", Name: "Иван", HourlyRate: 5000, Seconds: 5400},
+	}, "USD")
+	invoice.Number = "2024-0007"
+	invoice.Client = "Acme"
+	invoice.From = time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+	invoice.To = time.Date(2024, 8, 1, 0, 0, 0, 0, time.UTC)
+	invoice.IssuedAt = time.Date(2024, 8, 2, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		issuer string
+		info   [][2]string
+	}{
+		{"Studio", [][2]string{{"From:", "Studio"}, {"Bill to:", "Acme"}, {"Issued:", "2024-08-02"}, {"Period:", "2024-07-01 - 2024-07-31"}, {"Currency:", "USD"}}},
+		{"", [][2]string{{"Bill to:", "Acme"}, {"Issued:", "2024-08-02"}, {"Period:", "2024-07-01 - 2024-07-31"}, {"Currency:", "USD"}}},
+	}
+
+	for _, test := range tests {
+		doc := printableInvoice{Invoice: invoice, Issuer: test.issuer, Location: time.UTC}.document()
+
+		if doc.Heading != "Invoice 2024-0007" || !slices.Equal(doc.Info, test.info) {
+			t.Errorf("issuer %q: heading %q, info %q; expected %q", test.issuer, doc.Heading, doc.Info, test.info)
+		}
+		if len(doc.Rows) != 1 || !slices.Equal(doc.Rows[0], []string{"1", "Design (Иванов Иван)", "1.50", "50.00", "75.00"}) {
+			t.Errorf("issuer %q: rows = %q", test.issuer, doc.Rows)
+		}
+		if !slices.Equal(doc.Total, []string{"", "Total, USD", "", "", "75.00"}) {
+			t.Errorf("issuer %q: total = %q", test.issuer, doc.Total)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package services
+
+import (
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/moxicom/user_test/internal/models"
+	"github.com/moxicom/user_test/internal/storage"
+)
+
+// InvoiceConfig is printed on every invoice
+type InvoiceConfig struct {
+	Currency string // ISO 4217 code of amounts
+	Issuer   string // name of the invoicing company
+}
+
+type InvoiceService struct {
+	s   storage.Storage
+	log *slog.Logger
+	cfg InvoiceConfig
+}
+
+func newInvoiceService(s storage.Storage, log *slog.Logger, cfg InvoiceConfig) *InvoiceService {
+	return &InvoiceService{s, log, cfg}
+}
+
+// CreateInvoice bills unbilled billable periods of the project or of all
+// projects of the client started within the range. Dates are printed in loc
+func (s *InvoiceService) CreateInvoice(sel models.InvoiceSelection, loc *time.Location) (models.Invoice, error) {
+	sel.Client = strings.TrimSpace(sel.Client)
+	if (sel.ProjectID == 0) == (sel.Client == "") {
+		return models.Invoice{}, ErrInvalidInvoiceTarget
+	}
+	if !sel.From.Before(sel.To) {
+		return models.Invoice{}, ErrInvalidRange
+	}
+
+	client := sel.Client
+	var projectID *uint
+	if sel.ProjectID != 0 {
+		project, err := s.s.GetProject(sel.ProjectID)
+		if err != nil {
+			return models.Invoice{}, err
+		}
+		client, projectID = project.Client, &project.ID
+	}
+
+	invoice, err := s.s.CreateInvoice(sel, func(lines []models.InvoiceLine) (models.Invoice, error) {
+		if len(lines) == 0 {
+			return models.Invoice{}, ErrNothingToInvoice
+		}
+
+		invoice := buildInvoice(lines, s.cfg.Currency)
+		invoice.Client = client
+		invoice.ProjectID = projectID
+		invoice.From = sel.From
+		invoice.To = sel.To
+		invoice.IssuedAt = time.Now()
+		invoice.TimeZone = loc.String()
+		return invoice, nil
+	})
+	if err != nil {
+		return models.Invoice{}, err
+	}
+
+	s.log.Info("invoice created", slog.String("number", invoice.Number), slog.Int("items", len(invoice.Items)))
+	return invoice, nil
+}
+
+func (s *InvoiceService) GetInvoices(filters models.InvoiceFilters) ([]models.Invoice, error) {
+	return s.s.GetInvoices(filters)
+}
+
+func (s *InvoiceService) GetInvoice(invoiceID uint) (models.Invoice, error) {
+	return s.s.GetInvoice(invoiceID)
+}
+
+// GetInvoiceHTML renders printable invoice
+func (s *InvoiceService) GetInvoiceHTML(invoiceID uint) ([]byte, error) {
+	inv, err := s.printableInvoice(invoiceID)
+	if err != nil {
+		return nil, err
+	}
+
+	return printHTML(inv.document())
+}
+
+// GetInvoicePDF renders the same invoice as GetInvoiceHTML as PDF
+func (s *InvoiceService) GetInvoicePDF(invoiceID uint) ([]byte, error) {
+	inv, err := s.printableInvoice(invoiceID)
+	if err != nil {
+		return nil, err
+	}
+
+	return printPDF(inv.document()), nil
+}
+
+// DeleteInvoice deletes the invoice so its periods can be billed again
+func (s *InvoiceService) DeleteInvoice(invoiceID uint) error {
+	return s.s.DeleteInvoice(invoiceID)
+}
+
+func (s *InvoiceService) printableInvoice(invoiceID uint) (printableInvoice, error) {
+	invoice, err := s.s.GetInvoice(invoiceID)
+	if err != nil {
+		return printableInvoice{}, err
+	}
+
+	loc, err := time.LoadLocation(invoice.TimeZone)
+	if err != nil {
+		s.log.Warn("invalid invoice time zone", slog.String("time_zone", invoice.TimeZone))
+		loc = time.UTC
+	}
+
+	return printableInvoice{Invoice: invoice, Issuer: s.cfg.Issuer, Location: loc}, nil
+}
+
+// buildInvoice makes an item of each line with time billed at its hourly
+// rate. Amounts are rounded to cents before they are summed up
+func buildInvoice(lines []models.InvoiceLine, currency string) models.Invoice {
+	invoice := models.Invoice{Currency: currency, Items: []models.InvoiceItem{}}
+
+	for _, line := range lines {
+		if line.Seconds <= 0 {
+			continue
+		}
+
+		description := line.TaskName
+		if line.ProjectName != "" {
+			description = line.ProjectName + ": " + description
+		}
+		if name := strings.TrimSpace(line.Surname + " " + line.Name); name != "" {
+			description += " (" + name + ")"
+		}
+
+		item := models.InvoiceItem{
+			Position:    len(invoice.Items) + 1,
+			TaskID:      line.TaskID,
+			Description: description,
+			Seconds:     line.Seconds,
+			Hours:       roundCents(float64(line.Seconds) / 3600),
+			HourlyRate:  line.HourlyRate,
+			Amount:      amountOf(line.Seconds, line.HourlyRate),
+		}
+
+		invoice.Items = append(invoice.Items, item)
+		invoice.TotalSeconds += item.Seconds
+		invoice.Total += item.Amount
+	}
+
+	return invoice
+}
@@ -0,0 +1,32 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/moxicom/user_test/internal/models"
+)
+
+func TestBuildInvoice(t *testing.T) {
+	lines := []models.InvoiceLine{
+		{TaskID: 1, TaskName: "Design", Surname: "Иванов", Name: "Иван", ProjectName: "Site", HourlyRate: 5000, Seconds: 5400},
+		{TaskID: 1, TaskName: "Design", Surname: "Иванов", Name: "Иван", ProjectName: "Site", HourlyRate: 6000, Seconds: 0},
+		{TaskID: 2, TaskName: "Support", HourlyRate: 3333, Seconds: 1000},
+	}
+
+	invoice := buildInvoice(lines, "EUR")
+
+	if len(invoice.Items) != 2 {
+		t.Fatalf("items = %d; expected 2", len(invoice.Items))
+	}
+	first, second := invoice.Items[0], invoice.Items[1]
+	if first.Position != 1 || first.Description != "Site: Design (Иванов Иван)" || first.Hours != 1.5 || first.Amount != 7500 {
+		t.Errorf("first item = %+v", first)
+	}
+	// 1000s at 33.33 is 9.258(3)
+	if second.Position != 2 || second.Description != "Support" || second.Hours != 0.28 || second.Amount != 926 {
+		t.Errorf("second item = %+v", second)
+	}
+	if invoice.Total != 8426 || invoice.TotalSeconds != 6400 || invoice.Currency != "EUR" {
+		t.Errorf("total = %v, %ds, %s; expected 84.26, 6400s, EUR", invoice.Total, invoice.TotalSeconds, invoice.Currency)
+	}
+}
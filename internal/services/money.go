@@ -0,0 +1,26 @@
+package services
+
+import (
+	"math"
+
+	"github.com/moxicom/user_test/internal/models"
+)
+
+// addMoney sums money figures
+func addMoney(a, b models.Money) models.Money {
+	return models.Money{
+		BillableSeconds: a.BillableSeconds + b.BillableSeconds,
+		Cost:            a.Cost + b.Cost,
+		BillableAmount:  a.BillableAmount + b.BillableAmount,
+	}
+}
+
+// amountOf returns value of seconds at the hourly rate rounded half up to cents
+func amountOf(seconds int64, rate models.Cents) models.Cents {
+	return models.Cents((seconds*int64(rate) + 1800) / 3600)
+}
+
+// roundCents rounds hours and percents to two decimals
+func roundCents(value float64) float64 {
+	return math.Round(value*100) / 100
+}
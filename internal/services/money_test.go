@@ -0,0 +1,19 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/moxicom/user_test/internal/models"
+)
+
+func TestAddMoney(t *testing.T) {
+	var total models.Money
+	for i := 0; i < 10; i++ {
+		total = addMoney(total, models.Money{BillableSeconds: 60, Cost: 10, BillableAmount: 7})
+	}
+
+	expected := models.Money{BillableSeconds: 600, Cost: 100, BillableAmount: 70}
+	if total != expected {
+		t.Errorf("total = %+v; expected %+v", total, expected)
+	}
+}
@@ -0,0 +1,329 @@
+package services
+
+import (
+	"bytes"
+	"compress/zlib"
+	_ "embed"
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"strings"
+	"unicode/utf16"
+)
+
+// A4 page in points
+const (
+	pdfPageWidth  = 595
+	pdfPageHeight = 842
+	pdfMargin     = 50
+)
+
+//go:embed fonts/DejaVuSans.ttf
+var dejaVuSans []byte
+
+//go:embed fonts/DejaVuSans-Bold.ttf
+var dejaVuSansBold []byte
+
+// pdfFonts are regular and bold fonts of documents
+var pdfFonts = [2]*ttfFont{
+	mustParseTTF("DejaVuSans", dejaVuSans),
+	mustParseTTF("DejaVuSans-Bold", dejaVuSansBold),
+}
+
+func pdfFont(bold bool) int {
+	if bold {
+		return 1
+	}
+	return 0
+}
+
+// pdfDocument writes PDF 1.4 with subsets of embedded DejaVu Sans fonts, so
+// any text they have glyphs for is printed as is
+type pdfDocument struct {
+	pages []*bytes.Buffer
+	used  [len(pdfFonts)]map[uint16]rune // glyphs drawn with each font
+}
+
+func (d *pdfDocument) addPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+}
+
+func (d *pdfDocument) page() *bytes.Buffer {
+	if len(d.pages) == 0 {
+		d.addPage()
+	}
+	return d.pages[len(d.pages)-1]
+}
+
+// text draws s with baseline at (x, y) from bottom left corner
+func (d *pdfDocument) text(x, y, size float64, bold bool, s string) {
+	font := pdfFont(bold)
+	if d.used[font] == nil {
+		d.used[font] = make(map[uint16]rune)
+	}
+
+	var glyphs strings.Builder
+	for _, r := range s {
+		g := pdfFonts[font].glyph(r)
+		d.used[font][g] = r
+		fmt.Fprintf(&glyphs, "%04X", g)
+	}
+	fmt.Fprintf(d.page(), "BT /F%d %g Tf %g %g Td <%s> Tj ET\n", font+1, size, x, y, glyphs.String())
+}
+
+// textRight draws s ending at x
+func (d *pdfDocument) textRight(x, y, size float64, bold bool, s string) {
+	d.text(x-pdfTextWidth(s, size, bold), y, size, bold, s)
+}
+
+func (d *pdfDocument) line(x1, y1, x2, y2 float64) {
+	fmt.Fprintf(d.page(), "%g %g m %g %g l S\n", x1, y1, x2, y2)
+}
+
+func (d *pdfDocument) bytes() []byte {
+	if len(d.pages) == 0 {
+		d.addPage()
+	}
+
+	var buf bytes.Buffer
+	offsets := make([]int, 0)
+	object := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// 1 catalog, 2 pages, 3 and 4 fonts, then page and content pairs and
+	// four more objects of each font
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+i*2)
+	}
+	fontObjects := 5 + len(d.pages)*2
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	for i := range pdfFonts {
+		first := fontObjects + i*4
+		object(fmt.Sprintf("<< /Type /Font /Subtype /Type0 /BaseFont /%s /Encoding /Identity-H "+
+			"/DescendantFonts [%d 0 R] /ToUnicode %d 0 R >>", d.fontName(i), first, first+3))
+	}
+	for i, content := range d.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] "+
+			"/Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>", pdfPageWidth, pdfPageHeight, 6+i*2))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()))
+	}
+	for i, font := range pdfFonts {
+		first := fontObjects + i*4
+		object(fmt.Sprintf("<< /Type /Font /Subtype /CIDFontType2 /BaseFont /%s "+
+			"/CIDSystemInfo << /Registry (Adobe) /Ordering (Identity) /Supplement 0 >> "+
+			"/FontDescriptor %d 0 R /CIDToGIDMap /Identity /W [%s] >>", d.fontName(i), first+1, d.widths(i)))
+		object(fmt.Sprintf("<< /Type /FontDescriptor /FontName /%s /Flags 32 /FontBBox [%d %d %d %d] "+
+			"/ItalicAngle 0 /Ascent %d /Descent %d /CapHeight %d /StemV 80 /FontFile2 %d 0 R >>",
+			d.fontName(i), font.scale(font.bbox[0]), font.scale(font.bbox[1]), font.scale(font.bbox[2]), font.scale(font.bbox[3]),
+			font.scale(font.ascent), font.scale(font.descent), font.scale(font.ascent), first+2))
+
+		glyphs := make(map[uint16]bool, len(d.used[i]))
+		for g := range d.used[i] {
+			glyphs[g] = true
+		}
+		file := font.subset(glyphs)
+		var packed bytes.Buffer
+		w := zlib.NewWriter(&packed)
+		w.Write(file)
+		w.Close()
+		object(fmt.Sprintf("<< /Length %d /Length1 %d /Filter /FlateDecode >>\nstream\n%s\nendstream",
+			packed.Len(), len(file), packed.String()))
+
+		cmap := d.toUnicode(i)
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", len(cmap), cmap))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return buf.Bytes()
+}
+
+// fontName returns name of the font subset, prefixed by a tag of its glyphs
+// as PDF requires for subsets
+func (d *pdfDocument) fontName(font int) string {
+	hash := fnv.New32a()
+	for _, g := range d.glyphs(font) {
+		binary.Write(hash, binary.BigEndian, g)
+	}
+	sum := hash.Sum32()
+
+	tag := make([]byte, 6)
+	for i := range tag {
+		tag[i] = byte('A' + sum%26)
+		sum /= 26
+	}
+	return string(tag) + "+" + pdfFonts[font].name
+}
+
+// glyphs returns glyphs drawn with the font in ascending order
+func (d *pdfDocument) glyphs(font int) []uint16 {
+	glyphs := make([]uint16, 0, len(d.used[font]))
+	for g := range d.used[font] {
+		glyphs = append(glyphs, g)
+	}
+	slices.Sort(glyphs)
+	return glyphs
+}
+
+// widths returns W array of widths of glyphs drawn with the font
+func (d *pdfDocument) widths(font int) string {
+	var w strings.Builder
+	for _, g := range d.glyphs(font) {
+		fmt.Fprintf(&w, "%d [%d] ", g, pdfFonts[font].scale(int(pdfFonts[font].advances[g])))
+	}
+	return strings.TrimSpace(w.String())
+}
+
+// toUnicode returns CMap mapping glyphs drawn with the font to their
+// characters, so text can be searched and copied
+func (d *pdfDocument) toUnicode(font int) string {
+	var b strings.Builder
+	b.WriteString("/CIDInit /ProcSet findresource begin\n12 dict begin\nbegincmap\n" +
+		"/CIDSystemInfo << /Registry (Adobe) /Ordering (UCS) /Supplement 0 >> def\n" +
+		"/CMapName /Adobe-Identity-UCS def\n/CMapType 2 def\n" +
+		"1 begincodespacerange\n<0000> <FFFF>\nendcodespacerange\n")
+
+	glyphs := slices.DeleteFunc(d.glyphs(font), func(g uint16) bool { return g == 0 })
+	for len(glyphs) > 0 {
+		chunk := glyphs[:min(len(glyphs), 100)]
+		glyphs = glyphs[len(chunk):]
+		fmt.Fprintf(&b, "%d beginbfchar\n", len(chunk))
+		for _, g := range chunk {
+			fmt.Fprintf(&b, "<%04X> <", g)
+			for _, unit := range utf16.Encode([]rune{d.used[font][g]}) {
+				fmt.Fprintf(&b, "%04X", unit)
+			}
+			b.WriteString(">\n")
+		}
+		b.WriteString("endbfchar\n")
+	}
+
+	b.WriteString("endcmap\nCMapName currentdict /CMap defineresource pop\nend\nend\n")
+	return b.String()
+}
+
+// pdfTextWidth returns width of s in points
+func pdfTextWidth(s string, size float64, bold bool) float64 {
+	return pdfFonts[pdfFont(bold)].width(s, size)
+}
+
+// pdfTruncate shortens s with "..." to fit into width points
+func pdfTruncate(s string, size float64, bold bool, width float64) string {
+	if pdfTextWidth(s, size, bold) <= width {
+		return s
+	}
+	text := []rune(s)
+	for len(text) > 0 && pdfTextWidth(string(text)+"...", size, bold) > width {
+		text = text[:len(text)-1]
+	}
+	return string(text) + "..."
+}
+
+// printPDF lays out the document on A4 pages repeating the table header on
+// every page. Number columns are columnWidth wide from the right margin
+func printPDF(p printDocument) []byte {
+	const (
+		rowHeight   = 18
+		columnWidth = 85
+		textX       = pdfMargin + 30
+		right       = pdfPageWidth - pdfMargin
+	)
+	// columnX returns right edge of number column i
+	columnX := func(i int) float64 {
+		return float64(right - (len(p.Columns)-1-i)*columnWidth)
+	}
+
+	doc := &pdfDocument{}
+	doc.addPage()
+	y := float64(pdfPageHeight - pdfMargin)
+
+	doc.text(pdfMargin, y, 18, true, p.Heading)
+	y -= 30
+	for _, info := range p.Info {
+		doc.text(pdfMargin, y, 11, false, info[0])
+		doc.text(pdfMargin+80, y, 11, false, info[1])
+		y -= 16
+	}
+
+	// row draws cells with text cut before the first number
+	row := func(cells []string, bold bool) {
+		for i, cell := range cells {
+			switch {
+			case cell == "":
+			case i == 0:
+				doc.text(pdfMargin, y, 11, bold, cell)
+			case i == 1:
+				width := float64(right - textX)
+				if len(p.Columns) > 2 {
+					width = columnX(2) - 10 - textX
+					if len(cells) > 2 {
+						width -= pdfTextWidth(cells[2], 11, bold)
+					}
+				}
+				doc.text(textX, y, 11, bold, pdfTruncate(cell, 11, bold, width))
+			default:
+				doc.textRight(columnX(i), y, 11, bold, cell)
+			}
+		}
+	}
+
+	header := func() {
+		y -= 10
+		row(p.Columns, true)
+		y -= 6
+		doc.line(pdfMargin, y, right, y)
+		y -= rowHeight - 4
+	}
+	header()
+
+	if len(p.Rows) == 0 && p.Empty != "" {
+		doc.text(textX, y, 11, false, p.Empty)
+		y -= rowHeight
+	}
+	for _, cells := range p.Rows {
+		if y < pdfMargin+rowHeight {
+			doc.addPage()
+			y = pdfPageHeight - pdfMargin
+			header()
+		}
+		row(cells, false)
+		y -= rowHeight
+	}
+
+	doc.line(pdfMargin, y+rowHeight-5, right, y+rowHeight-5)
+	row(p.Total, true)
+
+	// signatures block stays on one page
+	if len(p.Signatures) > 0 {
+		if y < pdfMargin+120 {
+			doc.addPage()
+			y = pdfPageHeight - pdfMargin
+		}
+		y -= 80
+		const gap = 40
+		width := (float64(right-pdfMargin) - gap*float64(len(p.Signatures)-1)) / float64(len(p.Signatures))
+		for i, label := range p.Signatures {
+			x := pdfMargin + float64(i)*(width+gap)
+			doc.line(x, y, x+width, y)
+			doc.text(x, y-14, 9, false, label)
+		}
+	}
+
+	if p.Footer != "" {
+		doc.text(pdfMargin, pdfMargin-20, 8, false, p.Footer)
+	}
+
+	return doc.bytes()
+}
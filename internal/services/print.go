@@ -0,0 +1,81 @@
+package services
+
+import (
+	"bytes"
+	"html/template"
+)
+
+// printDocument is the layout shared by printable timesheets and invoices:
+// a title with info lines and a table with a total row. Cells of the first
+// two columns are a row number and text, the other columns are right aligned
+// numbers. It is rendered to HTML by printHTML and to PDF by printPDF
+type printDocument struct {
+	Title      string // of the HTML page
+	Heading    string
+	Info       [][2]string // label and value lines under the heading
+	Columns    []string
+	Rows       [][]string
+	Empty      string // printed instead of rows if there are none
+	Total      []string
+	Signatures []string // labels of signature lines after the table
+	Footer     string
+}
+
+// printHTML renders the document as a page with print styles
+func printHTML(doc printDocument) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := printTemplate.Execute(&buf, doc); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+var printTemplate = template.Must(template.New("print").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: Arial, Helvetica, sans-serif; font-size: 12pt; margin: 2em; color: #000; }
+h1 { font-size: 18pt; margin-bottom: 0.2em; }
+table { border-collapse: collapse; width: 100%; margin-top: 1em; }
+th, td { border: 1px solid #888; padding: 4px 8px; text-align: left; }
+td.num, th.num { text-align: right; white-space: nowrap; }
+tfoot td { font-weight: bold; }
+.info td { border: none; padding: 2px 8px 2px 0; }
+.signatures { display: flex; gap: 4em; margin-top: 4em; }
+.signature { flex: 1; }
+.signature .line { border-bottom: 1px solid #000; height: 3em; }
+.signature .label { font-size: 10pt; margin-top: 0.3em; }
+.footer { font-size: 9pt; margin-top: 3em; }
+.print { margin-bottom: 1em; }
+@page { size: A4; margin: 15mm; }
+@media print {
+	body { margin: 0; font-size: 11pt; }
+	.print { display: none; }
+	tr { page-break-inside: avoid; }
+	.signatures { page-break-inside: avoid; }
+}
+</style>
+</head>
+<body>
+<button class="print" onclick="window.print()">Print</button>
+<h1>{{.Heading}}</h1>
+<table class="info">
+{{range .Info}}<tr><td>{{index . 0}}</td><td>{{index . 1}}</td></tr>
+{{end}}</table>
+<table>
+<thead><tr>{{range $i, $c := .Columns}}<th{{if ge $i 2}} class="num"{{end}}>{{$c}}</th>{{end}}</tr></thead>
+<tbody>
+{{range .Rows}}<tr>{{range $i, $c := .}}<td{{if ge $i 2}} class="num"{{end}}>{{$c}}</td>{{end}}</tr>
+{{else}}{{with .Empty}}<tr><td colspan="{{len $.Columns}}">{{.}}</td></tr>
+{{end}}{{end}}</tbody>
+<tfoot><tr>{{range $i, $c := .Total}}<td{{if ge $i 2}} class="num"{{end}}>{{$c}}</td>{{end}}</tr></tfoot>
+</table>
+{{with .Signatures}}<div class="signatures">
+{{range .}}<div class="signature"><div class="line"></div><div class="label">{{.}}</div></div>
+{{end}}</div>
+{{end}}{{with .Footer}}<p class="footer">{{.}}</p>
+{{end}}</body>
+</html>
+`))
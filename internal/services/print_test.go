@@ -0,0 +1,139 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func testPrintDocument(rows int) printDocument {
+	doc := printDocument{
+		Title:      "Report <title>",
+		Heading:    "Report",
+		Info:       [][2]string{{"Employee:", "Иванов Иван"}},
+		Columns:    []string{"#", "Task", "Time", "Hours"},
+		Empty:      "No time recorded",
+		Total:      []string{"", "Total", "3:00", "3.00"},
+		Signatures: []string{"Employee signature / date", "Approved by"},
+		Footer:     "Generated 2024-08-01",
+	}
+	for i := 0; i < rows; i++ {
+		doc.Rows = append(doc.Rows, []string{fmt.Sprint(i + 1), fmt.Sprintf("<task %d>", i), "1:30", "1.50"})
+	}
+	return doc
+}
+
+func TestPrintHTML(t *testing.T) {
+	tests := []struct {
+		name     string
+		doc      printDocument
+		expected []string
+		missing  []string
+	}{
+		{"rows", testPrintDocument(2), []string{"<title>Report &lt;title&gt;</title>", "Иванов Иван", "&lt;task 1&gt;",
+			`<td class="num">1:30</td>`, `<td class="num">3.00</td>`, "@media print", "Employee signature", "Generated 2024-08-01"},
+			[]string{"No time recorded"}},
+		{"no rows", testPrintDocument(0), []string{`<td colspan="4">No time recorded</td>`}, nil},
+		{"no signatures and footer", printDocument{Columns: []string{"#", "Item"}}, nil, []string{`class="signatures"`, `class="footer"`}},
+	}
+
+	for _, test := range tests {
+		html, err := printHTML(test.doc)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", test.name, err)
+		}
+		for _, s := range test.expected {
+			if !bytes.Contains(html, []byte(s)) {
+				t.Errorf("%s: html does not contain %q", test.name, s)
+			}
+		}
+		for _, s := range test.missing {
+			if bytes.Contains(html, []byte(s)) {
+				t.Errorf("%s: html contains %q", test.name, s)
+			}
+		}
+	}
+}
+
+func TestPrintPDF(t *testing.T) {
+	pdf := printPDF(testPrintDocument(60))
+
+	if !bytes.HasPrefix(pdf, []byte("%PDF-1.4")) || !bytes.HasSuffix(pdf, []byte("%%EOF\n")) {
+		t.Fatalf("not a PDF document")
+	}
+	for _, s := range []string{pdfGlyphs("Report", true), pdfGlyphs("Иванов Иван", false), pdfGlyphs("<task 59>", false), pdfGlyphs("Approved by", false)} {
+		if !bytes.Contains(pdf, []byte(s)) {
+			t.Errorf("pdf does not contain %q", s)
+		}
+	}
+	if !bytes.Contains(pdf, []byte("/Count 2")) {
+		t.Errorf("expected 60 rows to take 2 pages")
+	}
+	if headers := bytes.Count(pdf, []byte(pdfGlyphs("Hours", true))); headers != 2 {
+		t.Errorf("table header is drawn %d times; expected on both pages", headers)
+	}
+
+	startxref := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(pdf)
+	if startxref == nil {
+		t.Fatalf("no startxref")
+	}
+	xref, _ := strconv.Atoi(string(startxref[1]))
+	if !bytes.HasPrefix(pdf[xref:], []byte("xref\n")) {
+		t.Fatalf("startxref does not point to xref table")
+	}
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(pdf[xref:], -1)
+	for i, e := range entries {
+		offset, _ := strconv.Atoi(string(e[1]))
+		if prefix := fmt.Sprintf("%d 0 obj", i+1); !bytes.HasPrefix(pdf[offset:], []byte(prefix)) {
+			t.Errorf("xref entry %d does not point to %q", i+1, prefix)
+		}
+	}
+}
+
+// pdfGlyphs returns s as drawn by pdfDocument
+func pdfGlyphs(s string, bold bool) string {
+	var glyphs strings.Builder
+	for _, r := range s {
+		fmt.Fprintf(&glyphs, "%04X", pdfFonts[pdfFont(bold)].glyph(r))
+	}
+	return "<" + glyphs.String() + ">"
+}
+
+func TestPDFFontsCoverCyrillic(t *testing.T) {
+	for _, font := range pdfFonts {
+		for _, r := range "АБВГДЕЁЖЗИЙКЛМНОПРСТУФХЦЧШЩЪЫЬЭЮЯабвгдеёжзийклмнопрстуфхцчшщъыьэюяé№" {
+			if font.glyph(r) == 0 {
+				t.Errorf("%s has no glyph of %q", font.name, r)
+			}
+		}
+	}
+}
+
+func TestPDFTruncate(t *testing.T) {
+	long := strings.Repeat("Широкая задача ", 10)
+	tests := []struct {
+		s        string
+		width    float64
+		expected string
+	}{
+		{"Review", 100, "Review"},
+		{"", 100, ""},
+		{"Review", pdfTextWidth("Review", 11, false), "Review"},
+		{"Review", pdfTextWidth("Rev...", 11, false), "Rev..."},
+	}
+	for _, test := range tests {
+		if result := pdfTruncate(test.s, 11, false, test.width); result != test.expected {
+			t.Errorf("pdfTruncate(%q, %g) = %q; expected %q", test.s, test.width, result, test.expected)
+		}
+	}
+
+	for _, bold := range []bool{false, true} {
+		result := pdfTruncate(long, 11, bold, 200)
+		if width := pdfTextWidth(result, 11, bold); width > 200 || !strings.HasSuffix(result, "...") {
+			t.Errorf("pdfTruncate(long, bold %v) = %q of width %g; expected at most 200 with ellipsis", bold, result, width)
+		}
+	}
+}
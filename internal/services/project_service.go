@@ -0,0 +1,111 @@
+package services
+
+import (
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/moxicom/user_test/internal/models"
+	"github.com/moxicom/user_test/internal/storage"
+)
+
+type ProjectService struct {
+	s        storage.Storage
+	log      *slog.Logger
+	rounding Rounding
+}
+
+func newProjectService(s storage.Storage, log *slog.Logger, rounding Rounding) *ProjectService {
+	return &ProjectService{s, log, rounding}
+}
+
+func (s *ProjectService) CreateProject(project models.Project) (uint, error) {
+	if negativeBudget(project.BudgetHours) || negativeBudget(project.BudgetAmount) {
+		return 0, ErrNegativeBudget
+	}
+	if project.BudgetHours != nil && *project.BudgetHours == 0 {
+		project.BudgetHours = nil
+	}
+	if project.BudgetAmount != nil && *project.BudgetAmount == 0 {
+		project.BudgetAmount = nil
+	}
+	project.CreatedAt = time.Now()
+	return s.s.CreateProject(project)
+}
+
+func (s *ProjectService) GetProjects(f models.ProjectFilters) ([]models.Project, error) {
+	return s.s.GetProjects(f)
+}
+
+func (s *ProjectService) GetProject(projectID uint) (models.Project, error) {
+	return s.s.GetProject(projectID)
+}
+
+func (s *ProjectService) UpdateProject(projectID uint, f models.ProjectFilters) error {
+	if negativeBudget(f.BudgetHours) || negativeBudget(f.BudgetAmount) {
+		return ErrNegativeBudget
+	}
+	return s.s.UpdateProject(projectID, f)
+}
+
+func (s *ProjectService) DeleteProject(projectID uint) error {
+	return s.s.DeleteProject(projectID)
+}
+
+// GetUserProjects aggregates user tasks within the range by project.
+// Tasks without project are grouped under empty project
+func (s *ProjectService) GetUserProjects(userID uint, startTime, endTime time.Time) ([]models.ProjectWithTotalTime, error) {
+	tasks, err := s.s.GetUserTasks(userID, startTime, endTime, models.TaskFilters{})
+	if err != nil {
+		return nil, err
+	}
+
+	projects, err := s.s.GetProjects(models.ProjectFilters{})
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[uint]string, len(projects))
+	for _, p := range projects {
+		names[p.ID] = p.Name
+	}
+
+	type total struct {
+		projectID *uint
+		tasks     int
+		seconds   int64
+		money     models.Money
+	}
+	totals := make(map[uint]*total)
+	for _, task := range tasks {
+		var key uint
+		if task.ProjectID != nil {
+			key = *task.ProjectID
+		}
+		if _, ok := totals[key]; !ok {
+			totals[key] = &total{projectID: task.ProjectID}
+		}
+		totals[key].tasks++
+		totals[key].seconds += s.rounding.Round(task.TotalSeconds)
+		totals[key].money = addMoney(totals[key].money, task.Money)
+	}
+
+	result := make([]models.ProjectWithTotalTime, 0, len(totals))
+	for key, t := range totals {
+		result = append(result, models.ProjectWithTotalTime{
+			ProjectID:   t.projectID,
+			ProjectName: names[key],
+			TasksCount:  t.tasks,
+			Duration:    newDuration(t.seconds),
+			Money:       t.money,
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].TotalSeconds > result[j].TotalSeconds
+	})
+
+	return result, nil
+}
+
+func negativeBudget[T float64 | models.Cents](budget *T) bool {
+	return budget != nil && *budget < 0
+}
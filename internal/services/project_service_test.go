@@ -0,0 +1,49 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/moxicom/user_test/internal/models"
+)
+
+func TestGetUserProjectsGroupsTasks(t *testing.T) {
+	web, api := uint(1), uint(2)
+	s := &fakeStorage{
+		projects: []models.Project{{ID: web, Name: "web"}, {ID: api, Name: "api"}},
+		tasks: map[uint][]models.TaskWithTotalTime{
+			5: {
+				trackedTask(10, &web, 3600+25*60),
+				trackedTask(11, &web, 3600+10*60),
+				trackedTask(12, nil, 20*60),
+				trackedTask(13, &api, 5*60),
+			},
+		},
+	}
+	service := newProjectService(s, discardLogger(), Rounding{Minutes: 15, Mode: RoundNearest})
+
+	result, err := service.GetUserProjects(5, time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(result) != 3 {
+		t.Fatalf("expected 3 projects, got %+v", result)
+	}
+
+	// each task is rounded before totals: 1h30m + 1h15m
+	first := result[0]
+	if first.ProjectName != "web" || first.TasksCount != 2 || first.TotalSeconds != 2*3600+45*60 {
+		t.Errorf("first project = %+v; expected web with 2 tasks and 2h45m", first)
+	}
+	if first.DurationHours != 2 || first.DurationMinutes != 45 || first.DurationSeconds != 0 {
+		t.Errorf("first project duration = %dh %dm %ds; expected 2h 45m 0s", first.DurationHours, first.DurationMinutes, first.DurationSeconds)
+	}
+
+	if result[1].ProjectID != nil || result[1].ProjectName != "" || result[1].TotalSeconds != 15*60 {
+		t.Errorf("second project = %+v; expected tasks without project with 15m", result[1])
+	}
+	if result[2].ProjectName != "api" || result[2].TotalSeconds != 0 {
+		t.Errorf("third project = %+v; expected api rounded to zero", result[2])
+	}
+}
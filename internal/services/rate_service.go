@@ -0,0 +1,68 @@
+package services
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/moxicom/user_test/internal/models"
+	"github.com/moxicom/user_test/internal/storage"
+)
+
+type RateService struct {
+	s   storage.Storage
+	log *slog.Logger
+}
+
+func newRateService(s storage.Storage, log *slog.Logger) *RateService {
+	return &RateService{s, log}
+}
+
+// CreateRate sets hourly rate of a user, project or task from the date of
+// rate.EffectiveFrom. Rate applies from UTC midnight of that date
+func (s *RateService) CreateRate(rate models.Rate) (uint, error) {
+	if err := validateRate(rate); err != nil {
+		return 0, err
+	}
+
+	var err error
+	switch {
+	case rate.UserID != nil:
+		_, err = s.s.GetUser(*rate.UserID)
+	case rate.ProjectID != nil:
+		_, err = s.s.GetProject(*rate.ProjectID)
+	case rate.TaskID != nil:
+		_, err = s.s.GetTask(*rate.TaskID)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	y, m, d := rate.EffectiveFrom.Date()
+	rate.EffectiveFrom = time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	return s.s.CreateRate(rate)
+}
+
+func (s *RateService) GetRates(filters models.RateFilters) ([]models.Rate, error) {
+	return s.s.GetRates(filters)
+}
+
+func (s *RateService) DeleteRate(rateID uint) error {
+	return s.s.DeleteRate(rateID)
+}
+
+// validateRate checks that rate has exactly one target and is not negative
+func validateRate(rate models.Rate) error {
+	targets := 0
+	for _, id := range []*uint{rate.UserID, rate.ProjectID, rate.TaskID} {
+		if id != nil {
+			targets++
+		}
+	}
+	if targets != 1 {
+		return ErrInvalidRateTarget
+	}
+	if rate.HourlyRate < 0 {
+		return ErrNegativeRate
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/moxicom/user_test/internal/models"
+)
+
+func TestValidateRate(t *testing.T) {
+	id := uint(1)
+	tests := []struct {
+		name     string
+		rate     models.Rate
+		expected error
+	}{
+		{"user rate", models.Rate{UserID: &id, HourlyRate: 2500}, nil},
+		{"free task", models.Rate{TaskID: &id}, nil},
+		{"no target", models.Rate{HourlyRate: 2500}, ErrInvalidRateTarget},
+		{"two targets", models.Rate{UserID: &id, ProjectID: &id, HourlyRate: 2500}, ErrInvalidRateTarget},
+		{"negative", models.Rate{ProjectID: &id, HourlyRate: -100}, ErrNegativeRate},
+	}
+
+	for _, tt := range tests {
+		if err := validateRate(tt.rate); !errors.Is(err, tt.expected) {
+			t.Errorf("%s: err = %v; expected %v", tt.name, err, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package services
+
+import (
+	"log/slog"
+	"sort"
+
+	"github.com/moxicom/user_test/internal/models"
+	"github.com/moxicom/user_test/internal/storage"
+)
+
+type ReportService struct {
+	s        storage.Storage
+	log      *slog.Logger
+	rounding Rounding
+}
+
+func newReportService(s storage.Storage, log *slog.Logger, rounding Rounding) *ReportService {
+	return &ReportService{s, log, rounding}
+}
+
+// GetTimeReport returns time worked within the range by all users (or team
+// members) grouped by user, task or project. Rows are sorted by time, longest
+// first unless f.Asc, and cut to f.Limit if it is set
+func (s *ReportService) GetTimeReport(f models.TimeReportFilters) ([]models.TimeReportRow, error) {
+	switch f.GroupBy {
+	case models.ReportByUser, models.ReportByTask, models.ReportByProject:
+	default:
+		return nil, ErrInvalidReportGroupBy
+	}
+	if !f.From.Before(f.To) {
+		return nil, ErrInvalidRange
+	}
+
+	totals, err := s.s.GetTaskTotals(f.From, f.To, f.TeamID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := aggregateReport(totals, f.GroupBy, s.rounding)
+	sort.SliceStable(rows, func(i, j int) bool {
+		if f.Asc {
+			return rows[i].TotalSeconds < rows[j].TotalSeconds
+		}
+		return rows[i].TotalSeconds > rows[j].TotalSeconds
+	})
+	if f.Limit > 0 && len(rows) > f.Limit {
+		rows = rows[:f.Limit]
+	}
+
+	return rows, nil
+}
+
+// aggregateReport groups rounded task totals. Rows come in order of first
+// appearance of the group
+func aggregateReport(totals []models.TaskTotal, groupBy models.ReportGroupBy, rounding Rounding) []models.TimeReportRow {
+	type group struct {
+		row     models.TimeReportRow
+		seconds int64
+		users   map[uint]struct{}
+	}
+
+	groups := make(map[uint]*group)
+	order := make([]uint, 0)
+	for _, t := range totals {
+		var key uint
+		var id *uint
+		var name string
+		switch groupBy {
+		case models.ReportByUser:
+			key, id, name = t.UserID, &t.UserID, t.Surname+" "+t.Name
+		case models.ReportByTask:
+			key, id, name = t.TaskID, &t.TaskID, t.TaskName
+		case models.ReportByProject:
+			if t.ProjectID != nil {
+				key = *t.ProjectID
+			}
+			id, name = t.ProjectID, t.ProjectName
+		}
+
+		g, ok := groups[key]
+		if !ok {
+			g = &group{row: models.TimeReportRow{ID: id, Name: name}, users: make(map[uint]struct{})}
+			groups[key] = g
+			order = append(order, key)
+		}
+		g.row.TasksCount++
+		g.users[t.UserID] = struct{}{}
+		g.seconds += rounding.Round(t.TotalSeconds)
+		g.row.Money = addMoney(g.row.Money, t.Money)
+	}
+
+	rows := make([]models.TimeReportRow, 0, len(order))
+	for _, key := range order {
+		g := groups[key]
+		g.row.UsersCount = len(g.users)
+		g.row.Duration = newDuration(g.seconds)
+		rows = append(rows, g.row)
+	}
+
+	return rows
+}
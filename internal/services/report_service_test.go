@@ -0,0 +1,34 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/moxicom/user_test/internal/models"
+)
+
+func TestAggregateReport(t *testing.T) {
+	project := uint(7)
+	totals := []models.TaskTotal{
+		{TaskID: 1, TaskName: "a", UserID: 1, Surname: "Ivanov", Name: "Ivan", ProjectID: &project, ProjectName: "site", TotalSeconds: 100},
+		{TaskID: 2, TaskName: "b", UserID: 2, Surname: "Petrov", Name: "Petr", ProjectID: &project, ProjectName: "site", TotalSeconds: 200},
+		{TaskID: 3, TaskName: "c", UserID: 1, Surname: "Ivanov", Name: "Ivan", TotalSeconds: 400},
+	}
+
+	byUser := aggregateReport(totals, models.ReportByUser, Rounding{})
+	if len(byUser) != 2 || byUser[0].Name != "Ivanov Ivan" || byUser[0].TotalSeconds != 500 || byUser[0].TasksCount != 2 {
+		t.Errorf("by user = %+v; expected Ivanov Ivan with 500 seconds in 2 tasks first", byUser)
+	}
+
+	byProject := aggregateReport(totals, models.ReportByProject, Rounding{})
+	if len(byProject) != 2 || *byProject[0].ID != project || byProject[0].UsersCount != 2 || byProject[0].TotalSeconds != 300 {
+		t.Errorf("by project = %+v; expected project 7 with 2 users and 300 seconds first", byProject)
+	}
+	if byProject[1].ID != nil || byProject[1].TotalSeconds != 400 {
+		t.Errorf("by project = %+v; expected tasks without project second", byProject)
+	}
+
+	rounded := aggregateReport(totals, models.ReportByProject, Rounding{Minutes: 5, Mode: RoundUp})
+	if rounded[0].TotalSeconds != 600 {
+		t.Errorf("rounded project total = %d; expected sum of rounded tasks %d", rounded[0].TotalSeconds, 600)
+	}
+}
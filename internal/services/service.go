@@ -1,6 +1,8 @@
 package services
 
 import (
+	"fmt"
+	"io"
 	"log/slog"
 	"time"
 
@@ -8,30 +10,165 @@ import (
 	"github.com/moxicom/user_test/internal/storage"
 )
 
+var (
+	ErrUserNotActive           = fmt.Errorf("user is not active")
+	ErrInvalidStatusTransition = fmt.Errorf("invalid user status transition")
+	ErrFutureEffectiveDate     = fmt.Errorf("effective date can not be in the future")
+	ErrInvalidTeamRole         = fmt.Errorf("invalid team role")
+	ErrEmptyTaskName           = fmt.Errorf("task name can not be empty")
+	ErrInvalidTaskTransition   = fmt.Errorf("task status transition is not allowed")
+	ErrTaskClosed              = fmt.Errorf("task is done or archived")
+	ErrInvalidPeriod           = fmt.Errorf("period start should be before end")
+	ErrPeriodInFuture          = fmt.Errorf("period can not be in the future")
+	ErrInvalidGroupBy          = fmt.Errorf("group_by should be day, week or month")
+	ErrInvalidRange            = fmt.Errorf("range start should be before end")
+	ErrInvalidTimeZone         = fmt.Errorf("invalid time zone")
+	ErrInvalidReportGroupBy    = fmt.Errorf("group_by should be user, task or project")
+	ErrInvalidCalendar         = fmt.Errorf("invalid iCalendar file")
+	ErrInvalidCSV              = fmt.Errorf("invalid CSV, expected Toggl or Clockify detailed report")
+	ErrInvalidCSVFormat        = fmt.Errorf("format should be toggl or clockify")
+	ErrWeekNotOver             = fmt.Errorf("week is not over yet")
+	ErrNotManager              = fmt.Errorf("reviewer does not manage the user")
+	ErrEmptyComment            = fmt.Errorf("comment is required to reject timesheet")
+	ErrInvalidSubmissionStatus = fmt.Errorf("status should be submitted, approved or rejected")
+	ErrInvalidRateTarget       = fmt.Errorf("rate should have exactly one of user_id, project_id and task_id")
+	ErrNegativeRate            = fmt.Errorf("hourly rate can not be negative")
+	ErrInvalidInvoiceTarget    = fmt.Errorf("invoice should have exactly one of project_id and client")
+	ErrNothingToInvoice        = fmt.Errorf("no unbilled billable time in the range")
+	ErrNegativeEstimate        = fmt.Errorf("estimate can not be negative")
+	ErrNegativeBudget          = fmt.Errorf("budget can not be negative")
+	ErrRangeTooLong            = fmt.Errorf("range is too long")
+)
+
 type User interface {
 	GetUsers(models.UserFilters) ([]models.User, error)
 	GetUserTasks(uint, time.Time, time.Time, models.TaskFilters) ([]models.TaskWithTotalTime, error)
 	CreateUser(string) (uint, error)
 	DeleteUser(uint) error
 	UpdateUser(uint, models.UserFilters) error
+	SuspendUser(uint, time.Time) error
+	ReactivateUser(uint, time.Time) error
+	TerminateUser(uint, time.Time) error
+	GetUserTags(uint, time.Time, time.Time) ([]models.TagWithTotalTime, error)
+	GetUserSummary(uint, time.Time, time.Time, models.GroupBy, *time.Location) ([]models.SummaryBucket, error)
+	GetUserLocation(userID uint, tz string) (*time.Location, error)
+	GetUserCalendar(uint, time.Time, time.Time) ([]byte, error)
+	CreateCalendarToken(uint) (string, error)
+	GetCalendarFeed(token string) ([]byte, error)
+	ImportCalendar(uint, []io.Reader, models.CalendarImportRules, *time.Location) (models.ImportResult, error)
+	GetUserTimesheet(uint, time.Time, time.Time, *time.Location) (models.Timesheet, error)
+	GetUserTimesheetXLSX(userID uint, day time.Time, loc *time.Location) ([]byte, error)
+	GetUserTimesheetHTML(uint, time.Time, time.Time, *time.Location) ([]byte, error)
+	GetUserTimesheetPDF(uint, time.Time, time.Time, *time.Location) ([]byte, error)
 }
 
 type Task interface {
 	CreateTask(models.Task) (uint, error)
+	GetTasks(models.TaskFilters) ([]models.Task, error)
+	UpdateTask(uint, models.TaskUpdate) error
+	GetTags() ([]models.Tag, error)
 	FinishTask(uint) error
+	ReopenTask(uint) error
+	ArchiveTask(uint) error
 	DeleteTask(uint) error
 	StartPeriod(uint) error
 	EndPeriod(uint) error
+	SwitchTask(uint) error
+	GetRunningTimers(userID uint) ([]models.RunningPeriod, error)
+	GetTaskPeriods(uint) ([]models.TaskPeriod, error)
+	AddPeriod(taskID uint, startTime time.Time, endTime time.Time, billable bool) (uint, error)
+	UpdatePeriod(periodID uint, startTime time.Time, endTime time.Time) error
+	DeletePeriod(uint) error
+	SetPeriodBillable(periodID uint, billable bool) error
+	GetTaskAlerts(models.AlertFilters) ([]models.TaskAlert, error)
+}
+
+type Team interface {
+	CreateTeam(models.Team) (uint, error)
+	GetTeams() ([]models.Team, error)
+	DeleteTeam(uint) error
+	AddTeamMember(models.TeamMember) error
+	RemoveTeamMember(teamID uint, userID uint) error
+	GetTeamMembers(uint) ([]models.TeamMemberInfo, error)
+	GetTeamUsers(uint, models.UserFilters) ([]models.User, error)
+	GetTeamTasks(uint, time.Time, time.Time, models.TaskFilters) ([]models.UserWithTasks, error)
+	GetManagedUsers(uint) ([]models.User, error)
+}
+
+type Project interface {
+	CreateProject(models.Project) (uint, error)
+	GetProjects(models.ProjectFilters) ([]models.Project, error)
+	GetProject(uint) (models.Project, error)
+	UpdateProject(uint, models.ProjectFilters) error
+	DeleteProject(uint) error
+	GetUserProjects(uint, time.Time, time.Time) ([]models.ProjectWithTotalTime, error)
+	GetProjectBurndown(projectID uint, from, to time.Time, loc *time.Location) (models.Burndown, error)
+}
+
+type Rate interface {
+	CreateRate(models.Rate) (uint, error)
+	GetRates(models.RateFilters) ([]models.Rate, error)
+	DeleteRate(uint) error
+}
+
+type Report interface {
+	GetTimeReport(models.TimeReportFilters) ([]models.TimeReportRow, error)
+}
+
+type Import interface {
+	ImportTimeEntries(io.Reader, models.CSVImportOptions) (models.CSVImportResult, error)
+}
+
+type Approval interface {
+	SubmitTimesheet(userID uint, day time.Time, loc *time.Location) (uint, error)
+	GetSubmissions(models.SubmissionFilters) ([]models.TimesheetSubmission, error)
+	ApproveTimesheet(submissionID uint, reviewerID uint, comment string) error
+	RejectTimesheet(submissionID uint, reviewerID uint, comment string) error
+}
+
+type Invoice interface {
+	CreateInvoice(models.InvoiceSelection, *time.Location) (models.Invoice, error)
+	GetInvoices(models.InvoiceFilters) ([]models.Invoice, error)
+	GetInvoice(uint) (models.Invoice, error)
+	GetInvoiceHTML(uint) ([]byte, error)
+	GetInvoicePDF(uint) ([]byte, error)
+	DeleteInvoice(uint) error
 }
 
 type Service struct {
 	Task
 	User
+	Team
+	Project
+	Report
+	Import
+	Approval
+	Rate
+	Invoice
 }
 
-func New(s storage.Storage, log *slog.Logger) *Service {
+func New(s storage.Storage, log *slog.Logger, rounding Rounding, invoicing InvoiceConfig) *Service {
 	return &Service{
-		User: newUserService(s, log),
-		Task: newTaskService(s, log),
+		User:     newUserService(s, log, rounding),
+		Task:     newTaskService(s, log),
+		Team:     newTeamService(s, log, rounding),
+		Project:  newProjectService(s, log, rounding),
+		Report:   newReportService(s, log, rounding),
+		Import:   newImportService(s, log),
+		Approval: newApprovalService(s, log),
+		Rate:     newRateService(s, log),
+		Invoice:  newInvoiceService(s, log, invoicing),
+	}
+}
+
+// loadLocation returns the named time zone, server time zone for empty name
+func loadLocation(name string) (*time.Location, error) {
+	if name == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, ErrInvalidTimeZone
 	}
+	return loc, nil
 }
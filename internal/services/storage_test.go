@@ -0,0 +1,125 @@
+package services
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/moxicom/user_test/internal/models"
+	"github.com/moxicom/user_test/internal/storage"
+)
+
+// fakeStorage keeps data of service tests in memory. Methods not overridden
+// panic through the nil embedded interface
+type fakeStorage struct {
+	storage.Storage
+	users     []models.User
+	members   map[uint][]uint // team ID to user IDs
+	tasks     map[uint][]models.TaskWithTotalTime
+	projects  []models.Project
+	added     []models.TeamMember
+	updates   map[uint]models.TaskUpdate
+	taskList  []models.Task
+	switched  []uint
+	imported  []models.ImportEntry
+	estimated []models.EstimatedTask
+	alerts    []models.TaskAlert
+}
+
+func (f *fakeStorage) GetUser(userID uint) (models.User, error) {
+	for _, user := range f.users {
+		if user.ID == userID {
+			return user, nil
+		}
+	}
+	return models.User{}, storage.ErrUserNotFound
+}
+
+func (f *fakeStorage) GetUsers(filters models.UserFilters) ([]models.User, error) {
+	if filters.TeamID == 0 {
+		return f.users, nil
+	}
+	var users []models.User
+	for _, id := range f.members[filters.TeamID] {
+		user, err := f.GetUser(id)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, nil
+}
+
+// GetUserTasks returns copies so services may change them
+func (f *fakeStorage) GetUserTasks(userID uint, _, _ time.Time, _ models.TaskFilters) ([]models.TaskWithTotalTime, error) {
+	return append([]models.TaskWithTotalTime{}, f.tasks[userID]...), nil
+}
+
+func (f *fakeStorage) GetProjects(models.ProjectFilters) ([]models.Project, error) {
+	return f.projects, nil
+}
+
+func (f *fakeStorage) AddTeamMember(member models.TeamMember) error {
+	f.added = append(f.added, member)
+	return nil
+}
+
+func (f *fakeStorage) UpdateTask(taskID uint, update models.TaskUpdate) error {
+	if f.updates == nil {
+		f.updates = make(map[uint]models.TaskUpdate)
+	}
+	f.updates[taskID] = update
+	return nil
+}
+
+func (f *fakeStorage) GetTask(taskID uint) (models.Task, error) {
+	for _, task := range f.taskList {
+		if task.ID == taskID {
+			return task, nil
+		}
+	}
+	return models.Task{}, errors.New("task not found")
+}
+
+func (f *fakeStorage) SwitchPeriod(taskID uint, _ time.Time) error {
+	f.switched = append(f.switched, taskID)
+	return nil
+}
+
+func (f *fakeStorage) ImportPeriods(entries []models.ImportEntry, _ bool) (models.ImportResult, error) {
+	f.imported = append(f.imported, entries...)
+	return models.ImportResult{Imported: len(entries)}, nil
+}
+
+func (f *fakeStorage) GetEstimatedTasks() ([]models.EstimatedTask, error) {
+	return f.estimated, nil
+}
+
+// CreateTaskAlerts numbers alerts skipping ones already recorded like the
+// unique index does
+func (f *fakeStorage) CreateTaskAlerts(alerts []models.TaskAlert) error {
+	for i, alert := range alerts {
+		if !slices.ContainsFunc(f.alerts, func(a models.TaskAlert) bool {
+			return a.TaskID == alert.TaskID && a.Threshold == alert.Threshold
+		}) {
+			alerts[i].ID = uint(len(f.alerts) + 1)
+			f.alerts = append(f.alerts, alerts[i])
+		}
+	}
+	return nil
+}
+
+func discardLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func trackedTask(id uint, projectID *uint, seconds int64, tags ...string) models.TaskWithTotalTime {
+	task := models.TaskWithTotalTime{Task: models.Task{ID: id, ProjectID: projectID, TaskName: "task"}}
+	task.TotalSeconds = seconds
+	for _, tag := range tags {
+		task.Tags = append(task.Tags, models.Tag{Name: tag})
+	}
+	return task
+}
@@ -0,0 +1,113 @@
+package services
+
+import (
+	"sort"
+	"time"
+
+	"github.com/moxicom/user_test/internal/models"
+)
+
+// maxRangeDays limits ranges of reports split into days, about ten years
+const maxRangeDays = 3660
+
+// checkRange returns ErrInvalidRange if from is not before to and
+// ErrRangeTooLong if the range is longer than maxRangeDays
+func checkRange(from, to time.Time) error {
+	if !from.Before(to) {
+		return ErrInvalidRange
+	}
+	if to.After(from.AddDate(0, 0, maxRangeDays)) {
+		return ErrRangeTooLong
+	}
+	return nil
+}
+
+// bucketStart returns the beginning of the day, week (from Monday) or month containing t in loc
+func bucketStart(t time.Time, groupBy models.GroupBy, loc *time.Location) time.Time {
+	t = t.In(loc)
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+	switch groupBy {
+	case models.GroupByWeek:
+		offset := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -offset)
+	case models.GroupByMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, loc)
+	default:
+		return day
+	}
+}
+
+// nextBucket returns the beginning of the bucket following the one starting at start.
+// Calendar arithmetic keeps boundaries at local midnight across DST changes
+func nextBucket(start time.Time, groupBy models.GroupBy) time.Time {
+	switch groupBy {
+	case models.GroupByWeek:
+		return start.AddDate(0, 0, 7)
+	case models.GroupByMonth:
+		return start.AddDate(0, 1, 0)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
+
+// summarize splits periods clipped to [from, to) into buckets. Running periods
+// last until now, periods crossing a bucket boundary are divided between buckets
+func summarize(periods []models.ReportPeriod, from, to, now time.Time, groupBy models.GroupBy, loc *time.Location) []models.SummaryBucket {
+	buckets := make([]models.SummaryBucket, 0)
+	for start := bucketStart(from, groupBy, loc); start.Before(to); start = nextBucket(start, groupBy) {
+		buckets = append(buckets, models.SummaryBucket{Start: start, End: nextBucket(start, groupBy)})
+	}
+
+	for i := range buckets {
+		b := &buckets[i]
+		bucketFrom, bucketTo := maxTime(b.Start, from), minTime(b.End, to)
+
+		byTask := make(map[uint]*models.SummaryTask)
+		for _, p := range periods {
+			seconds := clippedSeconds(p.TaskPeriod, bucketFrom, bucketTo, now)
+			if seconds <= 0 {
+				continue
+			}
+			if _, ok := byTask[p.TaskID]; !ok {
+				byTask[p.TaskID] = &models.SummaryTask{TaskID: p.TaskID, TaskName: p.TaskName}
+			}
+			byTask[p.TaskID].TotalSeconds += seconds
+			b.TotalSeconds += seconds
+		}
+
+		b.Tasks = make([]models.SummaryTask, 0, len(byTask))
+		for _, t := range byTask {
+			b.Tasks = append(b.Tasks, *t)
+		}
+		sort.Slice(b.Tasks, func(i, j int) bool {
+			return b.Tasks[i].TotalSeconds > b.Tasks[j].TotalSeconds
+		})
+	}
+
+	return buckets
+}
+
+// clippedSeconds returns whole seconds of the period within [from, to).
+// Running period lasts until now. Periods outside the window give zero
+func clippedSeconds(p models.TaskPeriod, from, to, now time.Time) int64 {
+	end := now
+	if p.EndTime != nil {
+		end = *p.EndTime
+	}
+	seconds := int64(minTime(end, to).Sub(maxTime(*p.StartTime, from)) / time.Second)
+	return max(seconds, 0)
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
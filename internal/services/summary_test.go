@@ -0,0 +1,136 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/moxicom/user_test/internal/models"
+)
+
+func reportPeriod(taskID uint, start, end time.Time) models.ReportPeriod {
+	return models.ReportPeriod{TaskPeriod: models.TaskPeriod{TaskID: taskID, StartTime: &start, EndTime: &end}}
+}
+
+func TestSummarizeSplitsAtMidnight(t *testing.T) {
+	loc := time.UTC
+	from := time.Date(2024, 7, 1, 0, 0, 0, 0, loc)
+	to := time.Date(2024, 7, 3, 0, 0, 0, 0, loc)
+	periods := []models.ReportPeriod{
+		reportPeriod(1, time.Date(2024, 7, 1, 22, 0, 0, 0, loc), time.Date(2024, 7, 2, 1, 0, 0, 0, loc)),
+		reportPeriod(2, time.Date(2024, 7, 2, 9, 0, 0, 0, loc), time.Date(2024, 7, 2, 10, 30, 0, 0, loc)),
+	}
+
+	buckets := summarize(periods, from, to, to, models.GroupByDay, loc)
+
+	if len(buckets) != 2 {
+		t.Fatalf("expected 2 buckets, got %d", len(buckets))
+	}
+	if buckets[0].TotalSeconds != 2*3600 {
+		t.Errorf("first day total = %d; expected %d", buckets[0].TotalSeconds, 2*3600)
+	}
+	if buckets[1].TotalSeconds != 3600+5400 {
+		t.Errorf("second day total = %d; expected %d", buckets[1].TotalSeconds, 3600+5400)
+	}
+	if len(buckets[1].Tasks) != 2 || buckets[1].Tasks[0].TaskID != 2 {
+		t.Errorf("second day tasks = %+v; expected task 2 first", buckets[1].Tasks)
+	}
+}
+
+func TestSummarizeClipsToWindow(t *testing.T) {
+	loc := time.UTC
+	from := time.Date(2024, 7, 1, 12, 0, 0, 0, loc)
+	to := time.Date(2024, 7, 1, 18, 0, 0, 0, loc)
+	periods := []models.ReportPeriod{
+		reportPeriod(1, time.Date(2024, 7, 1, 10, 0, 0, 0, loc), time.Date(2024, 7, 1, 20, 0, 0, 0, loc)),
+	}
+
+	buckets := summarize(periods, from, to, to, models.GroupByDay, loc)
+
+	if len(buckets) != 1 || buckets[0].TotalSeconds != 6*3600 {
+		t.Errorf("buckets = %+v; expected one bucket with 6 hours", buckets)
+	}
+}
+
+func TestBucketStart(t *testing.T) {
+	loc := time.UTC
+	wednesday := time.Date(2024, 7, 3, 15, 4, 5, 0, loc)
+
+	tests := []struct {
+		groupBy  models.GroupBy
+		expected time.Time
+	}{
+		{models.GroupByDay, time.Date(2024, 7, 3, 0, 0, 0, 0, loc)},
+		{models.GroupByWeek, time.Date(2024, 7, 1, 0, 0, 0, 0, loc)},
+		{models.GroupByMonth, time.Date(2024, 7, 1, 0, 0, 0, 0, loc)},
+	}
+
+	for _, test := range tests {
+		result := bucketStart(wednesday, test.groupBy, loc)
+		if !result.Equal(test.expected) {
+			t.Errorf("bucketStart(%v, %q) = %v; expected %v", wednesday, test.groupBy, result, test.expected)
+		}
+	}
+}
+
+func TestClippedSeconds(t *testing.T) {
+	from := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 7, 2, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2024, 7, 1, 18, 0, 0, 0, time.UTC)
+	at := func(hour int) time.Time {
+		return from.Add(time.Duration(hour) * time.Hour)
+	}
+	finished := func(start, end time.Time) models.TaskPeriod {
+		return models.TaskPeriod{StartTime: &start, EndTime: &end}
+	}
+	running := func(start time.Time) models.TaskPeriod {
+		return models.TaskPeriod{StartTime: &start}
+	}
+
+	tests := []struct {
+		name     string
+		period   models.TaskPeriod
+		expected int64
+	}{
+		{"inside", finished(at(9), at(11)), 2 * 3600},
+		{"crosses start", finished(at(-2), at(1)), 3600},
+		{"crosses end", finished(at(23), at(26)), 3600},
+		{"spans window", finished(at(-5), at(30)), 24 * 3600},
+		{"before window", finished(at(-5), at(-1)), 0},
+		{"ends at start", finished(at(-5), at(0)), 0},
+		{"after window", finished(at(24), at(26)), 0},
+		{"running until now", running(at(16)), 2 * 3600},
+		{"running since before window", running(at(-3)), 18 * 3600},
+		{"fraction of second", finished(at(9), at(9).Add(1500*time.Millisecond)), 1},
+	}
+
+	for _, test := range tests {
+		result := clippedSeconds(test.period, from, to, now)
+		if result != test.expected {
+			t.Errorf("%s: clippedSeconds = %d; expected %d", test.name, result, test.expected)
+		}
+	}
+}
+
+func TestCheckRange(t *testing.T) {
+	from := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		to       time.Time
+		expected error
+	}{
+		{from.AddDate(0, 0, 1), nil},
+		{from.AddDate(0, 0, maxRangeDays), nil},
+		{from.AddDate(0, 0, maxRangeDays).Add(time.Second), ErrRangeTooLong},
+		{from.AddDate(50, 0, 0), ErrRangeTooLong},
+		{from, ErrInvalidRange},
+		{from.Add(-time.Hour), ErrInvalidRange},
+	}
+
+	for _, test := range tests {
+		err := checkRange(from, test.to)
+		if !errors.Is(err, test.expected) {
+			t.Errorf("checkRange(%v, %v) = %v; expected %v", from, test.to, err, test.expected)
+		}
+	}
+}
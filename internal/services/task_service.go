@@ -2,6 +2,7 @@ package services
 
 import (
 	"log/slog"
+	"strings"
 	"time"
 
 	"github.com/moxicom/user_test/internal/models"
@@ -18,14 +19,84 @@ func newTaskService(s storage.Storage, log *slog.Logger) *TaskService {
 }
 
 func (s *TaskService) CreateTask(task models.Task) (uint, error) {
+	if err := s.checkUserActive(task.UserID); err != nil {
+		return 0, err
+	}
+
+	if task.Estimate != nil {
+		if *task.Estimate < 0 {
+			return 0, ErrNegativeEstimate
+		}
+		if *task.Estimate == 0 {
+			task.Estimate = nil
+		}
+	}
+
 	task.CreatedAt = time.Now()
-	task.IsFinished = false
+	task.Status = models.TaskTodo
 	return s.s.CreateTask(task)
 }
 
+func (s *TaskService) GetTasks(filters models.TaskFilters) ([]models.Task, error) {
+	return s.s.GetTasks(filters)
+}
+
+func (s *TaskService) UpdateTask(taskID uint, update models.TaskUpdate) error {
+	if update.TaskName != nil && strings.TrimSpace(*update.TaskName) == "" {
+		return ErrEmptyTaskName
+	}
+	if update.Estimate != nil && *update.Estimate < 0 {
+		return ErrNegativeEstimate
+	}
+	return s.s.UpdateTask(taskID, update)
+}
+
+func (s *TaskService) GetTaskAlerts(filters models.AlertFilters) ([]models.TaskAlert, error) {
+	return s.s.GetTaskAlerts(filters)
+}
+
+func (s *TaskService) GetTags() ([]models.Tag, error) {
+	return s.s.GetTags()
+}
+
+// FinishTask marks task done closing its ongoing period
 func (s *TaskService) FinishTask(taskID uint) error {
-	endTime := time.Now()
-	return s.s.FinishTask(taskID, endTime)
+	if err := s.checkTransition(taskID, models.TaskDone); err != nil {
+		return err
+	}
+	return s.s.FinishTask(taskID, time.Now())
+}
+
+// ReopenTask returns done or archived task to work. Task with tracked
+// periods becomes paused, otherwise todo
+func (s *TaskService) ReopenTask(taskID uint) error {
+	task, err := s.s.GetTask(taskID)
+	if err != nil {
+		return err
+	}
+
+	if !task.Status.IsClosed() {
+		return ErrInvalidTaskTransition
+	}
+
+	periods, err := s.s.GetTaskPeriods(taskID)
+	if err != nil {
+		return err
+	}
+
+	status := models.TaskTodo
+	if len(periods) > 0 {
+		status = models.TaskPaused
+	}
+
+	return s.s.SetTaskStatus(taskID, status)
+}
+
+func (s *TaskService) ArchiveTask(taskID uint) error {
+	if err := s.checkTransition(taskID, models.TaskArchived); err != nil {
+		return err
+	}
+	return s.s.SetTaskStatus(taskID, models.TaskArchived)
 }
 
 func (s *TaskService) DeleteTask(taskID uint) error {
@@ -33,9 +104,182 @@ func (s *TaskService) DeleteTask(taskID uint) error {
 }
 
 func (s *TaskService) StartPeriod(taskID uint) error {
+	task, err := s.s.GetTask(taskID)
+	if err != nil {
+		return err
+	}
+
+	if task.Status == models.TaskInProgress {
+		return storage.ErrPeriodNotFinished
+	}
+	if !task.Status.CanTransitionTo(models.TaskInProgress) {
+		return ErrInvalidTaskTransition
+	}
+
+	if err := s.checkUserActive(task.UserID); err != nil {
+		return err
+	}
+
 	return s.s.StartPeriod(taskID, time.Now())
 }
 
 func (s *TaskService) EndPeriod(taskID uint) error {
+	task, err := s.s.GetTask(taskID)
+	if err != nil {
+		return err
+	}
+
+	if task.Status != models.TaskInProgress {
+		return storage.ErrPeriodNotStarted
+	}
+
 	return s.s.EndPeriod(taskID, time.Now())
 }
+
+func (s *TaskService) GetTaskPeriods(taskID uint) ([]models.TaskPeriod, error) {
+	return s.s.GetTaskPeriods(taskID)
+}
+
+// AddPeriod records a finished period with explicit bounds
+func (s *TaskService) AddPeriod(taskID uint, startTime, endTime time.Time, billable bool) (uint, error) {
+	if err := validatePeriod(startTime, endTime, time.Now()); err != nil {
+		return 0, err
+	}
+
+	if err := s.checkTaskOpen(taskID); err != nil {
+		return 0, err
+	}
+
+	return s.s.AddPeriod(models.TaskPeriod{TaskID: taskID, StartTime: &startTime, EndTime: &endTime, Billable: &billable})
+}
+
+func (s *TaskService) UpdatePeriod(periodID uint, startTime, endTime time.Time) error {
+	if err := validatePeriod(startTime, endTime, time.Now()); err != nil {
+		return err
+	}
+
+	period, err := s.s.GetPeriod(periodID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.checkTaskOpen(period.TaskID); err != nil {
+		return err
+	}
+
+	return s.s.UpdatePeriod(periodID, startTime, endTime)
+}
+
+func (s *TaskService) DeletePeriod(periodID uint) error {
+	period, err := s.s.GetPeriod(periodID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.checkTaskOpen(period.TaskID); err != nil {
+		return err
+	}
+
+	return s.s.DeletePeriod(periodID)
+}
+
+func (s *TaskService) SetPeriodBillable(periodID uint, billable bool) error {
+	return s.s.SetPeriodBillable(periodID, billable)
+}
+
+// checkTaskOpen returns ErrTaskClosed if task is done or archived
+func (s *TaskService) checkTaskOpen(taskID uint) error {
+	task, err := s.s.GetTask(taskID)
+	if err != nil {
+		return err
+	}
+
+	if task.Status.IsClosed() {
+		return ErrTaskClosed
+	}
+
+	return nil
+}
+
+// SwitchTask ends all running periods of the task owner and starts the task
+func (s *TaskService) SwitchTask(taskID uint) error {
+	task, err := s.s.GetTask(taskID)
+	if err != nil {
+		return err
+	}
+
+	if task.Status != models.TaskInProgress && !task.Status.CanTransitionTo(models.TaskInProgress) {
+		return ErrInvalidTaskTransition
+	}
+
+	if err := s.checkUserActive(task.UserID); err != nil {
+		return err
+	}
+
+	return s.s.SwitchPeriod(taskID, time.Now())
+}
+
+// GetRunningTimers returns running periods of the user or of all users if userID is 0
+func (s *TaskService) GetRunningTimers(userID uint) ([]models.RunningPeriod, error) {
+	periods, err := s.s.GetRunningPeriods(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	setElapsed(periods, time.Now())
+	return periods, nil
+}
+
+// setElapsed fills whole seconds each period has been running at now. Clock
+// skew can not make them negative
+func setElapsed(periods []models.RunningPeriod, now time.Time) {
+	for i := range periods {
+		periods[i].ElapsedSeconds = max(int64(now.Sub(*periods[i].StartTime)/time.Second), 0)
+	}
+}
+
+// checkTransition returns ErrInvalidTaskTransition if task can not be moved to the status
+func (s *TaskService) checkTransition(taskID uint, to models.TaskStatus) error {
+	task, err := s.s.GetTask(taskID)
+	if err != nil {
+		return err
+	}
+
+	if !task.Status.CanTransitionTo(to) {
+		s.log.Warn("task status transition is not allowed",
+			slog.Uint64("task_id", uint64(taskID)),
+			slog.String("from", string(task.Status)),
+			slog.String("to", string(to)))
+		return ErrInvalidTaskTransition
+	}
+
+	return nil
+}
+
+// checkUserActive returns ErrUserNotActive if user is suspended or terminated
+func (s *TaskService) checkUserActive(userID uint) error {
+	user, err := s.s.GetUser(userID)
+	if err != nil {
+		return err
+	}
+
+	if user.Status != models.UserActive {
+		s.log.Warn("user is not active",
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("status", string(user.Status)))
+		return ErrUserNotActive
+	}
+
+	return nil
+}
+
+// validatePeriod checks that period bounds are ordered and not after now
+func validatePeriod(startTime, endTime, now time.Time) error {
+	if !startTime.Before(endTime) {
+		return ErrInvalidPeriod
+	}
+	if endTime.After(now) {
+		return ErrPeriodInFuture
+	}
+	return nil
+}
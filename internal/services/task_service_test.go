@@ -0,0 +1,122 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/moxicom/user_test/internal/models"
+)
+
+func TestUpdateTaskValidation(t *testing.T) {
+	name, blank := "Review", "  "
+	tags := []string{"backend"}
+	negative, zero := int64(-60), int64(0)
+
+	tests := []struct {
+		update models.TaskUpdate
+		err    error
+	}{
+		{models.TaskUpdate{TaskName: &name, Tags: &tags}, nil},
+		{models.TaskUpdate{TaskName: &blank}, ErrEmptyTaskName},
+		{models.TaskUpdate{Estimate: &negative}, ErrNegativeEstimate},
+		{models.TaskUpdate{Estimate: &zero}, nil}, // removes the estimate
+	}
+
+	for i, test := range tests {
+		s := &fakeStorage{}
+		service := newTaskService(s, discardLogger())
+
+		err := service.UpdateTask(1, test.update)
+		if !errors.Is(err, test.err) {
+			t.Errorf("case %d: error = %v; expected %v", i, err, test.err)
+			continue
+		}
+		_, stored := s.updates[1]
+		if stored != (test.err == nil) {
+			t.Errorf("case %d: update stored = %v; expected %v", i, stored, test.err == nil)
+		}
+	}
+}
+
+func TestValidatePeriod(t *testing.T) {
+	now := time.Date(2024, 7, 3, 12, 0, 0, 0, time.UTC)
+	hour := time.Hour
+
+	tests := []struct {
+		start    time.Time
+		end      time.Time
+		expected error
+	}{
+		{now.Add(-2 * hour), now.Add(-hour), nil},
+		{now.Add(-hour), now, nil}, // may end right now
+		{now.Add(-72 * hour), now.Add(-hour), nil},
+		{now.Add(-hour), now.Add(-hour), ErrInvalidPeriod},
+		{now.Add(-hour), now.Add(-2 * hour), ErrInvalidPeriod},
+		{now.Add(-hour), now.Add(time.Second), ErrPeriodInFuture},
+		{now.Add(hour), now.Add(2 * hour), ErrPeriodInFuture},
+		{now.Add(2 * hour), now.Add(hour), ErrInvalidPeriod}, // order is checked first
+	}
+
+	for _, test := range tests {
+		err := validatePeriod(test.start, test.end, now)
+		if !errors.Is(err, test.expected) {
+			t.Errorf("validatePeriod(%v, %v) = %v; expected %v", test.start, test.end, err, test.expected)
+		}
+	}
+}
+
+func TestSwitchTaskRules(t *testing.T) {
+	tests := []struct {
+		status     models.TaskStatus
+		userStatus models.UserStatus
+		expected   error
+	}{
+		{models.TaskTodo, models.UserActive, nil},
+		{models.TaskPaused, models.UserActive, nil},
+		{models.TaskInProgress, models.UserActive, nil}, // ends timers of other tasks
+		{models.TaskDone, models.UserActive, ErrInvalidTaskTransition},
+		{models.TaskArchived, models.UserActive, ErrInvalidTaskTransition},
+		{models.TaskTodo, models.UserSuspended, ErrUserNotActive},
+		{models.TaskPaused, models.UserTerminated, ErrUserNotActive},
+	}
+
+	for _, test := range tests {
+		s := &fakeStorage{
+			users:    []models.User{{ID: 5, Status: test.userStatus}},
+			taskList: []models.Task{{ID: 1, UserID: 5, Status: test.status}},
+		}
+		service := newTaskService(s, discardLogger())
+
+		err := service.SwitchTask(1)
+		if !errors.Is(err, test.expected) {
+			t.Errorf("SwitchTask(%s task, %s user) = %v; expected %v", test.status, test.userStatus, err, test.expected)
+			continue
+		}
+		if switched := len(s.switched) == 1; switched != (test.expected == nil) {
+			t.Errorf("SwitchTask(%s task, %s user) switched = %v; expected %v", test.status, test.userStatus, switched, test.expected == nil)
+		}
+	}
+}
+
+func TestSetElapsed(t *testing.T) {
+	now := time.Date(2024, 7, 3, 12, 0, 0, 0, time.UTC)
+	running := func(start time.Time) models.RunningPeriod {
+		return models.RunningPeriod{TaskPeriod: models.TaskPeriod{StartTime: &start}}
+	}
+	periods := []models.RunningPeriod{
+		running(now.Add(-90*time.Minute - 500*time.Millisecond)),
+		running(now.Add(-26 * time.Hour)),
+		running(now),
+		running(now.Add(2 * time.Second)), // started on a clock ahead of ours
+	}
+
+	setElapsed(periods, now)
+
+	expected := []int64{90 * 60, 26 * 3600, 0, 0}
+	for i, period := range periods {
+		if period.ElapsedSeconds != expected[i] {
+			t.Errorf("period %d elapsed = %d; expected %d", i, period.ElapsedSeconds, expected[i])
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/moxicom/user_test/internal/models"
+	"github.com/moxicom/user_test/internal/storage"
+)
+
+type TeamService struct {
+	s        storage.Storage
+	log      *slog.Logger
+	rounding Rounding
+}
+
+func newTeamService(s storage.Storage, log *slog.Logger, rounding Rounding) *TeamService {
+	return &TeamService{s, log, rounding}
+}
+
+func (s *TeamService) CreateTeam(team models.Team) (uint, error) {
+	return s.s.CreateTeam(team)
+}
+
+func (s *TeamService) GetTeams() ([]models.Team, error) {
+	return s.s.GetTeams()
+}
+
+func (s *TeamService) DeleteTeam(teamID uint) error {
+	return s.s.DeleteTeam(teamID)
+}
+
+func (s *TeamService) AddTeamMember(member models.TeamMember) error {
+	switch member.Role {
+	case "":
+		member.Role = models.TeamMemberRole
+	case models.TeamMemberRole, models.TeamManagerRole:
+	default:
+		return ErrInvalidTeamRole
+	}
+
+	if _, err := s.s.GetUser(member.UserID); err != nil {
+		return err
+	}
+
+	return s.s.AddTeamMember(member)
+}
+
+func (s *TeamService) RemoveTeamMember(teamID uint, userID uint) error {
+	return s.s.RemoveTeamMember(teamID, userID)
+}
+
+func (s *TeamService) GetTeamMembers(teamID uint) ([]models.TeamMemberInfo, error) {
+	return s.s.GetTeamMembers(teamID)
+}
+
+func (s *TeamService) GetTeamUsers(teamID uint, f models.UserFilters) ([]models.User, error) {
+	f.TeamID = teamID
+	return s.s.GetUsers(f)
+}
+
+// GetTeamTasks returns tasks with total time of every team member within the range
+func (s *TeamService) GetTeamTasks(teamID uint, startTime, endTime time.Time, filters models.TaskFilters) ([]models.UserWithTasks, error) {
+	log := s.log.With(slog.String("op", "service.GetTeamTasks"))
+
+	users, err := s.s.GetUsers(models.UserFilters{TeamID: teamID})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]models.UserWithTasks, 0, len(users))
+	for _, user := range users {
+		tasks, err := s.s.GetUserTasks(user.ID, startTime, endTime, filters)
+		if err != nil {
+			log.Error("failed to get member tasks", slog.Uint64("user_id", uint64(user.ID)))
+			return nil, err
+		}
+		s.rounding.roundTasks(tasks)
+		result = append(result, models.UserWithTasks{User: user, Tasks: tasks})
+	}
+
+	return result, nil
+}
+
+func (s *TeamService) GetManagedUsers(managerID uint) ([]models.User, error) {
+	return s.s.GetManagedUsers(managerID)
+}
@@ -0,0 +1,63 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/moxicom/user_test/internal/models"
+)
+
+func TestGetTeamTasksRoundsEveryMember(t *testing.T) {
+	s := &fakeStorage{
+		users:   []models.User{{ID: 1}, {ID: 2}, {ID: 3}},
+		members: map[uint][]uint{7: {1, 3}},
+		tasks: map[uint][]models.TaskWithTotalTime{
+			1: {trackedTask(10, nil, 7*60)},
+			2: {trackedTask(20, nil, 3600)},
+		},
+	}
+	service := newTeamService(s, discardLogger(), Rounding{Minutes: 15, Mode: RoundUp})
+
+	result, err := service.GetTeamTasks(7, time.Time{}, time.Time{}, models.TaskFilters{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(result) != 2 || result[0].User.ID != 1 || result[1].User.ID != 3 {
+		t.Fatalf("result = %+v; expected members 1 and 3", result)
+	}
+	if len(result[0].Tasks) != 1 || result[0].Tasks[0].TotalSeconds != 15*60 {
+		t.Errorf("member 1 tasks = %+v; expected one task rounded up to 15 minutes", result[0].Tasks)
+	}
+	if len(result[1].Tasks) != 0 {
+		t.Errorf("member 3 tasks = %+v; expected none", result[1].Tasks)
+	}
+}
+
+func TestAddTeamMemberRole(t *testing.T) {
+	tests := []struct {
+		role     models.TeamRole
+		expected models.TeamRole
+		err      error
+	}{
+		{"", models.TeamMemberRole, nil},
+		{models.TeamMemberRole, models.TeamMemberRole, nil},
+		{models.TeamManagerRole, models.TeamManagerRole, nil},
+		{"owner", "", ErrInvalidTeamRole},
+	}
+
+	for _, test := range tests {
+		s := &fakeStorage{users: []models.User{{ID: 1}}}
+		service := newTeamService(s, discardLogger(), Rounding{})
+
+		err := service.AddTeamMember(models.TeamMember{TeamID: 7, UserID: 1, Role: test.role})
+		if !errors.Is(err, test.err) {
+			t.Errorf("AddTeamMember(role %q) error = %v; expected %v", test.role, err, test.err)
+			continue
+		}
+		if test.err == nil && (len(s.added) != 1 || s.added[0].Role != test.expected) {
+			t.Errorf("AddTeamMember(role %q) added %+v; expected role %q", test.role, s.added, test.expected)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"sort"
+	"time"
+
+	"github.com/moxicom/user_test/internal/models"
+)
+
+// GetUserTimesheet returns time worked by the user per task and day of the
+// range. Day boundaries are local midnights in loc
+func (s *UserService) GetUserTimesheet(userID uint, from, to time.Time, loc *time.Location) (models.Timesheet, error) {
+	if err := checkRange(from, to); err != nil {
+		return models.Timesheet{}, err
+	}
+
+	user, err := s.s.GetUser(userID)
+	if err != nil {
+		return models.Timesheet{}, err
+	}
+
+	periods, err := s.s.GetUserPeriods(userID, from, to)
+	if err != nil {
+		return models.Timesheet{}, err
+	}
+
+	days := summarize(periods, from, to, time.Now(), models.GroupByDay, loc)
+	s.rounding.roundSummary(days)
+
+	ts := buildTimesheet(days)
+	ts.User, ts.Start, ts.End = user, from, to
+	return ts, nil
+}
+
+// GetUserTimesheetXLSX renders timesheet of the week containing day as XLSX workbook
+func (s *UserService) GetUserTimesheetXLSX(userID uint, day time.Time, loc *time.Location) ([]byte, error) {
+	start := bucketStart(day, models.GroupByWeek, loc)
+
+	ts, err := s.GetUserTimesheet(userID, start, nextBucket(start, models.GroupByWeek), loc)
+	if err != nil {
+		return nil, err
+	}
+
+	return timesheetXLSX(ts, loc)
+}
+
+// buildTimesheet turns day buckets into rows of tasks sorted by name with a
+// column per day
+func buildTimesheet(days []models.SummaryBucket) models.Timesheet {
+	ts := models.Timesheet{
+		Days:      make([]time.Time, len(days)),
+		Rows:      make([]models.TimesheetRow, 0),
+		DayTotals: make([]models.Duration, len(days)),
+	}
+
+	rows := make(map[uint]*models.TimesheetRow)
+	for i, d := range days {
+		ts.Days[i] = d.Start
+		ts.DayTotals[i] = d.Duration
+		for _, t := range d.Tasks {
+			row, ok := rows[t.TaskID]
+			if !ok {
+				row = &models.TimesheetRow{TaskID: t.TaskID, TaskName: t.TaskName, Days: make([]models.Duration, len(days))}
+				for j := range row.Days {
+					row.Days[j] = newDuration(0)
+				}
+				rows[t.TaskID] = row
+			}
+			row.Days[i] = t.Duration
+		}
+	}
+
+	var total int64
+	for _, row := range rows {
+		var seconds int64
+		for _, d := range row.Days {
+			seconds += d.TotalSeconds
+		}
+		row.Total = newDuration(seconds)
+		total += seconds
+		ts.Rows = append(ts.Rows, *row)
+	}
+	sort.Slice(ts.Rows, func(i, j int) bool {
+		if ts.Rows[i].TaskName != ts.Rows[j].TaskName {
+			return ts.Rows[i].TaskName < ts.Rows[j].TaskName
+		}
+		return ts.Rows[i].TaskID < ts.Rows[j].TaskID
+	})
+	ts.Total = newDuration(total)
+
+	return ts
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/moxicom/user_test/internal/models"
+)
+
+// printableTimesheet is task totals of a user within [From, To) for signed timesheets
+type printableTimesheet struct {
+	User        models.User
+	From        time.Time
+	To          time.Time
+	Tasks       []models.TaskWithTotalTime
+	Total       models.Duration
+	GeneratedAt time.Time
+	Location    *time.Location
+}
+
+func (t printableTimesheet) UserName() string {
+	return strings.TrimSpace(t.User.Surname + " " + t.User.Name + " " + t.User.Patronymic)
+}
+
+// Period formats the range with inclusive last day
+func (t printableTimesheet) Period() string {
+	return t.From.In(t.Location).Format("2006-01-02") + " - " + t.To.In(t.Location).Add(-time.Nanosecond).Format("2006-01-02")
+}
+
+// GetUserTimesheetHTML renders printable timesheet of the user with task totals
+// of the range and signatures block
+func (s *UserService) GetUserTimesheetHTML(userID uint, from, to time.Time, loc *time.Location) ([]byte, error) {
+	ts, err := s.printableTimesheet(userID, from, to, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	return printHTML(ts.document())
+}
+
+// GetUserTimesheetPDF renders the same timesheet as GetUserTimesheetHTML as PDF
+func (s *UserService) GetUserTimesheetPDF(userID uint, from, to time.Time, loc *time.Location) ([]byte, error) {
+	ts, err := s.printableTimesheet(userID, from, to, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	return printPDF(ts.document()), nil
+}
+
+func (s *UserService) printableTimesheet(userID uint, from, to time.Time, loc *time.Location) (printableTimesheet, error) {
+	if !from.Before(to) {
+		return printableTimesheet{}, ErrInvalidRange
+	}
+
+	user, err := s.s.GetUser(userID)
+	if err != nil {
+		return printableTimesheet{}, err
+	}
+
+	tasks, err := s.GetUserTasks(userID, from, to, models.TaskFilters{})
+	if err != nil {
+		return printableTimesheet{}, err
+	}
+
+	var total int64
+	for _, t := range tasks {
+		total += t.TotalSeconds
+	}
+
+	return printableTimesheet{
+		User:        user,
+		From:        from,
+		To:          to,
+		Tasks:       tasks,
+		Total:       newDuration(total),
+		GeneratedAt: time.Now(),
+		Location:    loc,
+	}, nil
+}
+
+// hoursMinutes formats duration as H:MM
+func hoursMinutes(d models.Duration) string {
+	return fmt.Sprintf("%d:%02d", d.DurationHours, d.DurationMinutes)
+}
+
+// decimalHours formats duration as hours with two decimals
+func decimalHours(d models.Duration) string {
+	return fmt.Sprintf("%.2f", float64(d.TotalSeconds)/3600)
+}
+
+// document lays out the timesheet with signature lines of the employee and
+// of the approver
+func (t printableTimesheet) document() printDocument {
+	doc := printDocument{
+		Title:   "Timesheet " + t.UserName() + " " + t.Period(),
+		Heading: "Timesheet",
+		Info: [][2]string{
+			{"Employee:", t.UserName()},
+			{"Passport:", t.User.PassportNumber},
+			{"Period:", t.Period()},
+			{"Time zone:", t.Location.String()},
+		},
+		Columns:    []string{"#", "Task", "Time", "Hours"},
+		Rows:       make([][]string, 0, len(t.Tasks)),
+		Empty:      "No time recorded",
+		Total:      []string{"", "Total", hoursMinutes(t.Total), decimalHours(t.Total)},
+		Signatures: []string{"Employee signature / date", "Approved by (name, signature / date)"},
+		Footer:     "Generated " + t.GeneratedAt.In(t.Location).Format("2006-01-02 15:04 MST"),
+	}
+	for i, task := range t.Tasks {
+		doc.Rows = append(doc.Rows, []string{fmt.Sprint(i + 1), task.TaskName, hoursMinutes(task.Duration), decimalHours(task.Duration)})
+	}
+	return doc
+}
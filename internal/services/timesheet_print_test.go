@@ -0,0 +1,46 @@
+package services
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/moxicom/user_test/internal/models"
+)
+
+func testPrintableTimesheet(tasks int) printableTimesheet {
+	ts := printableTimesheet{
+		User:        models.User{Surname: "Иванов", Name: "Иван", PassportNumber: "1234 567890"},
+		From:        time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC),
+		To:          time.Date(2024, 8, 1, 0, 0, 0, 0, time.UTC),
+		GeneratedAt: time.Date(2024, 8, 1, 9, 30, 0, 0, time.UTC),
+		Location:    time.UTC,
+	}
+	for i := 0; i < tasks; i++ {
+		task := models.TaskWithTotalTime{Task: models.Task{TaskName: fmt.Sprintf("task %d", i)}, Duration: newDuration(5400)}
+		ts.Tasks = append(ts.Tasks, task)
+		ts.Total = newDuration(ts.Total.TotalSeconds + task.TotalSeconds)
+	}
+	return ts
+}
+
+func TestTimesheetDocument(t *testing.T) {
+	doc := testPrintableTimesheet(2).document()
+
+	if doc.Title != "Timesheet Иванов Иван 2024-07-01 - 2024-07-31" {
+		t.Errorf("title = %q", doc.Title)
+	}
+	if !slices.Contains(doc.Info, [2]string{"Passport:", "1234 567890"}) || !slices.Contains(doc.Info, [2]string{"Time zone:", "UTC"}) {
+		t.Errorf("info = %q", doc.Info)
+	}
+	if len(doc.Rows) != 2 || !slices.Equal(doc.Rows[1], []string{"2", "task 1", "1:30", "1.50"}) {
+		t.Errorf("rows = %q", doc.Rows)
+	}
+	if !slices.Equal(doc.Total, []string{"", "Total", "3:00", "3.00"}) {
+		t.Errorf("total = %q", doc.Total)
+	}
+	if len(doc.Signatures) != 2 || doc.Footer != "Generated 2024-08-01 09:30 UTC" {
+		t.Errorf("signatures = %q, footer = %q", doc.Signatures, doc.Footer)
+	}
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/moxicom/user_test/internal/models"
+)
+
+func TestBuildTimesheet(t *testing.T) {
+	loc := time.UTC
+	from := time.Date(2024, 7, 1, 0, 0, 0, 0, loc)
+	to := from.AddDate(0, 0, 7)
+	periods := []models.ReportPeriod{
+		reportPeriod(1, time.Date(2024, 7, 1, 22, 0, 0, 0, loc), time.Date(2024, 7, 2, 1, 0, 0, 0, loc)),
+		reportPeriod(2, time.Date(2024, 7, 3, 9, 0, 0, 0, loc), time.Date(2024, 7, 3, 10, 30, 0, 0, loc)),
+	}
+	periods[0].TaskName, periods[1].TaskName = "b", "a"
+
+	days := summarize(periods, from, to, to, models.GroupByDay, loc)
+	Rounding{}.roundSummary(days)
+	ts := buildTimesheet(days)
+
+	if len(ts.Days) != 7 || len(ts.Rows) != 2 {
+		t.Fatalf("got %d days and %d rows; expected 7 and 2", len(ts.Days), len(ts.Rows))
+	}
+	if ts.Rows[0].TaskName != "a" || ts.Rows[0].Days[2].TotalSeconds != 5400 || ts.Rows[0].Days[0].TotalSeconds != 0 {
+		t.Errorf("first row = %+v; expected task a with 5400 seconds on Wednesday", ts.Rows[0])
+	}
+	if ts.Rows[1].Days[0].TotalSeconds != 7200 || ts.Rows[1].Days[1].TotalSeconds != 3600 || ts.Rows[1].Total.TotalSeconds != 10800 {
+		t.Errorf("second row = %+v; expected period split at midnight", ts.Rows[1])
+	}
+	if ts.Total.TotalSeconds != 16200 || ts.DayTotals[0].TotalSeconds != 7200 {
+		t.Errorf("totals = %d, %d; expected 16200, 7200", ts.Total.TotalSeconds, ts.DayTotals[0].TotalSeconds)
+	}
+}
+
+func TestXLSXColumn(t *testing.T) {
+	for i, expected := range map[int]string{0: "A", 25: "Z", 26: "AA", 27: "AB", 701: "ZZ", 702: "AAA"} {
+		if col := xlsxColumn(i); col != expected {
+			t.Errorf("xlsxColumn(%d) = %s; expected %s", i, col, expected)
+		}
+	}
+}
+
+func TestWriteXLSX(t *testing.T) {
+	data, err := writeXLSX([]xlsxSheet{{Name: "Sheet & 1", Rows: [][]xlsxCell{{{"<task>", 0}, {1.5, xlsxStyleHours}}}}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("workbook is not a zip: %v", err)
+	}
+	if zr.File[0].Name != "[Content_Types].xml" {
+		t.Errorf("first part = %s; expected [Content_Types].xml", zr.File[0].Name)
+	}
+
+	for _, f := range zr.File {
+		r, err := f.Open()
+		if err != nil {
+			t.Fatalf("failed to open %s: %v", f.Name, err)
+		}
+		content, _ := io.ReadAll(r)
+		r.Close()
+
+		decoder := xml.NewDecoder(bytes.NewReader(content))
+		for {
+			if _, err := decoder.Token(); err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatalf("%s is not well-formed: %v", f.Name, err)
+			}
+		}
+
+		if f.Name == "xl/worksheets/sheet1.xml" && !strings.Contains(string(content), `<c r="B1" s="2"><v>1.5</v></c>`) {
+			t.Errorf("sheet does not contain number cell: %s", content)
+		}
+	}
+}
@@ -0,0 +1,330 @@
+package services
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"sort"
+)
+
+var errInvalidFont = errors.New("invalid TrueType font")
+
+// ttfFont is a parsed TrueType font, enough to measure text and embed
+// subsets of it into PDF
+type ttfFont struct {
+	name       string // PostScript name
+	tables     map[string][]byte
+	unitsPerEm int
+	bbox       [4]int // xMin, yMin, xMax, yMax
+	ascent     int
+	descent    int
+	glyphs     map[rune]uint16
+	advances   []uint16 // advance width of every glyph
+	loca       []uint32 // glyph offsets in glyf, one more than glyphs
+}
+
+// tables kept by subsets, glyph programs are needed for hinting
+var ttfSubsetTables = []string{"head", "hhea", "maxp", "hmtx", "loca", "glyf", "cvt ", "fpgm", "prep"}
+
+func mustParseTTF(name string, data []byte) *ttfFont {
+	font, err := parseTTF(name, data)
+	if err != nil {
+		panic(name + ": " + err.Error())
+	}
+	return font
+}
+
+func parseTTF(name string, data []byte) (*ttfFont, error) {
+	if len(data) < 12 {
+		return nil, errInvalidFont
+	}
+	f := &ttfFont{name: name, tables: make(map[string][]byte)}
+	numTables := int(binary.BigEndian.Uint16(data[4:]))
+	for i := 0; i < numTables; i++ {
+		entry := 12 + i*16
+		if entry+16 > len(data) {
+			return nil, errInvalidFont
+		}
+		offset := binary.BigEndian.Uint32(data[entry+8:])
+		length := binary.BigEndian.Uint32(data[entry+12:])
+		if uint64(offset)+uint64(length) > uint64(len(data)) {
+			return nil, errInvalidFont
+		}
+		f.tables[string(data[entry:entry+4])] = data[offset : offset+length]
+	}
+
+	head, hhea, maxp := f.tables["head"], f.tables["hhea"], f.tables["maxp"]
+	if len(head) < 54 || len(hhea) < 36 || len(maxp) < 6 {
+		return nil, errInvalidFont
+	}
+	f.unitsPerEm = int(binary.BigEndian.Uint16(head[18:]))
+	for i := range f.bbox {
+		f.bbox[i] = int(int16(binary.BigEndian.Uint16(head[36+i*2:])))
+	}
+	f.ascent = int(int16(binary.BigEndian.Uint16(hhea[4:])))
+	f.descent = int(int16(binary.BigEndian.Uint16(hhea[6:])))
+	numGlyphs := int(binary.BigEndian.Uint16(maxp[4:]))
+	if f.unitsPerEm == 0 || numGlyphs == 0 {
+		return nil, errInvalidFont
+	}
+
+	hmtx := f.tables["hmtx"]
+	metrics := int(binary.BigEndian.Uint16(hhea[34:]))
+	if metrics == 0 || metrics > numGlyphs || len(hmtx) < metrics*4 {
+		return nil, errInvalidFont
+	}
+	f.advances = make([]uint16, numGlyphs)
+	for g := range f.advances {
+		f.advances[g] = binary.BigEndian.Uint16(hmtx[min(g, metrics-1)*4:])
+	}
+
+	loca, longLoca := f.tables["loca"], binary.BigEndian.Uint16(head[50:]) == 1
+	if (longLoca && len(loca) < (numGlyphs+1)*4) || (!longLoca && len(loca) < (numGlyphs+1)*2) {
+		return nil, errInvalidFont
+	}
+	f.loca = make([]uint32, numGlyphs+1)
+	for g := range f.loca {
+		if longLoca {
+			f.loca[g] = binary.BigEndian.Uint32(loca[g*4:])
+		} else {
+			f.loca[g] = uint32(binary.BigEndian.Uint16(loca[g*2:])) * 2
+		}
+	}
+	if f.loca[numGlyphs] > uint32(len(f.tables["glyf"])) {
+		return nil, errInvalidFont
+	}
+
+	glyphs, err := parseCmap(f.tables["cmap"], numGlyphs)
+	if err != nil {
+		return nil, err
+	}
+	f.glyphs = glyphs
+
+	return f, nil
+}
+
+// parseCmap reads Unicode mapping of Windows platform in format 4 or 12
+func parseCmap(cmap []byte, numGlyphs int) (map[rune]uint16, error) {
+	if len(cmap) < 4 {
+		return nil, errInvalidFont
+	}
+	var bmp, full []byte
+	for i := 0; i < int(binary.BigEndian.Uint16(cmap[2:])); i++ {
+		record := 4 + i*8
+		if record+8 > len(cmap) {
+			return nil, errInvalidFont
+		}
+		platform, encoding := binary.BigEndian.Uint16(cmap[record:]), binary.BigEndian.Uint16(cmap[record+2:])
+		offset := binary.BigEndian.Uint32(cmap[record+4:])
+		if platform != 3 || offset+4 > uint32(len(cmap)) {
+			continue
+		}
+		switch {
+		case encoding == 1 && binary.BigEndian.Uint16(cmap[offset:]) == 4:
+			bmp = cmap[offset:]
+		case encoding == 10 && binary.BigEndian.Uint16(cmap[offset:]) == 12:
+			full = cmap[offset:]
+		}
+	}
+
+	glyphs := make(map[rune]uint16)
+	add := func(r rune, g uint32) {
+		if g != 0 && int(g) < numGlyphs {
+			glyphs[r] = uint16(g)
+		}
+	}
+
+	switch {
+	case full != nil && len(full) >= 16:
+		groups := int(binary.BigEndian.Uint32(full[12:]))
+		if len(full) < 16+groups*12 {
+			return nil, errInvalidFont
+		}
+		for i := 0; i < groups; i++ {
+			group := full[16+i*12:]
+			start, end, glyph := binary.BigEndian.Uint32(group), binary.BigEndian.Uint32(group[4:]), binary.BigEndian.Uint32(group[8:])
+			for c := start; c <= end && c <= 0x10ffff; c++ {
+				add(rune(c), glyph+c-start)
+			}
+		}
+	case bmp != nil && len(bmp) >= 14:
+		segments := int(binary.BigEndian.Uint16(bmp[6:])) / 2
+		ends, starts, deltas, ranges := 14, 16+segments*2, 16+segments*4, 16+segments*6
+		if len(bmp) < ranges+segments*2 {
+			return nil, errInvalidFont
+		}
+		for i := 0; i < segments; i++ {
+			end := binary.BigEndian.Uint16(bmp[ends+i*2:])
+			start := binary.BigEndian.Uint16(bmp[starts+i*2:])
+			delta := binary.BigEndian.Uint16(bmp[deltas+i*2:])
+			rangeOffset := int(binary.BigEndian.Uint16(bmp[ranges+i*2:]))
+			for c := uint32(start); c <= uint32(end) && c != 0xffff; c++ {
+				if rangeOffset == 0 {
+					add(rune(c), uint32(uint16(c)+delta))
+					continue
+				}
+				at := ranges + i*2 + rangeOffset + int(c-uint32(start))*2
+				if at+2 > len(bmp) {
+					return nil, errInvalidFont
+				}
+				if g := binary.BigEndian.Uint16(bmp[at:]); g != 0 {
+					add(rune(c), uint32(g+delta))
+				}
+			}
+		}
+	default:
+		return nil, errInvalidFont
+	}
+
+	return glyphs, nil
+}
+
+// glyph returns glyph of r, zero is the missing glyph
+func (f *ttfFont) glyph(r rune) uint16 {
+	return f.glyphs[r]
+}
+
+// width returns advance width of s in points
+func (f *ttfFont) width(s string, size float64) float64 {
+	var units int
+	for _, r := range s {
+		units += int(f.advances[f.glyph(r)])
+	}
+	return float64(units) * size / float64(f.unitsPerEm)
+}
+
+// scale converts font units to thousandths of text size used by PDF
+func (f *ttfFont) scale(units int) int {
+	return units * 1000 / f.unitsPerEm
+}
+
+// subset returns the font with outlines of the used glyphs and of parts of
+// composite ones only. Glyph numbers are kept, so text needs no remapping
+func (f *ttfFont) subset(used map[uint16]bool) []byte {
+	keep := map[uint16]bool{0: true}
+	for g := range used {
+		f.keepGlyph(keep, g)
+	}
+
+	glyf := f.tables["glyf"]
+	var outlines []byte
+	loca := make([]byte, len(f.loca)*4)
+	for g := 0; g < len(f.loca)-1; g++ {
+		binary.BigEndian.PutUint32(loca[g*4:], uint32(len(outlines)))
+		if keep[uint16(g)] {
+			outlines = append(outlines, glyf[f.loca[g]:f.loca[g+1]]...)
+			for len(outlines)%4 != 0 {
+				outlines = append(outlines, 0)
+			}
+		}
+	}
+	binary.BigEndian.PutUint32(loca[(len(f.loca)-1)*4:], uint32(len(outlines)))
+
+	head := append([]byte{}, f.tables["head"]...)
+	binary.BigEndian.PutUint32(head[8:], 0)  // checksum adjustment, set below
+	binary.BigEndian.PutUint16(head[50:], 1) // long loca offsets
+
+	tables := make(map[string][]byte)
+	for _, tag := range ttfSubsetTables {
+		if table, ok := f.tables[tag]; ok {
+			tables[tag] = table
+		}
+	}
+	tables["head"], tables["loca"], tables["glyf"] = head, loca, outlines
+
+	font, offsets := writeSfnt(tables)
+	binary.BigEndian.PutUint32(font[offsets["head"]+8:], 0xb1b0afba-ttfChecksum(font))
+	return font
+}
+
+// keepGlyph adds the glyph and components of composite glyph to keep
+func (f *ttfFont) keepGlyph(keep map[uint16]bool, g uint16) {
+	if int(g) >= len(f.loca)-1 || keep[g] {
+		return
+	}
+	keep[g] = true
+
+	data := f.tables["glyf"][f.loca[g]:f.loca[g+1]]
+	if len(data) < 10 || int16(binary.BigEndian.Uint16(data)) >= 0 {
+		return
+	}
+	const (
+		argsAreWords   = 0x0001
+		hasScale       = 0x0008
+		moreComponents = 0x0020
+		hasXYScale     = 0x0040
+		hasTwoByTwo    = 0x0080
+	)
+	for at := 10; at+4 <= len(data); {
+		flags := binary.BigEndian.Uint16(data[at:])
+		f.keepGlyph(keep, binary.BigEndian.Uint16(data[at+2:]))
+		at += 4
+		if flags&argsAreWords != 0 {
+			at += 4
+		} else {
+			at += 2
+		}
+		switch {
+		case flags&hasScale != 0:
+			at += 2
+		case flags&hasXYScale != 0:
+			at += 4
+		case flags&hasTwoByTwo != 0:
+			at += 8
+		}
+		if flags&moreComponents == 0 {
+			break
+		}
+	}
+}
+
+// writeSfnt assembles font file of the tables sorted by tag and returns
+// offsets of the tables in it
+func writeSfnt(tables map[string][]byte) ([]byte, map[string]int) {
+	tags := make([]string, 0, len(tables))
+	for tag := range tables {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	selector := 0
+	for 2<<selector <= len(tags) {
+		selector++
+	}
+	searchRange := 16 << selector
+
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.BigEndian, uint32(0x00010000))
+	for _, v := range []uint16{uint16(len(tags)), uint16(searchRange), uint16(selector), uint16(len(tags)*16 - searchRange)} {
+		binary.Write(&buf, binary.BigEndian, v)
+	}
+
+	offsets := make(map[string]int, len(tags))
+	offset := 12 + len(tags)*16
+	for _, tag := range tags {
+		table := tables[tag]
+		offsets[tag] = offset
+		buf.WriteString(tag)
+		for _, v := range []uint32{ttfChecksum(table), uint32(offset), uint32(len(table))} {
+			binary.Write(&buf, binary.BigEndian, v)
+		}
+		offset += (len(table) + 3) &^ 3
+	}
+	for _, tag := range tags {
+		buf.Write(tables[tag])
+		buf.Write(make([]byte, (4-len(tables[tag])%4)%4))
+	}
+
+	return buf.Bytes(), offsets
+}
+
+// ttfChecksum sums data as big endian 32-bit words padded with zeros
+func ttfChecksum(data []byte) uint32 {
+	var sum uint32
+	for i := 0; i < len(data); i += 4 {
+		var word [4]byte
+		copy(word[:], data[i:])
+		sum += binary.BigEndian.Uint32(word[:])
+	}
+	return sum
+}
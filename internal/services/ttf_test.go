@@ -0,0 +1,64 @@
+package services
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+func TestTTFWidth(t *testing.T) {
+	font := pdfFonts[0]
+
+	if font.width("", 11) != 0 {
+		t.Errorf("width of empty text is not zero")
+	}
+	if font.width("WWW", 10) <= font.width("iii", 10) {
+		t.Errorf("W is not wider than i")
+	}
+	if one, two := font.width("Щ", 10), font.width("ЩЩ", 20); two != 4*one {
+		t.Errorf("width of ЩЩ at 20 = %g; expected %g", two, 4*one)
+	}
+}
+
+func TestTTFSubset(t *testing.T) {
+	font := pdfFonts[0]
+	used := map[uint16]bool{font.glyph('A'): true, font.glyph('Й'): true}
+
+	subset := font.subset(used)
+	if len(subset) >= len(dejaVuSans)/4 {
+		t.Errorf("subset is %d bytes of %d", len(subset), len(dejaVuSans))
+	}
+	if sum := ttfChecksum(subset); sum != 0xb1b0afba {
+		t.Errorf("font checksum = %#x; expected 0xb1b0afba", sum)
+	}
+
+	tables := make(map[string][]byte)
+	for i := 0; i < int(binary.BigEndian.Uint16(subset[4:])); i++ {
+		entry := subset[12+i*16:]
+		offset, length := binary.BigEndian.Uint32(entry[8:]), binary.BigEndian.Uint32(entry[12:])
+		tables[string(entry[:4])] = subset[offset : offset+length]
+	}
+	for _, tag := range []string{"head", "hhea", "maxp", "hmtx", "loca", "glyf"} {
+		if _, ok := tables[tag]; !ok {
+			t.Errorf("subset has no %s table", tag)
+		}
+	}
+
+	loca := tables["loca"]
+	outline := func(g uint16) []byte {
+		return tables["glyf"][binary.BigEndian.Uint32(loca[g*4:]):binary.BigEndian.Uint32(loca[g*4+4:])]
+	}
+	kept := make(map[uint16]bool)
+	font.keepGlyph(kept, font.glyph('Й'))
+	for g := 0; g < len(font.loca)-1; g++ {
+		original := font.tables["glyf"][font.loca[g]:font.loca[g+1]]
+		size := len(outline(uint16(g)))
+		switch {
+		case g == 0 || used[uint16(g)] || kept[uint16(g)]:
+			if size < len(original) || string(outline(uint16(g))[:len(original)]) != string(original) {
+				t.Errorf("outline of glyph %d is not kept", g)
+			}
+		case size != 0:
+			t.Errorf("outline of unused glyph %d is kept", g)
+		}
+	}
+}
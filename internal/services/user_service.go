@@ -2,6 +2,7 @@ package services
 
 import (
 	"log/slog"
+	"sort"
 	"time"
 
 	"github.com/moxicom/user_test/internal/models"
@@ -10,12 +11,13 @@ import (
 )
 
 type UserService struct {
-	s   storage.Storage
-	log *slog.Logger
+	s        storage.Storage
+	log      *slog.Logger
+	rounding Rounding
 }
 
-func newUserService(s storage.Storage, log *slog.Logger) *UserService {
-	return &UserService{s, log}
+func newUserService(s storage.Storage, log *slog.Logger, rounding Rounding) *UserService {
+	return &UserService{s, log, rounding}
 }
 
 func (s *UserService) CreateUser(passport string) (uint, error) {
@@ -59,5 +61,124 @@ func (s *UserService) UpdateUser(userID uint, filters models.UserFilters) error
 }
 
 func (s *UserService) GetUserTasks(userID uint, startTime, endTime time.Time, filters models.TaskFilters) ([]models.TaskWithTotalTime, error) {
-	return s.s.GetUserTasks(userID, startTime, endTime, filters.Asc)
+	tasks, err := s.s.GetUserTasks(userID, startTime, endTime, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	s.rounding.roundTasks(tasks)
+	return tasks, nil
+}
+
+func (s *UserService) SuspendUser(userID uint, effective time.Time) error {
+	return s.changeStatus(userID, models.UserSuspended, effective)
+}
+
+func (s *UserService) ReactivateUser(userID uint, effective time.Time) error {
+	return s.changeStatus(userID, models.UserActive, effective)
+}
+
+func (s *UserService) TerminateUser(userID uint, effective time.Time) error {
+	return s.changeStatus(userID, models.UserTerminated, effective)
+}
+
+// changeStatus moves user to the given status if the transition is allowed.
+// Status takes effect immediately, so effective date can not be in the future
+func (s *UserService) changeStatus(userID uint, to models.UserStatus, effective time.Time) error {
+	log := s.log.With(slog.String("op", "service.changeStatus"))
+
+	if effective.After(time.Now()) {
+		return ErrFutureEffectiveDate
+	}
+
+	user, err := s.s.GetUser(userID)
+	if err != nil {
+		return err
+	}
+
+	if !user.Status.CanTransitionTo(to) {
+		log.Warn("status transition is not allowed",
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("from", string(user.Status)),
+			slog.String("to", string(to)))
+		return ErrInvalidStatusTransition
+	}
+
+	return s.s.SetUserStatus(userID, to, effective)
+}
+
+// GetUserTags aggregates user tasks within the range by tag.
+// Task with several tags is counted in each of them
+func (s *UserService) GetUserTags(userID uint, startTime, endTime time.Time) ([]models.TagWithTotalTime, error) {
+	tasks, err := s.GetUserTasks(userID, startTime, endTime, models.TaskFilters{})
+	if err != nil {
+		return nil, err
+	}
+
+	type total struct {
+		tasks   int
+		seconds int64
+		money   models.Money
+	}
+	totals := make(map[string]*total)
+	for _, task := range tasks {
+		for _, tag := range task.Tags {
+			if _, ok := totals[tag.Name]; !ok {
+				totals[tag.Name] = &total{}
+			}
+			totals[tag.Name].tasks++
+			totals[tag.Name].seconds += task.TotalSeconds
+			totals[tag.Name].money = addMoney(totals[tag.Name].money, task.Money)
+		}
+	}
+
+	result := make([]models.TagWithTotalTime, 0, len(totals))
+	for name, t := range totals {
+		result = append(result, models.TagWithTotalTime{
+			Tag:        name,
+			TasksCount: t.tasks,
+			Duration:   newDuration(t.seconds),
+			Money:      t.money,
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].TotalSeconds > result[j].TotalSeconds
+	})
+
+	return result, nil
+}
+
+// GetUserSummary returns time worked by the user in every day, week or month of the range.
+// Bucket boundaries are local midnights in loc
+func (s *UserService) GetUserSummary(userID uint, from, to time.Time, groupBy models.GroupBy, loc *time.Location) ([]models.SummaryBucket, error) {
+	switch groupBy {
+	case models.GroupByDay, models.GroupByWeek, models.GroupByMonth:
+	default:
+		return nil, ErrInvalidGroupBy
+	}
+	if err := checkRange(from, to); err != nil {
+		return nil, err
+	}
+
+	periods, err := s.s.GetUserPeriods(userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	buckets := summarize(periods, from, to, time.Now(), groupBy, loc)
+	s.rounding.roundSummary(buckets)
+	return buckets, nil
+}
+
+// GetUserLocation returns time zone tz if set, otherwise the user time zone or server one
+func (s *UserService) GetUserLocation(userID uint, tz string) (*time.Location, error) {
+	if tz == "" {
+		user, err := s.s.GetUser(userID)
+		if err != nil {
+			return nil, err
+		}
+		tz = user.TimeZone
+	}
+
+	return loadLocation(tz)
 }
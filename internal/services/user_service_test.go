@@ -0,0 +1,40 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/moxicom/user_test/internal/models"
+)
+
+func TestGetUserTagsCountsTaskInEachTag(t *testing.T) {
+	s := &fakeStorage{
+		tasks: map[uint][]models.TaskWithTotalTime{
+			5: {
+				trackedTask(10, nil, 3600+30*60, "backend", "urgent"),
+				trackedTask(11, nil, 2*3600+5*60+7, "backend"),
+				trackedTask(12, nil, 600),
+			},
+		},
+	}
+	service := newUserService(s, discardLogger(), Rounding{})
+
+	tags, err := service.GetUserTags(5, time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(tags) != 2 {
+		t.Fatalf("expected 2 tags, got %+v", tags)
+	}
+	backend := tags[0]
+	if backend.Tag != "backend" || backend.TasksCount != 2 || backend.TotalSeconds != 3*3600+35*60+7 {
+		t.Errorf("first tag = %+v; expected backend with 2 tasks and 3h35m7s", backend)
+	}
+	if backend.DurationHours != 3 || backend.DurationMinutes != 35 || backend.DurationSeconds != 7 {
+		t.Errorf("backend duration = %dh %dm %ds; expected 3h 35m 7s", backend.DurationHours, backend.DurationMinutes, backend.DurationSeconds)
+	}
+	if tags[1].Tag != "urgent" || tags[1].TasksCount != 1 || tags[1].TotalSeconds != 3600+30*60 {
+		t.Errorf("second tag = %+v; expected urgent with 1 task and 1h30m", tags[1])
+	}
+}
@@ -0,0 +1,192 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/moxicom/user_test/internal/models"
+)
+
+// Cell styles defined in xlsxStyles
+const (
+	xlsxStyleDefault = iota
+	xlsxStyleBold
+	xlsxStyleHours
+	xlsxStyleBoldHours
+)
+
+// xlsxCell holds string or float64 value
+type xlsxCell struct {
+	Value any
+	Style int
+}
+
+type xlsxSheet struct {
+	Name   string
+	Widths []float64
+	Rows   [][]xlsxCell
+}
+
+// timesheetXLSX renders timesheet as workbook with hours per task and day and a summary sheet
+func timesheetXLSX(ts models.Timesheet, loc *time.Location) ([]byte, error) {
+	header := []xlsxCell{{"Task", xlsxStyleBold}}
+	widths := []float64{40}
+	for _, d := range ts.Days {
+		header = append(header, xlsxCell{d.In(loc).Format("Mon 2006-01-02"), xlsxStyleBold})
+		widths = append(widths, 15)
+	}
+	header = append(header, xlsxCell{"Total", xlsxStyleBold})
+	widths = append(widths, 12)
+
+	rows := [][]xlsxCell{header}
+	for _, r := range ts.Rows {
+		row := []xlsxCell{{r.TaskName, xlsxStyleDefault}}
+		for _, d := range r.Days {
+			row = append(row, xlsxCell{hours(d), xlsxStyleHours})
+		}
+		rows = append(rows, append(row, xlsxCell{hours(r.Total), xlsxStyleBoldHours}))
+	}
+	totals := []xlsxCell{{"Total", xlsxStyleBold}}
+	for _, d := range ts.DayTotals {
+		totals = append(totals, xlsxCell{hours(d), xlsxStyleBoldHours})
+	}
+	rows = append(rows, append(totals, xlsxCell{hours(ts.Total), xlsxStyleBoldHours}))
+
+	summary := [][]xlsxCell{
+		{{"User", xlsxStyleBold}, {strings.TrimSpace(ts.User.Surname + " " + ts.User.Name + " " + ts.User.Patronymic), xlsxStyleDefault}},
+		{{"Passport", xlsxStyleBold}, {ts.User.PassportNumber, xlsxStyleDefault}},
+		{{"Period start", xlsxStyleBold}, {ts.Start.In(loc).Format("2006-01-02"), xlsxStyleDefault}},
+		{{"Period end", xlsxStyleBold}, {ts.End.In(loc).AddDate(0, 0, -1).Format("2006-01-02"), xlsxStyleDefault}},
+		{{"Time zone", xlsxStyleBold}, {loc.String(), xlsxStyleDefault}},
+		{{"Tasks", xlsxStyleBold}, {float64(len(ts.Rows)), xlsxStyleDefault}},
+		{{"Total hours", xlsxStyleBold}, {hours(ts.Total), xlsxStyleBoldHours}},
+	}
+
+	return writeXLSX([]xlsxSheet{
+		{Name: "Timesheet", Widths: widths, Rows: rows},
+		{Name: "Summary", Widths: []float64{15, 40}, Rows: summary},
+	})
+}
+
+func hours(d models.Duration) float64 {
+	return float64(d.TotalSeconds) / 3600
+}
+
+// writeXLSX writes minimal Office Open XML workbook with inline strings
+func writeXLSX(sheets []xlsxSheet) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+
+	var contentTypes, workbook, workbookRels strings.Builder
+	contentTypes.WriteString(xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>`)
+	workbook.WriteString(xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+		`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`)
+	workbookRels.WriteString(xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+
+	files := make(map[string]string)
+	for i, sheet := range sheets {
+		n := i + 1
+		fmt.Fprintf(&contentTypes, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, n)
+		fmt.Fprintf(&workbook, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, xmlEscape(sheet.Name), n, n)
+		fmt.Fprintf(&workbookRels, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, n, n)
+		files[fmt.Sprintf("xl/worksheets/sheet%d.xml", n)] = sheetXML(sheet)
+	}
+	fmt.Fprintf(&workbookRels, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`, len(sheets)+1)
+	contentTypes.WriteString(`</Types>`)
+	workbook.WriteString(`</sheets></workbook>`)
+	workbookRels.WriteString(`</Relationships>`)
+
+	files["[Content_Types].xml"] = contentTypes.String()
+	files["_rels/.rels"] = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`
+	files["xl/workbook.xml"] = workbook.String()
+	files["xl/_rels/workbook.xml.rels"] = workbookRels.String()
+	files["xl/styles.xml"] = xlsxStyles
+
+	// [Content_Types].xml goes first as some readers expect
+	order := []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/styles.xml"}
+	for i := range sheets {
+		order = append(order, fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1))
+	}
+	for _, name := range order {
+		w, err := zw.Create(name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write([]byte(files[name])); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func sheetXML(sheet xlsxSheet) string {
+	var b strings.Builder
+	b.WriteString(xml.Header + `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	if len(sheet.Widths) > 0 {
+		b.WriteString(`<cols>`)
+		for i, w := range sheet.Widths {
+			fmt.Fprintf(&b, `<col min="%d" max="%d" width="%g" customWidth="1"/>`, i+1, i+1, w)
+		}
+		b.WriteString(`</cols>`)
+	}
+	b.WriteString(`<sheetData>`)
+	for i, row := range sheet.Rows {
+		fmt.Fprintf(&b, `<row r="%d">`, i+1)
+		for j, cell := range row {
+			ref := xlsxColumn(j) + strconv.Itoa(i+1)
+			switch v := cell.Value.(type) {
+			case float64:
+				fmt.Fprintf(&b, `<c r="%s" s="%d"><v>%s</v></c>`, ref, cell.Style, strconv.FormatFloat(v, 'f', -1, 64))
+			default:
+				fmt.Fprintf(&b, `<c r="%s" s="%d" t="inlineStr"><is><t>%s</t></is></c>`, ref, cell.Style, xmlEscape(fmt.Sprint(v)))
+			}
+		}
+		b.WriteString(`</row>`)
+	}
+	b.WriteString(`</sheetData></worksheet>`)
+	return b.String()
+}
+
+// xlsxColumn returns column letters of zero based index: A, B, ..., Z, AA
+func xlsxColumn(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
+
+func xmlEscape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+const xlsxStyles = xml.Header + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="4">` +
+	`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+	`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>` +
+	`<xf numFmtId="2" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="2" fontId="1" fillId="0" borderId="0" xfId="0" applyNumberFormat="1" applyFont="1"/>` +
+	`</cellXfs>` +
+	`<cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles>` +
+	`</styleSheet>`
@@ -7,7 +7,37 @@ import (
 	"gorm.io/gorm"
 )
 
-func MigratePostgres(db *gorm.DB, log *slog.Logger) {
+// MigratePostgres migrates the schema. It reports whether the daily rollup
+// was added or changed and should be rebuilt from periods
+func MigratePostgres(db *gorm.DB, log *slog.Logger) bool {
 	log.Info("Making automigration...")
-	db.AutoMigrate(&models.User{}, &models.Task{}, &models.TaskPeriod{})
+	hadIsFinished := db.Migrator().HasColumn("tasks", "is_finished")
+	hadDailyTotals := db.Migrator().HasTable(&models.DailyTotal{})
+	hadBillableTotals := db.Migrator().HasColumn(&models.DailyTotal{}, "billable_seconds")
+	db.AutoMigrate(&models.User{}, &models.Task{}, &models.TaskPeriod{}, &models.Team{}, &models.TeamMember{}, &models.Project{}, &models.Tag{}, &models.DailyTotal{}, &models.TimesheetSubmission{}, &models.Rate{}, &models.Invoice{}, &models.InvoiceItem{}, &models.TaskAlert{})
+
+	if hadIsFinished {
+		migrateTaskStatus(db, log)
+	}
+	return !hadDailyTotals || !hadBillableTotals
+}
+
+// migrateTaskStatus replaces legacy is_finished flag with task status
+func migrateTaskStatus(db *gorm.DB, log *slog.Logger) {
+	log.Info("Migrating tasks is_finished to status...")
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`
+			UPDATE tasks SET status = CASE
+				WHEN is_finished THEN 'done'
+				WHEN EXISTS (SELECT 1 FROM task_periods p WHERE p.task_id = tasks.id AND p.end_time IS NULL) THEN 'in_progress'
+				WHEN EXISTS (SELECT 1 FROM task_periods p WHERE p.task_id = tasks.id) THEN 'paused'
+				ELSE 'todo'
+			END`).Error; err != nil {
+			return err
+		}
+		return tx.Migrator().DropColumn("tasks", "is_finished")
+	})
+	if err != nil {
+		log.Error("failed to migrate task status", slog.Any("err", err))
+	}
 }
@@ -0,0 +1,68 @@
+package postgres
+
+import (
+	"log/slog"
+
+	"github.com/moxicom/user_test/internal/models"
+	"gorm.io/gorm/clause"
+)
+
+// GetEstimatedTasks returns tasks with an estimate that are not done or
+// archived with their tracked time and the highest threshold alerted
+func (p *PgStorage) GetEstimatedTasks() ([]models.EstimatedTask, error) {
+	log := p.log.With(slog.String("op", "PgStorage.GetEstimatedTasks"))
+	var tasks []models.EstimatedTask
+
+	err := p.db.Model(&models.Task{}).
+		Select(`tasks.id, tasks.user_id, tasks.task_name, tasks.estimate, `+trackedColumn+`,
+            COALESCE((SELECT MAX(threshold) FROM task_alerts WHERE task_alerts.task_id = tasks.id), 0) AS alerted`).
+		Where("tasks.estimate > 0 AND tasks.status NOT IN ?", []models.TaskStatus{models.TaskDone, models.TaskArchived}).
+		Scan(&tasks).Error
+	if err != nil {
+		log.Error("failed to get estimated tasks", slog.Any("err", err))
+		return []models.EstimatedTask{}, err
+	}
+
+	return tasks, nil
+}
+
+// CreateTaskAlerts records alerts skipping thresholds already alerted
+func (p *PgStorage) CreateTaskAlerts(alerts []models.TaskAlert) error {
+	log := p.log.With(slog.String("op", "PgStorage.CreateTaskAlerts"))
+	if len(alerts) == 0 {
+		return nil
+	}
+
+	if err := p.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&alerts).Error; err != nil {
+		log.Error("failed to add task alerts", slog.Any("err", err))
+		return err
+	}
+
+	return nil
+}
+
+// GetTaskAlerts returns alerts of tasks, latest first
+func (p *PgStorage) GetTaskAlerts(filters models.AlertFilters) ([]models.TaskAlert, error) {
+	log := p.log.With(slog.String("op", "PgStorage.GetTaskAlerts"))
+	var alerts []models.TaskAlert
+
+	query := p.db.Model(&models.TaskAlert{})
+	if filters.TaskID != 0 {
+		query = query.Where("task_alerts.task_id = ?", filters.TaskID)
+	}
+	if filters.UserID != 0 {
+		query = query.Where("task_alerts.user_id = ?", filters.UserID)
+	}
+	if filters.ProjectID != 0 {
+		query = query.
+			Joins("JOIN tasks ON tasks.id = task_alerts.task_id").
+			Where("tasks.project_id = ?", filters.ProjectID)
+	}
+
+	if err := query.Order("task_alerts.created_at DESC, task_alerts.id DESC").Find(&alerts).Error; err != nil {
+		log.Error("failed to get task alerts", slog.Any("err", err))
+		return []models.TaskAlert{}, err
+	}
+
+	return alerts, nil
+}
@@ -0,0 +1,137 @@
+package postgres
+
+import (
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/moxicom/user_test/internal/models"
+	"github.com/moxicom/user_test/internal/storage"
+	"gorm.io/gorm"
+)
+
+// ImportPeriods adds finished periods in one transaction skipping already
+// imported external ids, periods within approved timesheets and periods
+// overlapping others of the task. With
+// dryRun the result is computed but nothing is saved
+func (p *PgStorage) ImportPeriods(entries []models.ImportEntry, dryRun bool) (models.ImportResult, error) {
+	log := p.log.With(slog.String("op", "PgStorage.ImportPeriods"))
+	var result models.ImportResult
+
+	tx := p.db.Begin()
+	defer tx.Rollback()
+
+	for _, e := range entries {
+		if e.ExternalID != "" {
+			imported, err := periodImported(tx, e.UserID, e.ExternalID)
+			if err != nil {
+				log.Error("failed to check imported period", slog.Any("err", err))
+				return result, err
+			}
+			if imported {
+				result.Duplicates++
+				continue
+			}
+		}
+
+		if err := checkUnlocked(tx, e.UserID, e.StartTime, e.EndTime); err != nil {
+			if errors.Is(err, storage.ErrPeriodLocked) {
+				result.Locked++
+				continue
+			}
+			log.Error("failed to check approved timesheets", slog.Any("err", err))
+			return result, err
+		}
+
+		task, created, err := importTask(tx, e)
+		if err != nil {
+			log.Error("failed to get import task", slog.Uint64("user_id", uint64(e.UserID)), slog.Any("err", err))
+			return result, err
+		}
+		if created {
+			result.TasksCreated++
+		}
+
+		overlap, err := periodOverlaps(tx, task.ID, 0, e.StartTime, e.EndTime)
+		if err != nil {
+			log.Error("failed to check period overlap", slog.Any("err", err))
+			return result, err
+		}
+		if overlap {
+			result.Overlapping++
+			continue
+		}
+
+		period := models.TaskPeriod{TaskID: task.ID, StartTime: &e.StartTime, EndTime: &e.EndTime, Billable: e.Billable}
+		if e.ExternalID != "" {
+			period.ExternalID = &e.ExternalID
+		}
+		if err := tx.Create(&period).Error; err != nil {
+			log.Error("failed to add period", slog.Uint64("task_id", uint64(task.ID)), slog.Any("err", err))
+			return result, err
+		}
+
+		if err := refreshDailyTotals(tx, task.ID, e.StartTime, e.EndTime); err != nil {
+			log.Error("failed to refresh daily totals", slog.Uint64("task_id", uint64(task.ID)), slog.Any("err", err))
+			return result, err
+		}
+
+		if task.Status == models.TaskTodo {
+			if err := setTaskStatus(tx, task.ID, models.TaskPaused); err != nil {
+				log.Error("failed to set task status", slog.Uint64("task_id", uint64(task.ID)), slog.Any("err", err))
+				return result, err
+			}
+		}
+
+		result.Imported++
+	}
+
+	if dryRun {
+		return result, nil
+	}
+
+	return result, tx.Commit().Error
+}
+
+// periodImported reports whether the user already has period with the external id
+func periodImported(tx *gorm.DB, userID uint, externalID string) (bool, error) {
+	var count int64
+	err := tx.Model(&models.TaskPeriod{}).
+		Joins("JOIN tasks ON tasks.id = task_periods.task_id").
+		Where("tasks.user_id = ? AND task_periods.external_id = ?", userID, externalID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// importTask returns the latest open task of the user with entry task name and
+// project or creates it
+func importTask(tx *gorm.DB, e models.ImportEntry) (models.Task, bool, error) {
+	var task models.Task
+
+	query := tx.Where("user_id = ? AND task_name = ? AND status NOT IN ?",
+		e.UserID, e.TaskName, []models.TaskStatus{models.TaskDone, models.TaskArchived})
+	if e.ProjectID != nil {
+		query = query.Where("project_id = ?", *e.ProjectID)
+	}
+
+	err := query.Order("id DESC").First(&task).Error
+	if err == nil {
+		return task, false, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return task, false, err
+	}
+
+	task = models.Task{
+		UserID:    e.UserID,
+		ProjectID: e.ProjectID,
+		TaskName:  e.TaskName,
+		CreatedAt: time.Now(),
+		Status:    models.TaskPaused,
+	}
+	if err := tx.Create(&task).Error; err != nil {
+		return task, false, err
+	}
+
+	return task, true, nil
+}
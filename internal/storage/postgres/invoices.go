@@ -0,0 +1,195 @@
+package postgres
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/moxicom/user_test/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CreateInvoice bills unbilled billable periods of the selection. The periods
+// are locked and priced by build in the same transaction, so time can not
+// change or be invoiced by another request in between. The invoice is
+// numbered within the year it is issued
+func (p *PgStorage) CreateInvoice(sel models.InvoiceSelection, build func([]models.InvoiceLine) (models.Invoice, error)) (models.Invoice, error) {
+	log := p.log.With(slog.String("op", "PgStorage.CreateInvoice"))
+
+	tx := p.db.Begin()
+	defer tx.Rollback()
+
+	// numbers are taken under the table lock so concurrent invoices do not share one
+	if err := tx.Exec("LOCK TABLE invoices IN SHARE ROW EXCLUSIVE MODE").Error; err != nil {
+		log.Error("failed to lock invoices", slog.Any("err", err))
+		return models.Invoice{}, err
+	}
+
+	var periodIDs []uint
+	err := invoicePeriods(tx, sel).
+		Clauses(clause.Locking{Strength: "UPDATE", Table: clause.Table{Name: "tp"}}).
+		Order("tp.id").
+		Pluck("tp.id", &periodIDs).Error
+	if err != nil {
+		log.Error("failed to lock unbilled periods", slog.Any("err", err))
+		return models.Invoice{}, err
+	}
+
+	lines, err := invoiceLines(tx, periodIDs)
+	if err != nil {
+		log.Error("failed to get invoice lines", slog.Any("err", err))
+		return models.Invoice{}, err
+	}
+
+	invoice, err := build(lines)
+	if err != nil {
+		return models.Invoice{}, err
+	}
+
+	prefix := fmt.Sprintf("%d-", invoice.IssuedAt.Year())
+	var last int
+	err = tx.Model(&models.Invoice{}).
+		Select("COALESCE(MAX(CAST(SUBSTRING(number FROM ?) AS integer)), 0)", len(prefix)+1).
+		Where("number LIKE ?", prefix+"%").
+		Scan(&last).Error
+	if err != nil {
+		log.Error("failed to get last invoice number", slog.Any("err", err))
+		return models.Invoice{}, err
+	}
+	invoice.Number = fmt.Sprintf("%s%04d", prefix, last+1)
+
+	if err := tx.Create(&invoice).Error; err != nil {
+		log.Error("failed to add invoice", slog.Any("err", err))
+		return models.Invoice{}, err
+	}
+
+	if len(periodIDs) > 0 {
+		err = tx.Model(&models.TaskPeriod{}).Where("id IN ?", periodIDs).Update("invoice_id", invoice.ID).Error
+		if err != nil {
+			log.Error("failed to mark periods invoiced", slog.Any("err", err))
+			return models.Invoice{}, err
+		}
+	}
+	log.Debug("periods invoiced", slog.String("number", invoice.Number), slog.Int("periods", len(periodIDs)))
+
+	return invoice, tx.Commit().Error
+}
+
+// GetInvoices returns invoices with their items, latest first
+func (p *PgStorage) GetInvoices(filters models.InvoiceFilters) ([]models.Invoice, error) {
+	log := p.log.With(slog.String("op", "PgStorage.GetInvoices"))
+	var invoices []models.Invoice
+
+	query := p.db.Model(&models.Invoice{}).Preload("Items", orderItems)
+	if filters.ProjectID != 0 {
+		query = query.Where("project_id = ?", filters.ProjectID)
+	}
+	if filters.Client != "" {
+		query = query.Where("LOWER(client) = LOWER(?)", filters.Client)
+	}
+
+	if err := query.Order("issued_at DESC, id DESC").Find(&invoices).Error; err != nil {
+		log.Error("failed to get invoices", slog.Any("err", err))
+		return []models.Invoice{}, err
+	}
+
+	return invoices, nil
+}
+
+func (p *PgStorage) GetInvoice(invoiceID uint) (models.Invoice, error) {
+	log := p.log.With(slog.String("op", "PgStorage.GetInvoice"))
+	var invoice models.Invoice
+
+	if err := p.db.Preload("Items", orderItems).First(&invoice, invoiceID).Error; err != nil {
+		log.Error("failed to get invoice", slog.Uint64("invoice_id", uint64(invoiceID)), slog.Any("err", err))
+		return models.Invoice{}, err
+	}
+
+	return invoice, nil
+}
+
+// DeleteInvoice deletes the invoice with its items. Its periods become
+// unbilled and can be invoiced again
+func (p *PgStorage) DeleteInvoice(invoiceID uint) error {
+	log := p.log.With(slog.String("op", "PgStorage.DeleteInvoice"))
+	tx := p.db.Begin()
+	defer tx.Rollback()
+
+	err := tx.Model(&models.TaskPeriod{}).
+		Where("invoice_id = ?", invoiceID).
+		Update("invoice_id", nil).Error
+	if err != nil {
+		log.Error("failed to release invoiced periods", slog.Uint64("invoice_id", uint64(invoiceID)), slog.Any("err", err))
+		return err
+	}
+
+	if err := tx.Delete(&models.Invoice{}, invoiceID).Error; err != nil {
+		log.Error("failed to delete invoice. Rolled back", slog.Any("err", err))
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// invoicePeriods selects unbilled billable finished periods of the selection
+func invoicePeriods(db *gorm.DB, sel models.InvoiceSelection) *gorm.DB {
+	query := db.Table("task_periods AS tp").
+		Joins("JOIN tasks ON tasks.id = tp.task_id").
+		Where("tp.invoice_id IS NULL AND tp.billable AND tp.end_time IS NOT NULL").
+		Where("tp.start_time >= ? AND tp.start_time < ?", sel.From, sel.To)
+	if sel.ProjectID != 0 {
+		query = query.Where("tasks.project_id = ?", sel.ProjectID)
+	}
+	if sel.Client != "" {
+		query = query.
+			Joins("JOIN projects ON projects.id = tasks.project_id").
+			Where("LOWER(projects.client) = LOWER(?)", sel.Client)
+	}
+	return query
+}
+
+// invoiceLines groups time of the periods by task and hourly rate. Periods
+// are billed whole, each UTC day at the rate effective on it
+func invoiceLines(tx *gorm.DB, periodIDs []uint) ([]models.InvoiceLine, error) {
+	lines := []models.InvoiceLine{}
+	if len(periodIDs) == 0 {
+		return lines, nil
+	}
+
+	pieces := tx.Table("task_periods AS tp").
+		Select("tp.task_id, d.day, s.seconds").
+		Joins(`CROSS JOIN LATERAL generate_series(
+                date_trunc('day', tp.start_time AT TIME ZONE 'UTC') AT TIME ZONE 'UTC', tp.end_time, INTERVAL '24 hours'
+            ) AS d(day)`).
+		Joins(`CROSS JOIN LATERAL (SELECT EXTRACT(EPOCH FROM
+                LEAST(tp.end_time, d.day + INTERVAL '24 hours') - GREATEST(tp.start_time, d.day)
+            ) AS seconds) AS s`).
+		Where("tp.id IN ? AND d.day < tp.end_time", periodIDs)
+
+	err := tx.Table("(?) AS pieces", pieces).
+		Select(`pieces.task_id, tasks.task_name, users.surname, users.name,
+            COALESCE(projects.name, '') AS project_name,
+            COALESCE(rate.hourly_rate, 0) AS hourly_rate,
+            ROUND(SUM(pieces.seconds))::bigint AS seconds`).
+		Joins("JOIN tasks ON tasks.id = pieces.task_id").
+		Joins("JOIN users ON users.id = tasks.user_id").
+		Joins("LEFT JOIN projects ON projects.id = tasks.project_id").
+		Joins(dayRate).
+		Group("pieces.task_id, tasks.task_name, users.surname, users.name, projects.name, rate.hourly_rate").
+		Order("tasks.task_name, pieces.task_id, hourly_rate").
+		Scan(&lines).Error
+	return lines, err
+}
+
+// taskInvoiced reports whether any period of the task is invoiced
+func taskInvoiced(tx *gorm.DB, taskID uint) (bool, error) {
+	var count int64
+	err := tx.Model(&models.TaskPeriod{}).
+		Where("task_id = ? AND invoice_id IS NOT NULL", taskID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func orderItems(db *gorm.DB) *gorm.DB {
+	return db.Order("position")
+}
@@ -0,0 +1,63 @@
+package postgres
+
+import (
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/moxicom/user_test/internal/models"
+)
+
+// invoicingDB answers as a database with unbilled periods 1 and 2 of task 7
+// and last invoice 2024-0003
+func invoicingDB() *fakeDB {
+	return &fakeDB{respond: func(query string, _ []driver.Value) fakeResult {
+		switch {
+		case strings.Contains(query, "FOR UPDATE"):
+			return fakeResult{columns: []string{"id"}, rows: [][]driver.Value{{int64(1)}, {int64(2)}}}
+		case strings.Contains(query, "AS pieces"):
+			return fakeResult{
+				columns: []string{"task_id", "task_name", "hourly_rate", "seconds"},
+				rows:    [][]driver.Value{{int64(7), "Design", []byte("50.00"), int64(5400)}},
+			}
+		case strings.Contains(query, "COALESCE(MAX"):
+			return fakeResult{columns: []string{"last"}, rows: [][]driver.Value{{int64(3)}}}
+		case strings.HasPrefix(query, `INSERT INTO "invoices"`):
+			return fakeResult{columns: []string{"id"}, rows: [][]driver.Value{{int64(9)}}}
+		}
+		return fakeResult{affected: 2}
+	}}
+}
+
+func TestCreateInvoice(t *testing.T) {
+	store := newFakeStorage(t, invoicingDB())
+
+	var built []models.InvoiceLine
+	invoice, err := store.CreateInvoice(models.InvoiceSelection{ProjectID: 4}, func(lines []models.InvoiceLine) (models.Invoice, error) {
+		built = lines
+		return models.Invoice{IssuedAt: time.Date(2024, 8, 2, 0, 0, 0, 0, time.UTC)}, nil
+	})
+	if err != nil {
+		t.Fatalf("CreateInvoice: %v", err)
+	}
+
+	if len(built) != 1 || built[0].TaskID != 7 || built[0].HourlyRate != 5000 || built[0].Seconds != 5400 {
+		t.Errorf("lines = %+v; expected 1.5h of task 7 at 50.00", built)
+	}
+	if invoice.ID != 9 || invoice.Number != "2024-0004" {
+		t.Errorf("invoice = %d %q; expected 9 2024-0004", invoice.ID, invoice.Number)
+	}
+}
+
+func TestCreateInvoiceBuildError(t *testing.T) {
+	failed := errors.New("nothing to invoice")
+
+	invoice, err := newFakeStorage(t, invoicingDB()).CreateInvoice(models.InvoiceSelection{Client: "ACME"}, func([]models.InvoiceLine) (models.Invoice, error) {
+		return models.Invoice{}, failed
+	})
+	if !errors.Is(err, failed) || invoice.ID != 0 {
+		t.Errorf("invoice %d, err = %v; expected none and %v", invoice.ID, err, failed)
+	}
+}
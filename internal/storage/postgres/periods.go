@@ -0,0 +1,394 @@
+package postgres
+
+import (
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/moxicom/user_test/internal/models"
+	"github.com/moxicom/user_test/internal/storage"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func (p *PgStorage) GetPeriod(periodID uint) (models.TaskPeriod, error) {
+	log := p.log.With(slog.String("op", "PgStorage.GetPeriod"))
+	var period models.TaskPeriod
+
+	err := p.db.First(&period, periodID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Warn("period not found", slog.Uint64("period_id", uint64(periodID)))
+		return models.TaskPeriod{}, storage.ErrPeriodNotFound
+	}
+	if err != nil {
+		log.Error("failed to get period", slog.Uint64("period_id", uint64(periodID)), slog.Any("err", err))
+		return models.TaskPeriod{}, err
+	}
+
+	return period, nil
+}
+
+// AddPeriod adds a finished period to the task. Task without periods becomes paused
+func (p *PgStorage) AddPeriod(period models.TaskPeriod) (uint, error) {
+	log := p.log.With(slog.String("op", "PgStorage.AddPeriod"))
+
+	tx := p.db.Begin()
+	defer tx.Rollback()
+
+	task, err := lockTask(tx, period.TaskID)
+	if err != nil {
+		log.Error("failed to lock task", slog.Uint64("task_id", uint64(period.TaskID)), slog.Any("err", err))
+		return 0, err
+	}
+
+	if err := checkUnlocked(tx, task.UserID, *period.StartTime, *period.EndTime); err != nil {
+		log.Warn("period can not be added", slog.Uint64("task_id", uint64(period.TaskID)), slog.Any("err", err))
+		return 0, err
+	}
+
+	overlap, err := periodOverlaps(tx, period.TaskID, 0, *period.StartTime, *period.EndTime)
+	if err != nil {
+		log.Error("failed to check period overlap", slog.Any("err", err))
+		return 0, err
+	}
+	if overlap {
+		log.Warn("period overlaps", slog.Uint64("task_id", uint64(period.TaskID)))
+		return 0, storage.ErrPeriodOverlap
+	}
+
+	if err := tx.Create(&period).Error; err != nil {
+		log.Error("failed to add period", slog.Uint64("task_id", uint64(period.TaskID)), slog.Any("err", err))
+		return 0, err
+	}
+
+	if err := refreshDailyTotals(tx, period.TaskID, *period.StartTime, *period.EndTime); err != nil {
+		log.Error("failed to refresh daily totals", slog.Uint64("task_id", uint64(period.TaskID)), slog.Any("err", err))
+		return 0, err
+	}
+
+	if task.Status == models.TaskTodo {
+		if err := setTaskStatus(tx, task.ID, models.TaskPaused); err != nil {
+			log.Error("failed to set task status", slog.Uint64("task_id", uint64(task.ID)), slog.Any("err", err))
+			return 0, err
+		}
+	}
+
+	return period.ID, tx.Commit().Error
+}
+
+func (p *PgStorage) UpdatePeriod(periodID uint, startTime time.Time, endTime time.Time) error {
+	log := p.log.With(slog.String("op", "PgStorage.UpdatePeriod"))
+	var period models.TaskPeriod
+
+	tx := p.db.Begin()
+	defer tx.Rollback()
+
+	err := tx.First(&period, periodID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return storage.ErrPeriodNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if period.InvoiceID != nil {
+		log.Warn("period can not be edited. It is invoiced", slog.Uint64("period_id", uint64(periodID)))
+		return storage.ErrPeriodInvoiced
+	}
+	if period.EndTime == nil {
+		log.Warn("period can not be edited. Should be finished", slog.Uint64("period_id", uint64(periodID)))
+		return storage.ErrPeriodNotFinished
+	}
+
+	task, err := lockTask(tx, period.TaskID)
+	if err != nil {
+		log.Error("failed to lock task", slog.Uint64("task_id", uint64(period.TaskID)), slog.Any("err", err))
+		return err
+	}
+
+	// neither old nor new bounds may touch an approved week
+	if err := checkUnlocked(tx, task.UserID, *period.StartTime, *period.EndTime); err != nil {
+		log.Warn("period can not be edited", slog.Uint64("period_id", uint64(periodID)), slog.Any("err", err))
+		return err
+	}
+	if err := checkUnlocked(tx, task.UserID, startTime, endTime); err != nil {
+		log.Warn("period can not be edited", slog.Uint64("period_id", uint64(periodID)), slog.Any("err", err))
+		return err
+	}
+
+	overlap, err := periodOverlaps(tx, period.TaskID, period.ID, startTime, endTime)
+	if err != nil {
+		log.Error("failed to check period overlap", slog.Any("err", err))
+		return err
+	}
+	if overlap {
+		log.Warn("period overlaps", slog.Uint64("period_id", uint64(periodID)))
+		return storage.ErrPeriodOverlap
+	}
+
+	// days of both old and new bounds change
+	refreshFrom, refreshTo := *period.StartTime, *period.EndTime
+	if startTime.Before(refreshFrom) {
+		refreshFrom = startTime
+	}
+	if endTime.After(refreshTo) {
+		refreshTo = endTime
+	}
+
+	period.StartTime = &startTime
+	period.EndTime = &endTime
+	if err := tx.Save(&period).Error; err != nil {
+		log.Error("failed to update period", slog.Uint64("period_id", uint64(periodID)), slog.Any("err", err))
+		return err
+	}
+
+	if err := refreshDailyTotals(tx, period.TaskID, refreshFrom, refreshTo); err != nil {
+		log.Error("failed to refresh daily totals", slog.Uint64("task_id", uint64(period.TaskID)), slog.Any("err", err))
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// SetPeriodBillable marks the period billable or non-billable
+func (p *PgStorage) SetPeriodBillable(periodID uint, billable bool) error {
+	log := p.log.With(slog.String("op", "PgStorage.SetPeriodBillable"))
+	var period models.TaskPeriod
+
+	tx := p.db.Begin()
+	defer tx.Rollback()
+
+	err := tx.First(&period, periodID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return storage.ErrPeriodNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if period.InvoiceID != nil {
+		log.Warn("period can not be changed. It is invoiced", slog.Uint64("period_id", uint64(periodID)))
+		return storage.ErrPeriodInvoiced
+	}
+
+	task, err := lockTask(tx, period.TaskID)
+	if err != nil {
+		log.Error("failed to lock task", slog.Uint64("task_id", uint64(period.TaskID)), slog.Any("err", err))
+		return err
+	}
+
+	end := time.Now()
+	if period.EndTime != nil {
+		end = *period.EndTime
+	}
+	if err := checkUnlocked(tx, task.UserID, *period.StartTime, end); err != nil {
+		log.Warn("period can not be changed", slog.Uint64("period_id", uint64(periodID)), slog.Any("err", err))
+		return err
+	}
+
+	if err := tx.Model(&period).Update("billable", billable).Error; err != nil {
+		log.Error("failed to set period billable", slog.Uint64("period_id", uint64(periodID)), slog.Any("err", err))
+		return err
+	}
+
+	if period.EndTime != nil {
+		if err := refreshDailyTotals(tx, task.ID, *period.StartTime, *period.EndTime); err != nil {
+			log.Error("failed to refresh daily totals", slog.Uint64("task_id", uint64(task.ID)), slog.Any("err", err))
+			return err
+		}
+	}
+
+	return tx.Commit().Error
+}
+
+// DeletePeriod deletes a finished period. Paused task without periods becomes todo
+func (p *PgStorage) DeletePeriod(periodID uint) error {
+	log := p.log.With(slog.String("op", "PgStorage.DeletePeriod"))
+	var period models.TaskPeriod
+
+	tx := p.db.Begin()
+	defer tx.Rollback()
+
+	err := tx.First(&period, periodID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return storage.ErrPeriodNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if period.InvoiceID != nil {
+		log.Warn("period can not be deleted. It is invoiced", slog.Uint64("period_id", uint64(periodID)))
+		return storage.ErrPeriodInvoiced
+	}
+	if period.EndTime == nil {
+		log.Warn("period can not be deleted. Should be finished", slog.Uint64("period_id", uint64(periodID)))
+		return storage.ErrPeriodNotFinished
+	}
+
+	task, err := lockTask(tx, period.TaskID)
+	if err != nil {
+		log.Error("failed to lock task", slog.Uint64("task_id", uint64(period.TaskID)), slog.Any("err", err))
+		return err
+	}
+
+	if err := checkUnlocked(tx, task.UserID, *period.StartTime, *period.EndTime); err != nil {
+		log.Warn("period can not be deleted", slog.Uint64("period_id", uint64(periodID)), slog.Any("err", err))
+		return err
+	}
+
+	if err := tx.Delete(&period).Error; err != nil {
+		log.Error("failed to delete period. Rolled back", slog.Any("err", err))
+		return err
+	}
+
+	if err := refreshDailyTotals(tx, task.ID, *period.StartTime, *period.EndTime); err != nil {
+		log.Error("failed to refresh daily totals", slog.Uint64("task_id", uint64(task.ID)), slog.Any("err", err))
+		return err
+	}
+
+	var left int64
+	if err := tx.Model(&models.TaskPeriod{}).Where("task_id = ?", task.ID).Count(&left).Error; err != nil {
+		return err
+	}
+	if left == 0 && task.Status == models.TaskPaused {
+		if err := setTaskStatus(tx, task.ID, models.TaskTodo); err != nil {
+			log.Error("failed to set task status", slog.Uint64("task_id", uint64(task.ID)), slog.Any("err", err))
+			return err
+		}
+	}
+
+	return tx.Commit().Error
+}
+
+// GetRunningPeriods returns periods without end of the user or of all users if userID is 0
+func (p *PgStorage) GetRunningPeriods(userID uint) ([]models.RunningPeriod, error) {
+	log := p.log.With(slog.String("op", "PgStorage.GetRunningPeriods"))
+	var periods []models.RunningPeriod
+
+	query := p.db.Model(&models.TaskPeriod{}).
+		Select("task_periods.*, tasks.task_name, tasks.user_id, users.surname, users.name, users.workday_end, users.time_zone").
+		Joins("JOIN tasks ON tasks.id = task_periods.task_id").
+		Joins("JOIN users ON users.id = tasks.user_id").
+		Where("task_periods.end_time IS NULL")
+	if userID != 0 {
+		query = query.Where("tasks.user_id = ?", userID)
+	}
+
+	if err := query.Order("task_periods.start_time").Find(&periods).Error; err != nil {
+		log.Error("failed to get running periods", slog.Any("err", err))
+		return []models.RunningPeriod{}, err
+	}
+
+	return periods, nil
+}
+
+// GetUserPeriods returns periods of the user overlapping [from, to] with their tasks
+func (p *PgStorage) GetUserPeriods(userID uint, from time.Time, to time.Time) ([]models.ReportPeriod, error) {
+	log := p.log.With(slog.String("op", "PgStorage.GetUserPeriods"))
+	var periods []models.ReportPeriod
+
+	res := p.db.Model(&models.TaskPeriod{}).
+		Select("task_periods.*, tasks.task_name, tasks.user_id, tasks.project_id").
+		Joins("JOIN tasks ON tasks.id = task_periods.task_id").
+		Where("tasks.user_id = ?", userID).
+		Where("task_periods.start_time < ? AND COALESCE(task_periods.end_time, CURRENT_TIMESTAMP) > ?", to, from).
+		Order("task_periods.start_time").
+		Find(&periods)
+	if res.Error != nil {
+		log.Error("failed to get user periods", slog.Uint64("user_id", uint64(userID)), slog.Any("err", res.Error))
+		return []models.ReportPeriod{}, res.Error
+	}
+
+	return periods, nil
+}
+
+// AutoClosePeriod ends a running period marking it auto-closed. Period ended
+// meanwhile is left untouched, period within an approved timesheet is not
+// closed and ErrPeriodLocked is returned
+func (p *PgStorage) AutoClosePeriod(periodID uint, endTime time.Time) error {
+	log := p.log.With(slog.String("op", "PgStorage.AutoClosePeriod"))
+	var period models.TaskPeriod
+
+	tx := p.db.Begin()
+	defer tx.Rollback()
+
+	err := tx.First(&period, periodID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return storage.ErrPeriodNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	task, err := lockTask(tx, period.TaskID)
+	if err != nil {
+		log.Error("failed to lock task", slog.Uint64("task_id", uint64(period.TaskID)), slog.Any("err", err))
+		return err
+	}
+
+	// period may have been ended before the task was locked
+	if err := tx.First(&period, periodID).Error; err != nil {
+		return err
+	}
+	if period.EndTime != nil {
+		return nil
+	}
+
+	if err := checkUnlocked(tx, task.UserID, *period.StartTime, endTime); err != nil {
+		log.Warn("period can not be auto-closed", slog.Uint64("period_id", uint64(periodID)), slog.Any("err", err))
+		return err
+	}
+
+	period.EndTime = &endTime
+	period.AutoClosed = true
+	if err := tx.Save(&period).Error; err != nil {
+		log.Error("failed to close period", slog.Uint64("period_id", uint64(periodID)), slog.Any("err", err))
+		return err
+	}
+
+	if err := refreshDailyTotals(tx, period.TaskID, *period.StartTime, endTime); err != nil {
+		log.Error("failed to refresh daily totals", slog.Uint64("task_id", uint64(period.TaskID)), slog.Any("err", err))
+		return err
+	}
+
+	if err := setTaskStatus(tx, period.TaskID, models.TaskPaused); err != nil {
+		log.Error("failed to set task status", slog.Uint64("task_id", uint64(period.TaskID)), slog.Any("err", err))
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// lockTask selects the task for update so concurrent period changes are serialized
+func lockTask(tx *gorm.DB, taskID uint) (models.Task, error) {
+	var task models.Task
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&task, taskID).Error
+	return task, err
+}
+
+// lockUser selects the user for update so concurrent timer starts are serialized
+func lockUser(tx *gorm.DB, userID uint) (models.User, error) {
+	var user models.User
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, userID).Error
+	return user, err
+}
+
+// userOpenPeriods returns running periods across all tasks of the user
+func userOpenPeriods(tx *gorm.DB, userID uint) ([]models.TaskPeriod, error) {
+	var periods []models.TaskPeriod
+	err := tx.Model(&models.TaskPeriod{}).
+		Select("task_periods.*").
+		Joins("JOIN tasks ON tasks.id = task_periods.task_id").
+		Where("tasks.user_id = ? AND task_periods.end_time IS NULL", userID).
+		Find(&periods).Error
+	return periods, err
+}
+
+// periodOverlaps reports whether [start, end) intersects any period of the task
+// except the one with exceptID. Ongoing periods last until now
+func periodOverlaps(tx *gorm.DB, taskID uint, exceptID uint, start time.Time, end time.Time) (bool, error) {
+	var count int64
+	err := tx.Model(&models.TaskPeriod{}).
+		Where("task_id = ? AND id <> ?", taskID, exceptID).
+		Where("start_time < ? AND COALESCE(end_time, CURRENT_TIMESTAMP) > ?", end, start).
+		Count(&count).Error
+	return count > 0, err
+}
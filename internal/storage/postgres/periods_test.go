@@ -0,0 +1,33 @@
+package postgres
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/moxicom/user_test/internal/storage"
+)
+
+func TestMissingPeriod(t *testing.T) {
+	store := newFakeStorage(t, &fakeDB{})
+	now := time.Now()
+
+	tests := []struct {
+		name string
+		call func() error
+	}{
+		{"GetPeriod", func() error {
+			_, err := store.GetPeriod(7)
+			return err
+		}},
+		{"UpdatePeriod", func() error { return store.UpdatePeriod(7, now.Add(-time.Hour), now) }},
+		{"SetPeriodBillable", func() error { return store.SetPeriodBillable(7, false) }},
+		{"DeletePeriod", func() error { return store.DeletePeriod(7) }},
+	}
+
+	for _, test := range tests {
+		if err := test.call(); !errors.Is(err, storage.ErrPeriodNotFound) {
+			t.Errorf("%s: err = %v; expected %v", test.name, err, storage.ErrPeriodNotFound)
+		}
+	}
+}
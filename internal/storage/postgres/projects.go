@@ -0,0 +1,162 @@
+package postgres
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/moxicom/user_test/internal/models"
+)
+
+func (p *PgStorage) CreateProject(project models.Project) (uint, error) {
+	log := p.log.With(slog.String("op", "PgStorage.CreateProject"))
+
+	result := p.db.Create(&project)
+	if result.Error != nil {
+		log.Error("failed to add project", slog.Any("err", result.Error))
+		return 0, result.Error
+	}
+	log.Debug("project added to storage", slog.Any("project", project))
+	return project.ID, nil
+}
+
+func (p *PgStorage) GetProjects(filters models.ProjectFilters) ([]models.Project, error) {
+	log := p.log.With(slog.String("op", "PgStorage.GetProjects"))
+	var projects []models.Project
+
+	query := p.db.Model(&models.Project{})
+
+	if filters.Name != "" {
+		query = query.Where("LOWER(name) LIKE LOWER(?)", "%"+filters.Name+"%")
+	}
+	if filters.Client != "" {
+		query = query.Where("LOWER(client) LIKE LOWER(?)", "%"+filters.Client+"%")
+	}
+
+	if err := query.Order("name").Find(&projects).Error; err != nil {
+		log.Error("failed to get projects", slog.Any("err", err))
+		return []models.Project{}, err
+	}
+
+	return projects, nil
+}
+
+func (p *PgStorage) GetProject(projectID uint) (models.Project, error) {
+	log := p.log.With(slog.String("op", "PgStorage.GetProject"))
+	var project models.Project
+
+	if err := p.db.First(&project, projectID).Error; err != nil {
+		log.Error("failed to get project", slog.Uint64("project_id", uint64(projectID)), slog.Any("err", err))
+		return models.Project{}, err
+	}
+
+	return project, nil
+}
+
+func (p *PgStorage) UpdateProject(projectID uint, filters models.ProjectFilters) error {
+	log := p.log.With(slog.String("op", "PgStorage.UpdateProject"))
+	var project models.Project
+
+	tx := p.db.Begin()
+	defer tx.Rollback()
+
+	if err := tx.First(&project, projectID).Error; err != nil {
+		return err
+	}
+
+	if filters.Name != "" {
+		project.Name = filters.Name
+	}
+	if filters.Client != "" {
+		project.Client = filters.Client
+	}
+	if filters.Description != "" {
+		project.Description = filters.Description
+	}
+	if filters.BudgetHours != nil {
+		project.BudgetHours = budget(*filters.BudgetHours)
+	}
+	if filters.BudgetAmount != nil {
+		project.BudgetAmount = budget(*filters.BudgetAmount)
+	}
+
+	if err := tx.Save(&project).Error; err != nil {
+		log.Error("failed to update project", slog.Any("err", err))
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+func (p *PgStorage) DeleteProject(projectID uint) error {
+	log := p.log.With(slog.String("op", "PgStorage.DeleteProject"))
+	tx := p.db.Begin()
+	defer tx.Rollback()
+
+	res := tx.Delete(&models.Project{}, projectID)
+	if res.Error != nil {
+		log.Error("failed to delete project. Rolled back", slog.Any("err", res.Error))
+		return res.Error
+	}
+
+	return tx.Commit().Error
+}
+
+// GetProjectDays returns time and cost of the project tracked before
+// bounds[0] and within each day from bounds[i] to bounds[i+1]. Days without
+// time are omitted. Time is split by UTC days within each day, so it is
+// valued at the rates effective on them as in reports. Running periods last
+// until now
+func (p *PgStorage) GetProjectDays(projectID uint, bounds []time.Time) (models.ProjectDayTotal, []models.ProjectDayTotal, error) {
+	log := p.log.With(slog.String("op", "PgStorage.GetProjectDays"))
+	var before models.ProjectDayTotal
+	var days []models.ProjectDayTotal
+
+	history := taskDurations(p.db, durationScope{ProjectID: projectID}, time.Unix(0, 0), bounds[0])
+	err := p.db.Table("(?) AS periods", history).
+		Select(`COALESCE(ROUND(SUM(periods.total_duration)), 0)::bigint AS seconds,
+            COALESCE(ROUND(SUM(periods.cost), 2), 0) AS cost`).
+		Scan(&before).Error
+	if err != nil {
+		log.Error("failed to get project time before range", slog.Uint64("project_id", uint64(projectID)), slog.Any("err", err))
+		return before, nil, err
+	}
+
+	pieces := p.db.Table("task_periods AS tp").
+		Select("tp.task_id, b.idx, d.day, s.seconds").
+		Joins("JOIN tasks ON tasks.id = tp.task_id").
+		Joins(`JOIN unnest(ARRAY[?]::timestamptz[], ARRAY[?]::timestamptz[]) WITH ORDINALITY AS b(day_start, day_end, idx)
+            ON tp.start_time < b.day_end AND COALESCE(tp.end_time, CURRENT_TIMESTAMP) > b.day_start`,
+			bounds[:len(bounds)-1], bounds[1:]).
+		Joins(`CROSS JOIN LATERAL generate_series(
+                date_trunc('day', GREATEST(tp.start_time, b.day_start) AT TIME ZONE 'UTC') AT TIME ZONE 'UTC',
+                LEAST(COALESCE(tp.end_time, CURRENT_TIMESTAMP), b.day_end), INTERVAL '24 hours'
+            ) AS d(day)`).
+		Joins(`CROSS JOIN LATERAL (SELECT EXTRACT(EPOCH FROM
+                LEAST(COALESCE(tp.end_time, CURRENT_TIMESTAMP), b.day_end, d.day + INTERVAL '24 hours') - GREATEST(tp.start_time, b.day_start, d.day)
+            ) AS seconds) AS s`).
+		Where("tasks.project_id = ?", projectID).
+		Where("d.day < LEAST(COALESCE(tp.end_time, CURRENT_TIMESTAMP), b.day_end)")
+
+	err = p.db.Table("(?) AS pieces", pieces).
+		Select(`pieces.idx AS day_index, ROUND(SUM(pieces.seconds))::bigint AS seconds,
+            ROUND(SUM(pieces.seconds::numeric * COALESCE(rate.hourly_rate, 0)) / 3600, 2) AS cost`).
+		Joins("JOIN tasks ON tasks.id = pieces.task_id").
+		Joins(dayRate).
+		Group("pieces.idx").
+		Order("pieces.idx").
+		Scan(&days).Error
+	if err != nil {
+		log.Error("failed to get project days", slog.Uint64("project_id", uint64(projectID)), slog.Any("err", err))
+		return before, nil, err
+	}
+
+	return before, days, nil
+}
+
+// budget returns nil for zero budget which removes it
+func budget[T float64 | models.Cents](value T) *T {
+	if value == 0 {
+		return nil
+	}
+	return &value
+}
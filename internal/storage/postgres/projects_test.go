@@ -0,0 +1,40 @@
+package postgres
+
+import (
+	"database/sql/driver"
+	"testing"
+	"time"
+)
+
+func TestGetProjectDays(t *testing.T) {
+	// time before the range is read first, then the days
+	answers := []fakeResult{
+		{columns: []string{"seconds", "cost"}, rows: [][]driver.Value{{int64(7200), []byte("100.50")}}},
+		{columns: []string{"day_index", "seconds", "cost"}, rows: [][]driver.Value{
+			{int64(1), int64(3600), []byte("50.10")},
+			{int64(2), int64(1800), []byte("25.05")},
+		}},
+	}
+	db := &fakeDB{respond: func(string, []driver.Value) fakeResult {
+		if len(answers) == 0 {
+			return fakeResult{}
+		}
+		answer := answers[0]
+		answers = answers[1:]
+		return answer
+	}}
+
+	from := time.Date(2024, 7, 1, 0, 0, 0, 0, time.FixedZone("MSK", 3*60*60))
+	bounds := []time.Time{from, from.AddDate(0, 0, 1), from.AddDate(0, 0, 2)}
+	before, days, err := newFakeStorage(t, db).GetProjectDays(4, bounds)
+	if err != nil {
+		t.Fatalf("GetProjectDays: %v", err)
+	}
+
+	if before.Seconds != 7200 || before.Cost != 10050 {
+		t.Errorf("before = %+v; expected 7200s and 100.50", before)
+	}
+	if len(days) != 2 || days[0].DayIndex != 1 || days[0].Cost != 5010 || days[1].Seconds != 1800 || days[1].Cost != 2505 {
+		t.Errorf("days = %+v", days)
+	}
+}
@@ -0,0 +1,59 @@
+package postgres
+
+import (
+	"log/slog"
+
+	"github.com/moxicom/user_test/internal/models"
+	"github.com/moxicom/user_test/internal/storage"
+)
+
+func (p *PgStorage) CreateRate(rate models.Rate) (uint, error) {
+	log := p.log.With(slog.String("op", "PgStorage.CreateRate"))
+
+	result := p.db.Create(&rate)
+	if result.Error != nil {
+		log.Error("failed to add rate", slog.Any("err", result.Error))
+		return 0, result.Error
+	}
+	log.Debug("rate added to storage", slog.Any("rate", rate))
+	return rate.ID, nil
+}
+
+// GetRates returns rates set for the user, project or task, latest first
+func (p *PgStorage) GetRates(filters models.RateFilters) ([]models.Rate, error) {
+	log := p.log.With(slog.String("op", "PgStorage.GetRates"))
+	var rates []models.Rate
+
+	query := p.db.Model(&models.Rate{})
+	if filters.UserID != 0 {
+		query = query.Where("user_id = ?", filters.UserID)
+	}
+	if filters.ProjectID != 0 {
+		query = query.Where("project_id = ?", filters.ProjectID)
+	}
+	if filters.TaskID != 0 {
+		query = query.Where("task_id = ?", filters.TaskID)
+	}
+
+	if err := query.Order("effective_from DESC, id DESC").Find(&rates).Error; err != nil {
+		log.Error("failed to get rates", slog.Any("err", err))
+		return []models.Rate{}, err
+	}
+
+	return rates, nil
+}
+
+func (p *PgStorage) DeleteRate(rateID uint) error {
+	log := p.log.With(slog.String("op", "PgStorage.DeleteRate"))
+
+	res := p.db.Delete(&models.Rate{}, rateID)
+	if res.Error != nil {
+		log.Error("failed to delete rate", slog.Uint64("rate_id", uint64(rateID)), slog.Any("err", res.Error))
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return storage.ErrRateNotFound
+	}
+
+	return nil
+}
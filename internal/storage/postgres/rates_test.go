@@ -0,0 +1,25 @@
+package postgres
+
+import (
+	"database/sql/driver"
+	"errors"
+	"testing"
+
+	"github.com/moxicom/user_test/internal/storage"
+)
+
+func TestDeleteRate(t *testing.T) {
+	for _, affected := range []int64{1, 0} {
+		db := &fakeDB{respond: func(string, []driver.Value) fakeResult {
+			return fakeResult{affected: affected}
+		}}
+
+		err := newFakeStorage(t, db).DeleteRate(4)
+		if affected > 0 && err != nil {
+			t.Errorf("existing rate: err = %v; expected nil", err)
+		}
+		if affected == 0 && !errors.Is(err, storage.ErrRateNotFound) {
+			t.Errorf("missing rate: err = %v; expected %v", err, storage.ErrRateNotFound)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package postgres
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/moxicom/user_test/internal/models"
+)
+
+// GetTaskTotals returns time worked on every task within [from, to] by all
+// users or members of the team if teamID is set. Periods are clipped to the range
+func (p *PgStorage) GetTaskTotals(from time.Time, to time.Time, teamID uint) ([]models.TaskTotal, error) {
+	log := p.log.With(slog.String("op", "PgStorage.GetTaskTotals"))
+	var totals []models.TaskTotal
+
+	durations := taskDurations(p.db, durationScope{}, from, to)
+
+	query := p.db.Model(&models.Task{}).
+		Select(`tasks.id AS task_id, tasks.task_name, tasks.user_id, users.surname, users.name,
+            tasks.project_id, COALESCE(projects.name, '') AS project_name, `+durationColumns).
+		Joins("JOIN (?) AS periods ON tasks.id = periods.task_id", durations).
+		Joins("JOIN users ON users.id = tasks.user_id").
+		Joins("LEFT JOIN projects ON projects.id = tasks.project_id")
+	if teamID != 0 {
+		query = query.Where("tasks.user_id IN (?)",
+			p.db.Model(&models.TeamMember{}).Select("user_id").Where("team_id = ?", teamID))
+	}
+
+	res := query.Find(&totals)
+	if res.Error != nil {
+		log.Error("failed to get task totals", slog.Any("err", res.Error))
+		return nil, res.Error
+	}
+
+	return totals, nil
+}
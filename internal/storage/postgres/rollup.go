@@ -0,0 +1,174 @@
+package postgres
+
+import (
+	"time"
+
+	"github.com/moxicom/user_test/internal/models"
+	"gorm.io/gorm"
+)
+
+const day = 24 * time.Hour
+
+// dailyTotalsInsert splits finished periods by UTC days. Conditions and
+// grouping are appended by callers
+const dailyTotalsInsert = `
+	INSERT INTO daily_totals (day, task_id, user_id, seconds, billable_seconds)
+	SELECT d.day, tp.task_id, tasks.user_id, SUM(s.seconds),
+		SUM(CASE WHEN tp.billable THEN s.seconds ELSE 0 END)
+	FROM task_periods tp
+	JOIN tasks ON tasks.id = tp.task_id
+	CROSS JOIN LATERAL generate_series(
+		date_trunc('day', tp.start_time AT TIME ZONE 'UTC') AT TIME ZONE 'UTC', tp.end_time, INTERVAL '24 hours'
+	) AS d(day)
+	CROSS JOIN LATERAL (
+		SELECT EXTRACT(EPOCH FROM LEAST(tp.end_time, d.day + INTERVAL '24 hours') - GREATEST(tp.start_time, d.day)) AS seconds
+	) AS s
+	WHERE tp.end_time IS NOT NULL AND d.day < tp.end_time`
+
+const dailyTotalsGroup = ` GROUP BY d.day, tp.task_id, tasks.user_id`
+
+// RebuildDailyTotals recomputes the whole daily rollup from task periods
+func (p *PgStorage) RebuildDailyTotals() error {
+	return p.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM daily_totals").Error; err != nil {
+			return err
+		}
+		return tx.Exec(dailyTotalsInsert + dailyTotalsGroup).Error
+	})
+}
+
+// refreshDailyTotals recomputes rollup rows of the task for UTC days
+// touched by [from, to). It should be called after periods of the task change
+func refreshDailyTotals(tx *gorm.DB, taskID uint, from time.Time, to time.Time) error {
+	dayFrom := from.UTC().Truncate(day)
+
+	err := tx.Where("task_id = ? AND day >= ? AND day < ?", taskID, dayFrom, to).
+		Delete(&models.DailyTotal{}).Error
+	if err != nil {
+		return err
+	}
+
+	return tx.Exec(dailyTotalsInsert+" AND tp.task_id = ? AND d.day >= ? AND d.day < ?"+dailyTotalsGroup,
+		taskID, dayFrom, to).Error
+}
+
+// dayRate selects hourly rate of the task effective on pieces.day. Task rate
+// overrides project rate which overrides user rate
+const dayRate = `LEFT JOIN LATERAL (
+		SELECT rates.hourly_rate FROM rates
+		WHERE rates.effective_from <= pieces.day AND (
+			rates.task_id = tasks.id OR
+			(rates.task_id IS NULL AND rates.project_id = tasks.project_id) OR
+			(rates.task_id IS NULL AND rates.project_id IS NULL AND rates.user_id = tasks.user_id))
+		ORDER BY rates.task_id IS NULL, rates.project_id IS NULL, rates.effective_from DESC
+		LIMIT 1
+	) AS rate ON true`
+
+// durationColumns selects totals of taskDurations subquery aliased as periods.
+// Money stays numeric and is rounded to cents
+const durationColumns = `ROUND(periods.total_duration)::bigint AS total_seconds,
+	ROUND(periods.billable_duration)::bigint AS billable_seconds,
+	ROUND(periods.cost, 2) AS cost,
+	ROUND(periods.billable_amount, 2) AS billable_amount`
+
+// trackedColumn selects all time tracked on tasks, running periods until now
+const trackedColumn = `COALESCE((
+		SELECT ROUND(SUM(EXTRACT(EPOCH FROM COALESCE(tp.end_time, CURRENT_TIMESTAMP) - tp.start_time)))
+		FROM task_periods tp WHERE tp.task_id = tasks.id
+	), 0)::bigint AS tracked_seconds`
+
+// durationScope limits taskDurations to tasks of a user and a project.
+// Zero fields do not limit
+type durationScope struct {
+	UserID    uint
+	ProjectID uint
+}
+
+// taskDurations returns subquery of task_id, total_duration and
+// billable_duration in seconds of periods within [from, to] clipped to it with
+// their cost and billable_amount. Time is split by UTC days, each valued at
+// the rate effective on its day. Whole closed UTC days of the window are read
+// from the daily rollup, edges of the window and running periods are computed
+// from periods
+func taskDurations(db *gorm.DB, scope durationScope, from time.Time, to time.Time) *gorm.DB {
+	pieces := periodPieces(db, scope, from, to)
+
+	if start, end, ok := rollupCovers(from, to, time.Now()); ok {
+		// rollup has finished periods only, so running ones are taken whole
+		periods := pieces.
+			Where("tp.end_time IS NULL OR tp.start_time < ? OR tp.end_time > ?", start, end).
+			Where("tp.end_time IS NULL OR d.day < ? OR d.day >= ?", start, end)
+		rollup := db.Model(&models.DailyTotal{}).
+			Select("daily_totals.task_id, daily_totals.day, daily_totals.seconds, daily_totals.billable_seconds").
+			Where("daily_totals.day >= ? AND daily_totals.day < ?", start, end)
+		if scope.UserID != 0 {
+			rollup = rollup.Where("daily_totals.user_id = ?", scope.UserID)
+		}
+		if scope.ProjectID != 0 {
+			rollup = rollup.
+				Joins("JOIN tasks ON tasks.id = daily_totals.task_id").
+				Where("tasks.project_id = ?", scope.ProjectID)
+		}
+		pieces = db.Raw("? UNION ALL ?", periods, rollup)
+	}
+
+	return db.Table("(?) AS pieces", pieces).
+		Select(`pieces.task_id, SUM(pieces.seconds) AS total_duration,
+            SUM(pieces.billable_seconds) AS billable_duration,
+            SUM(pieces.seconds::numeric * COALESCE(rate.hourly_rate, 0)) / 3600 AS cost,
+            SUM(pieces.billable_seconds::numeric * COALESCE(rate.hourly_rate, 0)) / 3600 AS billable_amount`).
+		Joins("JOIN tasks ON tasks.id = pieces.task_id").
+		Joins(dayRate).
+		Group("pieces.task_id")
+}
+
+// periodPieces selects task_id, day, seconds and billable_seconds of periods
+// of the scope overlapping [from, to], clipped to it and split by UTC days.
+// Running periods last until now
+func periodPieces(db *gorm.DB, scope durationScope, from time.Time, to time.Time) *gorm.DB {
+	query := db.Table("task_periods AS tp").
+		Select("tp.task_id, d.day, s.seconds, CASE WHEN tp.billable THEN s.seconds ELSE 0 END AS billable_seconds").
+		Joins(`CROSS JOIN LATERAL generate_series(
+                date_trunc('day', GREATEST(tp.start_time, ?) AT TIME ZONE 'UTC') AT TIME ZONE 'UTC',
+                LEAST(COALESCE(tp.end_time, CURRENT_TIMESTAMP), ?), INTERVAL '24 hours'
+            ) AS d(day)`, from, to).
+		Joins(`CROSS JOIN LATERAL (SELECT EXTRACT(EPOCH FROM
+                LEAST(COALESCE(tp.end_time, CURRENT_TIMESTAMP), ?, d.day + INTERVAL '24 hours') - GREATEST(tp.start_time, ?, d.day)
+            ) AS seconds) AS s`, to, from).
+		Where("tp.start_time < ? AND COALESCE(tp.end_time, CURRENT_TIMESTAMP) > ?", to, from).
+		Where("d.day < LEAST(COALESCE(tp.end_time, CURRENT_TIMESTAMP), ?)", to)
+
+	if scope.UserID != 0 || scope.ProjectID != 0 {
+		query = query.Joins("JOIN tasks ON tasks.id = tp.task_id")
+	}
+	if scope.UserID != 0 {
+		query = query.Where("tasks.user_id = ?", scope.UserID)
+	}
+	if scope.ProjectID != 0 {
+		query = query.Where("tasks.project_id = ?", scope.ProjectID)
+	}
+	return query
+}
+
+// rollupCovers returns whole UTC days [start, end) of the window [from, to)
+// which ended before today, so the rollup has their finished periods.
+// It reports false if there is no such day
+func rollupCovers(from time.Time, to time.Time, now time.Time) (time.Time, time.Time, bool) {
+	start := from.UTC().Truncate(day)
+	if start.Before(from) {
+		start = start.Add(day)
+	}
+	end := minTime(to, now).UTC().Truncate(day)
+
+	if !start.Before(end) {
+		return time.Time{}, time.Time{}, false
+	}
+	return start, end, true
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
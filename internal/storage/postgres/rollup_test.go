@@ -0,0 +1,36 @@
+package postgres
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRollupCovers(t *testing.T) {
+	msk := time.FixedZone("MSK", 3*60*60)
+	now := time.Date(2024, 5, 20, 15, 0, 0, 0, time.UTC)
+	utcDay := func(d int) time.Time { return time.Date(2024, 5, d, 0, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name     string
+		from, to time.Time
+		start    time.Time
+		end      time.Time
+		ok       bool
+	}{
+		{"utc midnights", utcDay(1), utcDay(8), utcDay(1), utcDay(8), true},
+		{"local midnights", time.Date(2024, 5, 1, 0, 0, 0, 0, msk), time.Date(2024, 5, 8, 0, 0, 0, 0, msk), utcDay(1), utcDay(7), true},
+		{"inside of days", utcDay(1).Add(time.Hour), utcDay(3).Add(time.Hour), utcDay(2), utcDay(3), true},
+		{"within one day", utcDay(1).Add(time.Hour), utcDay(1).Add(20 * time.Hour), time.Time{}, time.Time{}, false},
+		{"one day crossing midnight", utcDay(1).Add(time.Hour), utcDay(2).Add(time.Hour), time.Time{}, time.Time{}, false},
+		{"until future", utcDay(10), utcDay(31), utcDay(10), utcDay(20), true},
+		{"today only", utcDay(20), utcDay(21), time.Time{}, time.Time{}, false},
+	}
+
+	for _, test := range tests {
+		start, end, ok := rollupCovers(test.from, test.to, now)
+		if !start.Equal(test.start) || !end.Equal(test.end) || ok != test.ok {
+			t.Errorf("%s: rollupCovers = %v, %v, %v; expected %v, %v, %v",
+				test.name, start, end, ok, test.start, test.end, test.ok)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package postgres
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/moxicom/user_test/internal/models"
+	"github.com/moxicom/user_test/internal/storage"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SubmitTimesheet records the week of the user as submitted with its total
+// time. Week with a running timer or overlapping a pending or approved
+// submission can not be submitted
+func (p *PgStorage) SubmitTimesheet(sub models.TimesheetSubmission) (uint, error) {
+	log := p.log.With(slog.String("op", "PgStorage.SubmitTimesheet"))
+
+	tx := p.db.Begin()
+	defer tx.Rollback()
+
+	if _, err := lockUser(tx, sub.UserID); err != nil {
+		log.Error("failed to lock user", slog.Uint64("user_id", uint64(sub.UserID)), slog.Any("err", err))
+		return 0, err
+	}
+
+	running, err := userOpenPeriods(tx, sub.UserID)
+	if err != nil {
+		log.Error("failed to get running periods", slog.Uint64("user_id", uint64(sub.UserID)), slog.Any("err", err))
+		return 0, err
+	}
+	for _, period := range running {
+		if period.StartTime.Before(sub.WeekEnd) {
+			log.Warn("timesheet can not be submitted. Timer is running", slog.Uint64("user_id", uint64(sub.UserID)))
+			return 0, storage.ErrWeekTimerRunning
+		}
+	}
+
+	var submitted int64
+	err = tx.Model(&models.TimesheetSubmission{}).
+		Where("user_id = ? AND week_start < ? AND week_end > ? AND status IN ?", sub.UserID, sub.WeekEnd, sub.WeekStart,
+			[]models.SubmissionStatus{models.SubmissionSubmitted, models.SubmissionApproved}).
+		Count(&submitted).Error
+	if err != nil {
+		log.Error("failed to check submissions", slog.Any("err", err))
+		return 0, err
+	}
+	if submitted > 0 {
+		log.Warn("timesheet is already submitted", slog.Uint64("user_id", uint64(sub.UserID)))
+		return 0, storage.ErrAlreadySubmitted
+	}
+
+	if sub.TotalSeconds, err = userSeconds(tx, sub.UserID, sub.WeekStart, sub.WeekEnd); err != nil {
+		log.Error("failed to get week total", slog.Uint64("user_id", uint64(sub.UserID)), slog.Any("err", err))
+		return 0, err
+	}
+
+	if err := tx.Create(&sub).Error; err != nil {
+		log.Error("failed to add submission", slog.Any("err", err))
+		return 0, err
+	}
+
+	return sub.ID, tx.Commit().Error
+}
+
+func (p *PgStorage) GetSubmission(id uint) (models.TimesheetSubmission, error) {
+	log := p.log.With(slog.String("op", "PgStorage.GetSubmission"))
+	var sub models.TimesheetSubmission
+
+	if err := p.db.First(&sub, id).Error; err != nil {
+		log.Error("failed to get submission", slog.Uint64("submission_id", uint64(id)), slog.Any("err", err))
+		return models.TimesheetSubmission{}, err
+	}
+
+	return sub, nil
+}
+
+func (p *PgStorage) GetSubmissions(filters models.SubmissionFilters) ([]models.TimesheetSubmission, error) {
+	log := p.log.With(slog.String("op", "PgStorage.GetSubmissions"))
+	var subs []models.TimesheetSubmission
+
+	query := p.db.Model(&models.TimesheetSubmission{})
+	if filters.UserID != 0 {
+		query = query.Where("user_id = ?", filters.UserID)
+	}
+	if filters.Status != "" {
+		query = query.Where("status = ?", filters.Status)
+	}
+	if filters.ManagerID != 0 {
+		managed := p.db.Model(&models.TeamMember{}).
+			Select("team_id").
+			Where("user_id = ? AND role = ?", filters.ManagerID, models.TeamManagerRole)
+		members := p.db.Model(&models.TeamMember{}).
+			Select("user_id").
+			Where("team_id IN (?) AND user_id <> ?", managed, filters.ManagerID)
+		query = query.Where("user_id IN (?)", members)
+	}
+
+	if err := query.Order("week_start DESC, id DESC").Find(&subs).Error; err != nil {
+		log.Error("failed to get submissions", slog.Any("err", err))
+		return []models.TimesheetSubmission{}, err
+	}
+
+	return subs, nil
+}
+
+// ReviewSubmission approves or rejects a submitted timesheet. Total time is
+// recounted on approval so it matches the locked periods
+func (p *PgStorage) ReviewSubmission(id uint, status models.SubmissionStatus, reviewerID uint, comment string, reviewedAt time.Time) error {
+	log := p.log.With(slog.String("op", "PgStorage.ReviewSubmission"))
+	var sub models.TimesheetSubmission
+
+	tx := p.db.Begin()
+	defer tx.Rollback()
+
+	if err := tx.First(&sub, id).Error; err != nil {
+		return err
+	}
+	// the user is locked first like on submission and deletion of the user
+	if _, err := lockUser(tx, sub.UserID); err != nil {
+		log.Error("failed to lock user", slog.Uint64("user_id", uint64(sub.UserID)), slog.Any("err", err))
+		return err
+	}
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&sub, id).Error; err != nil {
+		return err
+	}
+	if sub.Status != models.SubmissionSubmitted {
+		log.Warn("submission is already reviewed", slog.Uint64("submission_id", uint64(id)))
+		return storage.ErrNotSubmitted
+	}
+
+	if status == models.SubmissionApproved {
+		total, err := userSeconds(tx, sub.UserID, sub.WeekStart, sub.WeekEnd)
+		if err != nil {
+			log.Error("failed to get week total", slog.Uint64("user_id", uint64(sub.UserID)), slog.Any("err", err))
+			return err
+		}
+		sub.TotalSeconds = total
+	}
+
+	sub.Status = status
+	sub.ReviewerID = &reviewerID
+	sub.ReviewedAt = &reviewedAt
+	sub.Comment = comment
+	if err := tx.Save(&sub).Error; err != nil {
+		log.Error("failed to review submission", slog.Uint64("submission_id", uint64(id)), slog.Any("err", err))
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// userSeconds is time of finished periods of the user clipped to [from, to)
+func userSeconds(tx *gorm.DB, userID uint, from time.Time, to time.Time) (int64, error) {
+	var total int64
+	err := tx.Model(&models.TaskPeriod{}).
+		Select(`COALESCE(ROUND(SUM(EXTRACT(EPOCH FROM
+            LEAST(task_periods.end_time, ?) - GREATEST(task_periods.start_time, ?)
+        ))), 0)::bigint`, to, from).
+		Joins("JOIN tasks ON tasks.id = task_periods.task_id").
+		Where("tasks.user_id = ? AND task_periods.end_time IS NOT NULL", userID).
+		Where("task_periods.start_time < ? AND task_periods.end_time > ?", to, from).
+		Scan(&total).Error
+	return total, err
+}
+
+// checkUnlocked returns storage.ErrPeriodLocked if [start, end) intersects a
+// week of an approved timesheet of the user
+func checkUnlocked(tx *gorm.DB, userID uint, start time.Time, end time.Time) error {
+	var count int64
+	err := tx.Model(&models.TimesheetSubmission{}).
+		Where("user_id = ? AND status = ?", userID, models.SubmissionApproved).
+		Where("week_start < ? AND week_end > ?", end, start).
+		Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return storage.ErrPeriodLocked
+	}
+	return nil
+}
+
+// taskLocked reports whether any period of the task is within a week of an
+// approved timesheet of its owner
+func taskLocked(tx *gorm.DB, taskID uint) (bool, error) {
+	var count int64
+	err := tx.Model(&models.TaskPeriod{}).
+		Joins("JOIN tasks ON tasks.id = task_periods.task_id").
+		Joins("JOIN timesheet_submissions ON timesheet_submissions.user_id = tasks.user_id").
+		Where("task_periods.task_id = ? AND timesheet_submissions.status = ?", taskID, models.SubmissionApproved).
+		Where("timesheet_submissions.week_start < COALESCE(task_periods.end_time, CURRENT_TIMESTAMP)").
+		Where("timesheet_submissions.week_end > task_periods.start_time").
+		Count(&count).Error
+	return count > 0, err
+}
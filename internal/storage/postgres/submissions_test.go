@@ -0,0 +1,145 @@
+package postgres
+
+import (
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/moxicom/user_test/internal/models"
+	"github.com/moxicom/user_test/internal/storage"
+)
+
+var (
+	lockedStart = time.Date(2024, 5, 7, 9, 0, 0, 0, time.UTC)
+	lockedEnd   = lockedStart.Add(time.Hour)
+)
+
+// lockingDB answers as a database where task 1 of user 5 has period 3 of
+// task periodTask from lockedStart to periodEnd (nil if running) and the
+// user has approved weeks submissions. Other counts and sums are zero
+func lockingDB(approved int64, periodTask int64, periodEnd driver.Value) *fakeDB {
+	row := func(columns []string, values ...driver.Value) fakeResult {
+		return fakeResult{columns: columns, rows: [][]driver.Value{values}}
+	}
+
+	return &fakeDB{respond: func(query string, _ []driver.Value) fakeResult {
+		switch {
+		case strings.Contains(query, "count(*)"):
+			if strings.Contains(query, "timesheet_submissions") {
+				return row([]string{"count"}, approved)
+			}
+			return row([]string{"count"}, int64(0))
+		case strings.HasPrefix(query, "SELECT") && strings.Contains(query, `FROM "tasks"`):
+			return row([]string{"id", "user_id", "status"}, int64(1), int64(5), string(models.TaskInProgress))
+		case strings.HasPrefix(query, "SELECT") && strings.Contains(query, `FROM "users"`):
+			return row([]string{"id"}, int64(5))
+		case strings.HasPrefix(query, "SELECT") && strings.Contains(query, `FROM "task_periods"`) && !strings.Contains(query, "SUM("):
+			if periodEnd != nil && strings.Contains(query, "end_time IS NULL") {
+				return fakeResult{}
+			}
+			return row([]string{"id", "task_id", "start_time", "end_time"}, int64(3), periodTask, lockedStart, periodEnd)
+		}
+		return fakeResult{affected: 1}
+	}}
+}
+
+func TestCheckUnlocked(t *testing.T) {
+	for _, approved := range []int64{0, 1} {
+		store := newFakeStorage(t, lockingDB(approved, 1, nil))
+
+		err := checkUnlocked(store.db, 5, lockedStart, lockedEnd)
+		if locked := errors.Is(err, storage.ErrPeriodLocked); locked != (approved > 0) || (err != nil && !locked) {
+			t.Errorf("checkUnlocked with %d approved weeks = %v", approved, err)
+		}
+	}
+}
+
+func TestTaskLocked(t *testing.T) {
+	for _, approved := range []int64{0, 1} {
+		store := newFakeStorage(t, lockingDB(approved, 1, nil))
+
+		locked, err := taskLocked(store.db, 1)
+		if err != nil || locked != (approved > 0) {
+			t.Errorf("taskLocked with %d approved weeks = %v, %v", approved, locked, err)
+		}
+	}
+}
+
+func TestLockedPeriodsAreNotChanged(t *testing.T) {
+	tests := []struct {
+		name      string
+		periodEnd driver.Value
+		change    func(store *PgStorage) error
+	}{
+		{"end period", nil, func(store *PgStorage) error {
+			return store.EndPeriod(1, lockedEnd)
+		}},
+		{"switch period", nil, func(store *PgStorage) error {
+			return store.SwitchPeriod(2, lockedEnd)
+		}},
+		{"finish task", nil, func(store *PgStorage) error {
+			return store.FinishTask(1, lockedEnd)
+		}},
+		{"delete task", lockedEnd, func(store *PgStorage) error {
+			return store.DeleteTask(1)
+		}},
+		{"add period", nil, func(store *PgStorage) error {
+			_, err := store.AddPeriod(models.TaskPeriod{TaskID: 1, StartTime: &lockedStart, EndTime: &lockedEnd})
+			return err
+		}},
+		{"update period", lockedEnd, func(store *PgStorage) error {
+			return store.UpdatePeriod(3, lockedStart.Add(time.Minute), lockedEnd)
+		}},
+		{"delete period", lockedEnd, func(store *PgStorage) error {
+			return store.DeletePeriod(3)
+		}},
+		{"set billable", lockedEnd, func(store *PgStorage) error {
+			return store.SetPeriodBillable(3, false)
+		}},
+		{"auto-close period", nil, func(store *PgStorage) error {
+			return store.AutoClosePeriod(3, lockedEnd)
+		}},
+	}
+
+	for _, test := range tests {
+		for _, approved := range []int64{1, 0} {
+			err := test.change(newFakeStorage(t, lockingDB(approved, 1, test.periodEnd)))
+
+			if approved > 0 && !errors.Is(err, storage.ErrPeriodLocked) {
+				t.Errorf("%s: err = %v; expected %v", test.name, err, storage.ErrPeriodLocked)
+			}
+			if approved == 0 && err != nil {
+				t.Errorf("%s: without approved weeks err = %v; expected nil", test.name, err)
+			}
+		}
+	}
+}
+
+func TestSubmitTimesheetRejectsSubmittedWeeks(t *testing.T) {
+	weekStart := time.Date(2024, 5, 6, 0, 0, 0, 0, time.UTC)
+	sub := models.TimesheetSubmission{UserID: 5, WeekStart: weekStart, WeekEnd: weekStart.AddDate(0, 0, 7)}
+
+	for _, submitted := range []int64{1, 0} {
+		_, err := newFakeStorage(t, lockingDB(submitted, 1, lockedEnd)).SubmitTimesheet(sub)
+		if submitted > 0 && !errors.Is(err, storage.ErrAlreadySubmitted) {
+			t.Errorf("with a submitted week err = %v; expected %v", err, storage.ErrAlreadySubmitted)
+		}
+		if submitted == 0 && err != nil {
+			t.Errorf("without submitted weeks err = %v; expected nil", err)
+		}
+	}
+}
+
+func TestDeleteUserWithApprovedTimesheets(t *testing.T) {
+	for _, approved := range []int64{1, 0} {
+		err := newFakeStorage(t, lockingDB(approved, 1, lockedEnd)).DeleteUser(5)
+		if approved > 0 && !errors.Is(err, storage.ErrUserHasApproved) {
+			t.Errorf("with approved weeks err = %v; expected %v", err, storage.ErrUserHasApproved)
+		}
+		if approved == 0 && err != nil {
+			t.Errorf("without approved weeks err = %v; expected nil", err)
+		}
+	}
+}
@@ -1,54 +1,293 @@
 package postgres
 
 import (
+	"errors"
 	"log/slog"
+	"strings"
 	"time"
 
 	"github.com/moxicom/user_test/internal/models"
 	"github.com/moxicom/user_test/internal/storage"
+	"gorm.io/gorm"
 )
 
 func (p *PgStorage) CreateTask(task models.Task) (uint, error) {
 	log := p.log.With(slog.String("op", "PgStorage.CreateTask"))
 
-	result := p.db.Create(&task)
+	tx := p.db.Begin()
+	defer tx.Rollback()
+
+	names := make([]string, 0, len(task.Tags))
+	for _, tag := range task.Tags {
+		names = append(names, tag.Name)
+	}
+	tags, err := resolveTags(tx, names)
+	if err != nil {
+		log.Error("failed to resolve task tags", slog.Any("err", err))
+		return 0, err
+	}
+	task.Tags = tags
+
+	result := tx.Create(&task)
 	if result.Error != nil {
 		log.Error("failed to add task", slog.Any("err", result.Error))
 		return 0, result.Error
 	}
 	log.Debug("user added to storage", slog.Any("task", task))
-	return task.ID, nil
+	return task.ID, tx.Commit().Error
 }
 
-func (p *PgStorage) FinishTask(taskID uint, finishTime time.Time) error {
-	log := p.log.With(slog.String("op", "PgStorage.EndTask"))
-	// TODO - fix
-	// TODO - end period
+func (p *PgStorage) UpdateTask(taskID uint, update models.TaskUpdate) error {
+	log := p.log.With(slog.String("op", "PgStorage.UpdateTask"))
+	var task models.Task
 
-	err := p.EndPeriod(taskID, finishTime)
+	tx := p.db.Begin()
+	defer tx.Rollback()
 
-	if err != storage.ErrPeriodNotStarted {
+	if err := tx.First(&task, taskID).Error; err != nil {
+		return err
+	}
+
+	if update.TaskName != nil {
+		task.TaskName = *update.TaskName
+	}
+	if update.Description != nil {
+		task.Description = *update.Description
+	}
+	if estimateChanged(task.Estimate, update.Estimate) {
+		task.Estimate = update.Estimate
+		if *update.Estimate == 0 {
+			task.Estimate = nil
+		}
+		// thresholds are alerted again against the new estimate
+		if err := tx.Where("task_id = ?", taskID).Delete(&models.TaskAlert{}).Error; err != nil {
+			log.Error("failed to reset task alerts", slog.Uint64("task_id", uint64(taskID)), slog.Any("err", err))
+			return err
+		}
+	}
+
+	if err := tx.Save(&task).Error; err != nil {
+		log.Error("failed to update task", slog.Any("err", err))
 		return err
 	}
 
+	if update.Tags != nil {
+		tags, err := resolveTags(tx, *update.Tags)
+		if err != nil {
+			log.Error("failed to resolve task tags", slog.Any("err", err))
+			return err
+		}
+		if err := tx.Model(&task).Association("Tags").Replace(tags); err != nil {
+			log.Error("failed to replace task tags", slog.Any("err", err))
+			return err
+		}
+	}
+
+	return tx.Commit().Error
+}
+
+func (p *PgStorage) GetTasks(filters models.TaskFilters) ([]models.Task, error) {
+	log := p.log.With(slog.String("op", "PgStorage.GetTasks"))
+	var tasks []models.Task
+
+	query := p.db.Model(&models.Task{}).Preload("Tags")
+
+	if filters.UserID != 0 {
+		query = query.Where("tasks.user_id = ?", filters.UserID)
+	}
+	if filters.ProjectID != 0 {
+		query = query.Where("tasks.project_id = ?", filters.ProjectID)
+	}
+	if filters.Tag != "" {
+		query = query.Where("tasks.id IN (?)", taggedTasks(p.db, filters.Tag))
+	}
+
+	order := "tasks.created_at DESC"
+	if filters.Asc {
+		order = "tasks.created_at ASC"
+	}
+
+	if err := query.Order(order).Find(&tasks).Error; err != nil {
+		log.Error("failed to get tasks", slog.Any("err", err))
+		return []models.Task{}, err
+	}
+
+	return tasks, nil
+}
+
+func (p *PgStorage) GetTags() ([]models.Tag, error) {
+	log := p.log.With(slog.String("op", "PgStorage.GetTags"))
+	var tags []models.Tag
+
+	if err := p.db.Order("name").Find(&tags).Error; err != nil {
+		log.Error("failed to get tags", slog.Any("err", err))
+		return []models.Tag{}, err
+	}
+
+	return tags, nil
+}
+
+// estimateChanged reports whether update replaces the current estimate, so
+// its alerts are reset. Nil update keeps it, zero removes it
+func estimateChanged(current *int64, update *int64) bool {
+	switch {
+	case update == nil:
+		return false
+	case current == nil:
+		return *update != 0
+	}
+	return *current != *update
+}
+
+func setTaskStatus(db *gorm.DB, taskID uint, status models.TaskStatus) error {
+	return db.Model(&models.Task{}).Where("id = ?", taskID).Update("status", status).Error
+}
+
+// resolveTags finds tags by names creating missing ones
+func resolveTags(tx *gorm.DB, names []string) ([]models.Tag, error) {
+	names = tagNames(names)
+	tags := make([]models.Tag, 0, len(names))
+	for _, name := range names {
+		tag := models.Tag{Name: name}
+		if err := tx.Where(models.Tag{Name: name}).FirstOrCreate(&tag).Error; err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+// tagNames trims names dropping empty and repeated ones, so a task never
+// gets the same tag twice
+func tagNames(names []string) []string {
+	result := make([]string, 0, len(names))
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		result = append(result, name)
+	}
+	return result
+}
+
+// taggedTasks is a subquery selecting IDs of tasks having the tag
+func taggedTasks(db *gorm.DB, tag string) *gorm.DB {
+	return db.Table("task_tags").
+		Select("task_tags.task_id").
+		Joins("JOIN tags ON tags.id = task_tags.tag_id").
+		Where("tags.name = ?", tag)
+}
+
+// loadTags fills tags of the tasks
+func loadTags(db *gorm.DB, tasks []models.TaskWithTotalTime) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, 0, len(tasks))
+	for _, task := range tasks {
+		ids = append(ids, task.ID)
+	}
+
+	var rows []struct {
+		TaskID uint
+		models.Tag
+	}
+	err := db.Table("task_tags").
+		Select("task_tags.task_id, tags.id, tags.name").
+		Joins("JOIN tags ON tags.id = task_tags.tag_id").
+		Where("task_tags.task_id IN ?", ids).
+		Order("tags.name").
+		Scan(&rows).Error
+	if err != nil {
+		return err
+	}
+
+	byTask := make(map[uint][]models.Tag)
+	for _, row := range rows {
+		byTask[row.TaskID] = append(byTask[row.TaskID], row.Tag)
+	}
+	for i := range tasks {
+		tasks[i].Tags = byTask[tasks[i].ID]
+	}
+	return nil
+}
+
+func (p *PgStorage) GetTask(taskID uint) (models.Task, error) {
+	log := p.log.With(slog.String("op", "PgStorage.GetTask"))
 	var task models.Task
 
+	if err := p.db.First(&task, taskID).Error; err != nil {
+		log.Error("failed to get task", slog.Uint64("task_id", uint64(taskID)), slog.Any("err", err))
+		return models.Task{}, err
+	}
+
+	return task, nil
+}
+
+// FinishTask ends ongoing period of the task if any and marks it done in one transaction
+func (p *PgStorage) FinishTask(taskID uint, finishTime time.Time) error {
+	log := p.log.With(slog.String("op", "PgStorage.FinishTask"))
+
+	var ongoingPeriod models.TaskPeriod
 	tx := p.db.Begin()
 	defer tx.Rollback()
 
-	if err := tx.First(&task, taskID).Error; err != nil {
-		log.Error("Error selecting task on ending ", slog.Any("err", err))
+	tx.Where("task_id = ? AND end_time IS NULL", taskID).Last(&ongoingPeriod)
+	if ongoingPeriod.ID != 0 {
+		task, err := lockTask(tx, taskID)
+		if err != nil {
+			log.Error("failed to lock task", slog.Uint64("task_id", uint64(taskID)), slog.Any("err", err))
+			return err
+		}
+		if err := checkUnlocked(tx, task.UserID, *ongoingPeriod.StartTime, finishTime); err != nil {
+			log.Warn("period can not be ended", slog.Uint64("task_id", uint64(taskID)), slog.Any("err", err))
+			return err
+		}
+
+		ongoingPeriod.EndTime = &finishTime
+		if err := tx.Save(&ongoingPeriod).Error; err != nil {
+			log.Error("failed to end period", slog.Uint64("task_id", uint64(taskID)), slog.Any("err", err))
+			return err
+		}
+		if err := refreshDailyTotals(tx, taskID, *ongoingPeriod.StartTime, finishTime); err != nil {
+			log.Error("failed to refresh daily totals", slog.Uint64("task_id", uint64(taskID)), slog.Any("err", err))
+			return err
+		}
+	}
+
+	if err := setTaskStatus(tx, taskID, models.TaskDone); err != nil {
+		log.Error("failed to finish task", slog.Uint64("task_id", uint64(taskID)), slog.Any("err", err))
 		return err
 	}
 
-	task.IsFinished = true
+	return tx.Commit().Error
+}
+
+func (p *PgStorage) SetTaskStatus(taskID uint, status models.TaskStatus) error {
+	log := p.log.With(slog.String("op", "PgStorage.SetTaskStatus"))
 
-	if err := tx.Save(&task).Error; err != nil {
-		log.Error("failed to finish task", slog.Any("err", err))
+	if err := setTaskStatus(p.db, taskID, status); err != nil {
+		log.Error("failed to set task status", slog.Uint64("task_id", uint64(taskID)), slog.Any("err", err))
 		return err
 	}
 
-	return tx.Commit().Error
+	return nil
+}
+
+func (p *PgStorage) GetTaskPeriods(taskID uint) ([]models.TaskPeriod, error) {
+	log := p.log.With(slog.String("op", "PgStorage.GetTaskPeriods"))
+	var periods []models.TaskPeriod
+
+	if err := p.db.Where("task_id = ?", taskID).Order("start_time").Find(&periods).Error; err != nil {
+		log.Error("failed to get task periods", slog.Uint64("task_id", uint64(taskID)), slog.Any("err", err))
+		return []models.TaskPeriod{}, err
+	}
+
+	return periods, nil
 }
 
 func (p *PgStorage) DeleteTask(taskID uint) error {
@@ -56,6 +295,31 @@ func (p *PgStorage) DeleteTask(taskID uint) error {
 	tx := p.db.Begin()
 	defer tx.Rollback()
 
+	if _, err := lockTask(tx, taskID); err != nil {
+		log.Error("failed to lock task", slog.Uint64("task_id", uint64(taskID)), slog.Any("err", err))
+		return err
+	}
+
+	locked, err := taskLocked(tx, taskID)
+	if err != nil {
+		log.Error("failed to check approved timesheets", slog.Uint64("task_id", uint64(taskID)), slog.Any("err", err))
+		return err
+	}
+	if locked {
+		log.Warn("task can not be deleted. It has approved periods", slog.Uint64("task_id", uint64(taskID)))
+		return storage.ErrPeriodLocked
+	}
+
+	invoiced, err := taskInvoiced(tx, taskID)
+	if err != nil {
+		log.Error("failed to check invoiced periods", slog.Uint64("task_id", uint64(taskID)), slog.Any("err", err))
+		return err
+	}
+	if invoiced {
+		log.Warn("task can not be deleted. It has invoiced periods", slog.Uint64("task_id", uint64(taskID)))
+		return storage.ErrPeriodInvoiced
+	}
+
 	res := tx.Delete(&models.Task{}, taskID)
 	if res.Error != nil {
 		log.Error("failed to delete task. Rolled back", slog.Any("err", res.Error))
@@ -72,24 +336,134 @@ func (p *PgStorage) StartPeriod(taskID uint, startTime time.Time) error {
 	tx := p.db.Begin()
 	defer tx.Rollback()
 
-	tx.Where("task_id = ? AND end_time IS NULL", taskID).Last(&ongoingPeriod)
-	if ongoingPeriod.ID != 0 {
+	task, err := lockTask(tx, taskID)
+	if err != nil {
+		log.Error("failed to lock task", slog.Uint64("task_id", uint64(taskID)), slog.Any("err", err))
+		return err
+	}
+
+	// checked under the task lock so concurrent starts do not both pass
+	err = tx.Where("task_id = ? AND end_time IS NULL", taskID).Last(&ongoingPeriod).Error
+	if err == nil {
 		log.Warn("task can not be started. Should be finished", slog.Any("err", storage.ErrPeriodNotFinished))
 		return storage.ErrPeriodNotFinished
 	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Error("failed to get running period", slog.Uint64("task_id", uint64(taskID)), slog.Any("err", err))
+		return err
+	}
 
-	now := time.Now()
-	period := models.TaskPeriod{TaskID: taskID, StartTime: &now}
+	user, err := lockUser(tx, task.UserID)
+	if err != nil {
+		log.Error("failed to lock user", slog.Uint64("user_id", uint64(task.UserID)), slog.Any("err", err))
+		return err
+	}
+
+	if user.SingleTimer {
+		running, err := userOpenPeriods(tx, user.ID)
+		if err != nil {
+			log.Error("failed to get running periods", slog.Uint64("user_id", uint64(user.ID)), slog.Any("err", err))
+			return err
+		}
+		if len(running) > 0 {
+			log.Warn("task can not be started. Another timer is running", slog.Uint64("user_id", uint64(user.ID)))
+			return storage.ErrTimerRunning
+		}
+	}
+
+	period := models.TaskPeriod{TaskID: taskID, StartTime: &startTime}
 	res := tx.Create(&period)
 	if res.Error != nil {
 		log.Error("failed to start period", slog.Uint64("task_id", uint64(taskID)), slog.Any("err", res.Error))
 		return res.Error
 	}
 
+	if err := setTaskStatus(tx, taskID, models.TaskInProgress); err != nil {
+		log.Error("failed to set task status", slog.Uint64("task_id", uint64(taskID)), slog.Any("err", err))
+		return err
+	}
+
 	return tx.Commit().Error
 }
 
-func (p *PgStorage) EndPeriod(taskID uint, startTime time.Time) error {
+// SwitchPeriod ends all running periods of the task owner and starts a period
+// of the task in one transaction
+func (p *PgStorage) SwitchPeriod(taskID uint, switchTime time.Time) error {
+	log := p.log.With(slog.String("op", "PgStorage.SwitchPeriod"))
+
+	tx := p.db.Begin()
+	defer tx.Rollback()
+
+	task, err := lockTask(tx, taskID)
+	if err != nil {
+		log.Error("failed to lock task", slog.Uint64("task_id", uint64(taskID)), slog.Any("err", err))
+		return err
+	}
+
+	if _, err := lockUser(tx, task.UserID); err != nil {
+		log.Error("failed to lock user", slog.Uint64("user_id", uint64(task.UserID)), slog.Any("err", err))
+		return err
+	}
+
+	running, err := userOpenPeriods(tx, task.UserID)
+	if err != nil {
+		log.Error("failed to get running periods", slog.Uint64("user_id", uint64(task.UserID)), slog.Any("err", err))
+		return err
+	}
+
+	toEnd, start := switchPeriods(running, taskID)
+	for _, period := range toEnd {
+		if err := checkUnlocked(tx, task.UserID, *period.StartTime, switchTime); err != nil {
+			log.Warn("period can not be ended", slog.Uint64("task_id", uint64(period.TaskID)), slog.Any("err", err))
+			return err
+		}
+		period.EndTime = &switchTime
+		if err := tx.Save(&period).Error; err != nil {
+			log.Error("failed to end period", slog.Uint64("task_id", uint64(period.TaskID)), slog.Any("err", err))
+			return err
+		}
+		if err := refreshDailyTotals(tx, period.TaskID, *period.StartTime, switchTime); err != nil {
+			log.Error("failed to refresh daily totals", slog.Uint64("task_id", uint64(period.TaskID)), slog.Any("err", err))
+			return err
+		}
+		if err := setTaskStatus(tx, period.TaskID, models.TaskPaused); err != nil {
+			log.Error("failed to set task status", slog.Uint64("task_id", uint64(period.TaskID)), slog.Any("err", err))
+			return err
+		}
+	}
+
+	if start {
+		period := models.TaskPeriod{TaskID: taskID, StartTime: &switchTime}
+		if err := tx.Create(&period).Error; err != nil {
+			log.Error("failed to start period", slog.Uint64("task_id", uint64(taskID)), slog.Any("err", err))
+			return err
+		}
+		if err := setTaskStatus(tx, taskID, models.TaskInProgress); err != nil {
+			log.Error("failed to set task status", slog.Uint64("task_id", uint64(taskID)), slog.Any("err", err))
+			return err
+		}
+	}
+
+	return tx.Commit().Error
+}
+
+// switchPeriods returns running periods to end when switching to the task and
+// whether a period of the task should be started. Running period of the task
+// itself is kept
+func switchPeriods(running []models.TaskPeriod, taskID uint) ([]models.TaskPeriod, bool) {
+	toEnd := make([]models.TaskPeriod, 0, len(running))
+	start := true
+	for _, period := range running {
+		if period.TaskID == taskID {
+			start = false
+			continue
+		}
+		toEnd = append(toEnd, period)
+	}
+	return toEnd, start
+}
+
+func (p *PgStorage) EndPeriod(taskID uint, endTime time.Time) error {
 	log := p.log.With(slog.String("op", "PgStorage.EndPeriod"))
 
 	var ongoingPeriod models.TaskPeriod
@@ -102,13 +476,33 @@ func (p *PgStorage) EndPeriod(taskID uint, startTime time.Time) error {
 		return storage.ErrPeriodNotStarted
 	}
 
-	now := time.Now()
-	ongoingPeriod.EndTime = &now
+	task, err := lockTask(tx, taskID)
+	if err != nil {
+		log.Error("failed to lock task", slog.Uint64("task_id", uint64(taskID)), slog.Any("err", err))
+		return err
+	}
+	if err := checkUnlocked(tx, task.UserID, *ongoingPeriod.StartTime, endTime); err != nil {
+		log.Warn("period can not be ended", slog.Uint64("task_id", uint64(taskID)), slog.Any("err", err))
+		return err
+	}
+
+	ongoingPeriod.EndTime = &endTime
 
 	res := tx.Save(&ongoingPeriod)
 	if res.Error != nil {
 		log.Error("failed to end period", slog.Uint64("task_id", uint64(taskID)), slog.Any("err", res.Error))
 		return res.Error
 	}
+
+	if err := refreshDailyTotals(tx, taskID, *ongoingPeriod.StartTime, endTime); err != nil {
+		log.Error("failed to refresh daily totals", slog.Uint64("task_id", uint64(taskID)), slog.Any("err", err))
+		return err
+	}
+
+	if err := setTaskStatus(tx, taskID, models.TaskPaused); err != nil {
+		log.Error("failed to set task status", slog.Uint64("task_id", uint64(taskID)), slog.Any("err", err))
+		return err
+	}
+
 	return tx.Commit().Error
 }
@@ -0,0 +1,107 @@
+package postgres
+
+import (
+	"database/sql/driver"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/moxicom/user_test/internal/models"
+	"github.com/moxicom/user_test/internal/storage"
+)
+
+func TestTagNames(t *testing.T) {
+	tests := []struct {
+		names    []string
+		expected []string
+	}{
+		{nil, []string{}},
+		{[]string{"backend", "urgent"}, []string{"backend", "urgent"}},
+		{[]string{" backend ", "", "  "}, []string{"backend"}},
+		{[]string{"backend", "urgent", "backend ", "urgent"}, []string{"backend", "urgent"}},
+		{[]string{"Backend", "backend"}, []string{"Backend", "backend"}},
+	}
+
+	for _, test := range tests {
+		result := tagNames(test.names)
+		if !slices.Equal(result, test.expected) {
+			t.Errorf("tagNames(%q) = %q; expected %q", test.names, result, test.expected)
+		}
+	}
+}
+
+func TestSwitchPeriods(t *testing.T) {
+	running := func(taskIDs ...uint) []models.TaskPeriod {
+		periods := make([]models.TaskPeriod, 0, len(taskIDs))
+		for i, taskID := range taskIDs {
+			periods = append(periods, models.TaskPeriod{ID: uint(i + 1), TaskID: taskID})
+		}
+		return periods
+	}
+
+	tests := []struct {
+		name    string
+		running []models.TaskPeriod
+		toEnd   []uint
+		start   bool
+	}{
+		{"nothing running", nil, []uint{}, true},
+		{"another task running", running(2), []uint{2}, true},
+		{"several timers running", running(2, 3), []uint{2, 3}, true},
+		{"task already running", running(1), []uint{}, false},
+		{"task and others running", running(2, 1, 3), []uint{2, 3}, false},
+	}
+
+	for _, test := range tests {
+		toEnd, start := switchPeriods(test.running, 1)
+		ended := make([]uint, 0, len(toEnd))
+		for _, period := range toEnd {
+			ended = append(ended, period.TaskID)
+		}
+		if !slices.Equal(ended, test.toEnd) || start != test.start {
+			t.Errorf("%s: switchPeriods ends tasks %v, start %v; expected %v, %v", test.name, ended, start, test.toEnd, test.start)
+		}
+	}
+}
+
+func TestEstimateChanged(t *testing.T) {
+	estimate := func(v int64) *int64 { return &v }
+	tests := []struct {
+		name     string
+		current  *int64
+		update   *int64
+		expected bool
+	}{
+		{"estimate changed", estimate(3600), estimate(7200), true},
+		{"estimate removed", estimate(3600), estimate(0), true},
+		{"estimate set", nil, estimate(3600), true},
+		{"same estimate", estimate(3600), estimate(3600), false},
+		{"still no estimate", nil, estimate(0), false},
+		{"estimate omitted", estimate(3600), nil, false},
+	}
+
+	for _, test := range tests {
+		if result := estimateChanged(test.current, test.update); result != test.expected {
+			t.Errorf("%s: estimateChanged = %v; expected %v", test.name, result, test.expected)
+		}
+	}
+}
+
+func TestStartPeriod(t *testing.T) {
+	tests := []struct {
+		name      string
+		periodEnd driver.Value
+		expected  error
+	}{
+		{"no running period", lockedEnd, nil},
+		{"running period", nil, storage.ErrPeriodNotFinished},
+	}
+
+	for _, test := range tests {
+		store := newFakeStorage(t, lockingDB(0, 1, test.periodEnd))
+
+		if err := store.StartPeriod(1, lockedEnd); !errors.Is(err, test.expected) {
+			t.Errorf("%s: StartPeriod = %v; expected %v", test.name, err, test.expected)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package postgres
+
+import (
+	"errors"
+	"log/slog"
+
+	"github.com/moxicom/user_test/internal/models"
+	"github.com/moxicom/user_test/internal/storage"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func (p *PgStorage) CreateTeam(team models.Team) (uint, error) {
+	log := p.log.With(slog.String("op", "PgStorage.CreateTeam"))
+
+	result := p.db.Create(&team)
+	if result.Error != nil {
+		log.Error("failed to add team", slog.Any("err", result.Error))
+		return 0, result.Error
+	}
+	log.Debug("team added to storage", slog.Any("team", team))
+	return team.ID, nil
+}
+
+func (p *PgStorage) GetTeams() ([]models.Team, error) {
+	log := p.log.With(slog.String("op", "PgStorage.GetTeams"))
+	var teams []models.Team
+
+	if err := p.db.Order("name").Find(&teams).Error; err != nil {
+		log.Error("failed to get teams", slog.Any("err", err))
+		return []models.Team{}, err
+	}
+
+	return teams, nil
+}
+
+func (p *PgStorage) DeleteTeam(teamID uint) error {
+	log := p.log.With(slog.String("op", "PgStorage.DeleteTeam"))
+	tx := p.db.Begin()
+	defer tx.Rollback()
+
+	res := tx.Delete(&models.Team{}, teamID)
+	if res.Error != nil {
+		log.Error("failed to delete team. Rolled back", slog.Any("err", res.Error))
+		return res.Error
+	}
+
+	return tx.Commit().Error
+}
+
+// AddTeamMember adds user to team or updates the role of an existing member.
+// The team is locked so it is not deleted meanwhile
+func (p *PgStorage) AddTeamMember(member models.TeamMember) error {
+	log := p.log.With(slog.String("op", "PgStorage.AddTeamMember"))
+	tx := p.db.Begin()
+	defer tx.Rollback()
+
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&models.Team{}, member.TeamID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return storage.ErrTeamNotFound
+	}
+	if err != nil {
+		log.Error("failed to lock team", slog.Uint64("team_id", uint64(member.TeamID)), slog.Any("err", err))
+		return err
+	}
+
+	res := tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "team_id"}, {Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"role"}),
+	}).Create(&member)
+	if res.Error != nil {
+		log.Error("failed to add team member", slog.Any("member", member), slog.Any("err", res.Error))
+		return res.Error
+	}
+
+	return tx.Commit().Error
+}
+
+func (p *PgStorage) RemoveTeamMember(teamID uint, userID uint) error {
+	log := p.log.With(slog.String("op", "PgStorage.RemoveTeamMember"))
+
+	res := p.db.Where("team_id = ? AND user_id = ?", teamID, userID).Delete(&models.TeamMember{})
+	if res.Error != nil {
+		log.Error("failed to remove team member", slog.Any("err", res.Error))
+		return res.Error
+	}
+
+	return nil
+}
+
+func (p *PgStorage) GetTeamMembers(teamID uint) ([]models.TeamMemberInfo, error) {
+	log := p.log.With(slog.String("op", "PgStorage.GetTeamMembers"))
+	var members []models.TeamMemberInfo
+
+	res := p.db.Model(&models.User{}).
+		Select("users.*, team_members.role").
+		Joins("JOIN team_members ON team_members.user_id = users.id").
+		Where("team_members.team_id = ?", teamID).
+		Order("team_members.role, users.surname").
+		Find(&members)
+	if res.Error != nil {
+		log.Error("failed to get team members", slog.Uint64("team_id", uint64(teamID)), slog.Any("err", res.Error))
+		return []models.TeamMemberInfo{}, res.Error
+	}
+
+	return members, nil
+}
+
+// GetManagedUsers returns members of all teams managed by the user
+func (p *PgStorage) GetManagedUsers(managerID uint) ([]models.User, error) {
+	log := p.log.With(slog.String("op", "PgStorage.GetManagedUsers"))
+	var users []models.User
+
+	managed := p.db.Model(&models.TeamMember{}).
+		Select("team_id").
+		Where("user_id = ? AND role = ?", managerID, models.TeamManagerRole)
+
+	res := p.db.Model(&models.User{}).
+		Distinct("users.*").
+		Joins("JOIN team_members ON team_members.user_id = users.id").
+		Where("team_members.team_id IN (?) AND users.id <> ?", managed, managerID).
+		Find(&users)
+	if res.Error != nil {
+		log.Error("failed to get managed users", slog.Uint64("manager_id", uint64(managerID)), slog.Any("err", res.Error))
+		return []models.User{}, res.Error
+	}
+
+	return users, nil
+}
@@ -0,0 +1,30 @@
+package postgres
+
+import (
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/moxicom/user_test/internal/models"
+	"github.com/moxicom/user_test/internal/storage"
+)
+
+func TestAddTeamMember(t *testing.T) {
+	for _, exists := range []bool{true, false} {
+		db := &fakeDB{respond: func(query string, _ []driver.Value) fakeResult {
+			if exists && strings.Contains(query, `FROM "teams"`) {
+				return fakeResult{columns: []string{"id", "name"}, rows: [][]driver.Value{{int64(2), "Core"}}}
+			}
+			return fakeResult{affected: 1}
+		}}
+
+		err := newFakeStorage(t, db).AddTeamMember(models.TeamMember{TeamID: 2, UserID: 3, Role: models.TeamMemberRole})
+		if exists && err != nil {
+			t.Errorf("existing team: err = %v; expected nil", err)
+		}
+		if !exists && !errors.Is(err, storage.ErrTeamNotFound) {
+			t.Errorf("missing team: err = %v; expected %v", err, storage.ErrTeamNotFound)
+		}
+	}
+}
@@ -1,11 +1,13 @@
 package postgres
 
 import (
-	"fmt"
+	"errors"
 	"log/slog"
 	"time"
 
 	"github.com/moxicom/user_test/internal/models"
+	"github.com/moxicom/user_test/internal/storage"
+	"gorm.io/gorm"
 )
 
 func (p *PgStorage) AddUser(user models.User) (uint, error) {
@@ -43,6 +45,21 @@ func (p *PgStorage) GetUsers(filters models.UserFilters) ([]models.User, error)
 	if filters.Address != "" {
 		query = query.Where("LOWER(address) LIKE LOWER(?)", "%"+filters.Address+"%")
 	}
+	if filters.Email != "" {
+		query = query.Where("LOWER(email) LIKE LOWER(?)", "%"+filters.Email+"%")
+	}
+	if filters.Status != "" {
+		query = query.Where("status = ?", filters.Status)
+	}
+	if filters.SingleTimer != nil {
+		query = query.Where("single_timer = ?", *filters.SingleTimer)
+	}
+	if filters.TeamID != 0 {
+		query = query.
+			Select("users.*").
+			Joins("JOIN team_members ON team_members.user_id = users.id").
+			Where("team_members.team_id = ?", filters.TeamID)
+	}
 
 	res := query.Find(&users)
 	if res.Error != nil {
@@ -55,6 +72,56 @@ func (p *PgStorage) GetUsers(filters models.UserFilters) ([]models.User, error)
 	return users, tx.Commit().Error
 }
 
+func (p *PgStorage) GetUser(userID uint) (models.User, error) {
+	log := p.log.With(slog.String("op", "PgStorage.GetUser"))
+	var user models.User
+
+	err := p.db.First(&user, userID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Warn("user not found", slog.Uint64("user_id", uint64(userID)))
+		return models.User{}, storage.ErrUserNotFound
+	}
+	if err != nil {
+		log.Error("failed to get user", slog.Uint64("user_id", uint64(userID)), slog.Any("err", err))
+		return models.User{}, err
+	}
+
+	return user, nil
+}
+
+// SetCalendarToken replaces calendar feed token of the user
+func (p *PgStorage) SetCalendarToken(userID uint, token string) error {
+	log := p.log.With(slog.String("op", "PgStorage.SetCalendarToken"))
+
+	res := p.db.Model(&models.User{}).Where("id = ?", userID).Update("calendar_token", token)
+	if res.Error != nil {
+		log.Error("failed to set calendar token", slog.Uint64("user_id", uint64(userID)), slog.Any("err", res.Error))
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return storage.ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (p *PgStorage) GetUserByCalendarToken(token string) (models.User, error) {
+	log := p.log.With(slog.String("op", "PgStorage.GetUserByCalendarToken"))
+	var user models.User
+
+	err := p.db.Where("calendar_token = ?", token).First(&user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Warn("unknown calendar token")
+		return models.User{}, storage.ErrCalendarNotFound
+	}
+	if err != nil {
+		log.Error("failed to get user by calendar token", slog.Any("err", err))
+		return models.User{}, err
+	}
+
+	return user, nil
+}
+
 func (p *PgStorage) UpdateUser(userID uint, filters models.UserFilters) error {
 	log := p.log.With(slog.String("op", "PgStorage.UpdateUser"))
 	var user models.User
@@ -82,6 +149,18 @@ func (p *PgStorage) UpdateUser(userID uint, filters models.UserFilters) error {
 	if filters.Address != "" {
 		user.Address = filters.Address
 	}
+	if filters.Email != "" {
+		user.Email = filters.Email
+	}
+	if filters.SingleTimer != nil {
+		user.SingleTimer = *filters.SingleTimer
+	}
+	if filters.WorkdayEnd != nil {
+		user.WorkdayEnd = *filters.WorkdayEnd
+	}
+	if filters.TimeZone != nil {
+		user.TimeZone = *filters.TimeZone
+	}
 
 	if err := tx.Save(&user).Error; err != nil {
 		log.Error("failed to update user", slog.Any("err", err))
@@ -91,9 +170,35 @@ func (p *PgStorage) UpdateUser(userID uint, filters models.UserFilters) error {
 	return tx.Commit().Error
 }
 
+// DeleteUser deletes the user with tasks and periods. User with approved
+// timesheets can not be deleted
 func (p *PgStorage) DeleteUser(userID uint) error {
 	log := p.log.With(slog.String("op", "PgStorage.DeleteUser"))
 	tx := p.db.Begin()
+	defer tx.Rollback()
+
+	// reviews lock the user too, so no week is approved meanwhile
+	_, err := lockUser(tx, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return storage.ErrUserNotFound
+	}
+	if err != nil {
+		log.Error("failed to lock user", slog.Uint64("user_id", uint64(userID)), slog.Any("err", err))
+		return err
+	}
+
+	var approved int64
+	err = tx.Model(&models.TimesheetSubmission{}).
+		Where("user_id = ? AND status = ?", userID, models.SubmissionApproved).
+		Count(&approved).Error
+	if err != nil {
+		log.Error("failed to check approved timesheets", slog.Uint64("user_id", uint64(userID)), slog.Any("err", err))
+		return err
+	}
+	if approved > 0 {
+		log.Warn("user can not be deleted. It has approved timesheets", slog.Uint64("user_id", uint64(userID)))
+		return storage.ErrUserHasApproved
+	}
 
 	res := tx.Delete(&models.User{}, userID)
 	if res.Error != nil {
@@ -104,53 +209,77 @@ func (p *PgStorage) DeleteUser(userID uint) error {
 	return tx.Commit().Error
 }
 
-func (p *PgStorage) GetUserTasks(userID uint, startTime time.Time, endTime time.Time, isAsc bool) ([]models.TaskWithTotalTime, error) {
-	log := p.log.With(slog.String("op", "PgStorage.GetUserTasks"))
+func (p *PgStorage) SetUserStatus(userID uint, status models.UserStatus, effective time.Time) error {
+	log := p.log.With(slog.String("op", "PgStorage.SetUserStatus"))
+	var user models.User
 
-	var tasks []models.TaskWithTotalTime
 	tx := p.db.Begin()
 	defer tx.Rollback()
 
+	err := tx.First(&user, userID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return storage.ErrUserNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	user.Status = status
+	user.StatusChangedAt = &effective
+	if status == models.UserTerminated {
+		user.TerminatedAt = &effective
+	}
+
+	if err := tx.Save(&user).Error; err != nil {
+		log.Error("failed to set user status", slog.Uint64("user_id", uint64(userID)), slog.Any("err", err))
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// GetUserTasks returns tasks of the user worked on within [startTime, endTime].
+// Only periods overlapping the window are counted and clipped to it, so the
+// total is the time worked in the range
+func (p *PgStorage) GetUserTasks(userID uint, startTime time.Time, endTime time.Time, filters models.TaskFilters) ([]models.TaskWithTotalTime, error) {
+	log := p.log.With(slog.String("op", "PgStorage.GetUserTasks"))
+
+	var tasks []models.TaskWithTotalTime
+
 	db := p.db
 
-	subquery := db.Model(&models.TaskPeriod{}).
-		Select("task_id, SUM(EXTRACT(EPOCH FROM COALESCE(end_time, CURRENT_TIMESTAMP) - COALESCE(start_time, CURRENT_TIMESTAMP))) AS total_duration").
-		Group("task_id")
+	subquery := taskDurations(db, durationScope{UserID: userID}, startTime, endTime)
+
+	order := "total_seconds DESC"
+	if filters.Asc {
+		order = "total_seconds ASC"
+	}
+
+	query := db.Model(&models.Task{})
+	if filters.ProjectID != 0 {
+		query = query.Where("tasks.project_id = ?", filters.ProjectID)
+	}
+	if filters.Tag != "" {
+		query = query.Where("tasks.id IN (?)", taggedTasks(db, filters.Tag))
+	}
 
 	// Main query to fetch tasks with total durations
-	res := db.
-		// Joins("LEFT JOIN (?) AS periods ON tasks.id = periods.task_id", subquery).
-		// Model(&models.Task{}).
-		// Select("tasks.*, COALESCE(periods.total_duration, 0) AS duration").
-		// Where("tasks.user_id = ? AND tasks.created_at BETWEEN ? AND ?", userID, startTime, endTime).
-		// Order("duration DESC").
-		// Find(&tasks)
-		Joins("LEFT JOIN (?) AS periods ON tasks.id = periods.task_id", subquery).
-		Model(&models.Task{}).
-		Select(`
-        tasks.*,
-        COALESCE(periods.total_duration, 0) AS total_seconds,
-        FLOOR(COALESCE(periods.total_duration, 0) / 3600) AS duration_hours,
-        FLOOR(COALESCE(periods.total_duration, 0) / 60) AS duration_minutes
-    `).
-		Where("tasks.user_id = ? AND tasks.created_at BETWEEN ? AND ?", userID, startTime, endTime).
-		Order("total_seconds DESC").
+	res := query.
+		Joins("JOIN (?) AS periods ON tasks.id = periods.task_id", subquery).
+		Select("tasks.*, "+durationColumns+", "+trackedColumn).
+		Where("tasks.user_id = ?", userID).
+		Order(order).
 		Find(&tasks)
 
-	// res := subquery.Find(&tasks)
-
-	err := res.Error
-	if err != nil {
+	if err := res.Error; err != nil {
 		log.Error("Failed to get user tasks", slog.Uint64("user_id", uint64(userID)), slog.Any("err", err.Error()))
 		return nil, err
 	}
 
-	// Convert total_time from seconds to time.Duration
-	// for i := range tasks {
-	// 	tasks[i].Duration = time.Duration(tasks[i].Duration) * time.Second
-	// }
-	fmt.Println()
-	fmt.Println(tasks)
-	fmt.Println()
+	if err := loadTags(db, tasks); err != nil {
+		log.Error("Failed to get user tasks tags", slog.Uint64("user_id", uint64(userID)), slog.Any("err", err))
+		return nil, err
+	}
+
 	return tasks, nil
 }
@@ -8,20 +8,86 @@ import (
 )
 
 var (
+	ErrUserNotFound      = fmt.Errorf("user not found")
 	ErrPeriodNotStarted  = fmt.Errorf("period not started")
 	ErrPeriodNotFinished = fmt.Errorf("period not finished")
+	ErrPeriodOverlap     = fmt.Errorf("period overlaps another period of the task")
+	ErrTimerRunning      = fmt.Errorf("another timer of the user is running")
+	ErrCalendarNotFound  = fmt.Errorf("calendar not found")
+	ErrPeriodLocked      = fmt.Errorf("period is within an approved timesheet")
+	ErrWeekTimerRunning  = fmt.Errorf("timer of the user is running within the week")
+	ErrAlreadySubmitted  = fmt.Errorf("timesheet of the week is already submitted")
+	ErrNotSubmitted      = fmt.Errorf("timesheet is not waiting for review")
+	ErrUserHasApproved   = fmt.Errorf("user has approved timesheets")
+	ErrPeriodInvoiced    = fmt.Errorf("period is invoiced")
+	ErrRateNotFound      = fmt.Errorf("rate not found")
+	ErrTeamNotFound      = fmt.Errorf("team not found")
+	ErrPeriodNotFound    = fmt.Errorf("period not found")
 )
 
 type Storage interface {
 	GetUsers(models.UserFilters) ([]models.User, error)
-	GetUserTasks(userID uint, startTime time.Time, endTime time.Time, isAsc bool) ([]models.TaskWithTotalTime, error)
+	GetUser(uint) (models.User, error)
+	GetUserTasks(userID uint, startTime time.Time, endTime time.Time, filters models.TaskFilters) ([]models.TaskWithTotalTime, error)
 	AddUser(models.User) (uint, error)
 	UpdateUser(uint, models.UserFilters) error
 	DeleteUser(uint) error
+	SetUserStatus(uint, models.UserStatus, time.Time) error
+	SetCalendarToken(userID uint, token string) error
+	GetUserByCalendarToken(token string) (models.User, error)
 
 	CreateTask(models.Task) (uint, error)
+	GetTask(uint) (models.Task, error)
+	GetTasks(models.TaskFilters) ([]models.Task, error)
+	UpdateTask(uint, models.TaskUpdate) error
+	GetTags() ([]models.Tag, error)
 	FinishTask(uint, time.Time) error
+	SetTaskStatus(uint, models.TaskStatus) error
+	GetTaskPeriods(uint) ([]models.TaskPeriod, error)
 	DeleteTask(uint) error
 	StartPeriod(uint, time.Time) error
 	EndPeriod(uint, time.Time) error
+	SwitchPeriod(uint, time.Time) error
+	GetRunningPeriods(userID uint) ([]models.RunningPeriod, error)
+	GetUserPeriods(userID uint, from time.Time, to time.Time) ([]models.ReportPeriod, error)
+	AutoClosePeriod(periodID uint, endTime time.Time) error
+	GetPeriod(uint) (models.TaskPeriod, error)
+	AddPeriod(models.TaskPeriod) (uint, error)
+	UpdatePeriod(periodID uint, startTime time.Time, endTime time.Time) error
+	DeletePeriod(uint) error
+	SetPeriodBillable(periodID uint, billable bool) error
+	ImportPeriods(entries []models.ImportEntry, dryRun bool) (models.ImportResult, error)
+	GetTaskTotals(from time.Time, to time.Time, teamID uint) ([]models.TaskTotal, error)
+	GetEstimatedTasks() ([]models.EstimatedTask, error)
+	CreateTaskAlerts([]models.TaskAlert) error
+	GetTaskAlerts(models.AlertFilters) ([]models.TaskAlert, error)
+
+	SubmitTimesheet(models.TimesheetSubmission) (uint, error)
+	GetSubmission(uint) (models.TimesheetSubmission, error)
+	GetSubmissions(models.SubmissionFilters) ([]models.TimesheetSubmission, error)
+	ReviewSubmission(id uint, status models.SubmissionStatus, reviewerID uint, comment string, reviewedAt time.Time) error
+
+	CreateTeam(models.Team) (uint, error)
+	GetTeams() ([]models.Team, error)
+	DeleteTeam(uint) error
+	AddTeamMember(models.TeamMember) error
+	RemoveTeamMember(teamID uint, userID uint) error
+	GetTeamMembers(uint) ([]models.TeamMemberInfo, error)
+	GetManagedUsers(managerID uint) ([]models.User, error)
+
+	CreateProject(models.Project) (uint, error)
+	GetProjects(models.ProjectFilters) ([]models.Project, error)
+	GetProject(uint) (models.Project, error)
+	UpdateProject(uint, models.ProjectFilters) error
+	DeleteProject(uint) error
+	GetProjectDays(projectID uint, bounds []time.Time) (before models.ProjectDayTotal, days []models.ProjectDayTotal, err error)
+
+	CreateRate(models.Rate) (uint, error)
+	GetRates(models.RateFilters) ([]models.Rate, error)
+	DeleteRate(uint) error
+
+	CreateInvoice(sel models.InvoiceSelection, build func([]models.InvoiceLine) (models.Invoice, error)) (models.Invoice, error)
+	GetInvoices(models.InvoiceFilters) ([]models.Invoice, error)
+	GetInvoice(uint) (models.Invoice, error)
+	DeleteInvoice(uint) error
 }
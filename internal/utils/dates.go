@@ -0,0 +1,30 @@
+package utils
+
+import "time"
+
+const (
+	dateLayout     = "2006-01-02"
+	dateTimeLayout = "2006-01-02T15:04:05"
+)
+
+// ParseTime parses RFC3339 time or, without offset, date-time and date
+// interpreted in loc. Date of a range end means the end of that day
+func ParseTime(value string, loc *time.Location, rangeEnd bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	if t, err := time.ParseInLocation(dateTimeLayout, value, loc); err == nil {
+		return t, nil
+	}
+
+	t, err := time.ParseInLocation(dateLayout, value, loc)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if rangeEnd {
+		// AddDate keeps local midnight on days with DST change
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
@@ -1,6 +1,8 @@
 package utils
 
 import (
+	"strconv"
+
 	"github.com/gin-gonic/gin"
 	"github.com/moxicom/user_test/internal/models"
 )
@@ -12,6 +14,37 @@ func GetFilters(c *gin.Context) models.UserFilters {
 		Name:           c.Query("name"),
 		Patronymic:     c.Query("patronymic"),
 		Address:        c.Query("address"),
+		Email:          c.Query("email"),
+		Status:         models.UserStatus(c.Query("status")),
+		WorkdayEnd:     clearableQuery(c, "workday_end"),
+		TimeZone:       clearableQuery(c, "time_zone"),
+	}
+	if teamID, err := strconv.ParseUint(c.Query("team_id"), 10, 32); err == nil {
+		f.TeamID = uint(teamID)
+	}
+	if singleTimer, err := strconv.ParseBool(c.Query("single_timer")); err == nil {
+		f.SingleTimer = &singleTimer
 	}
 	return f
 }
+
+// clearableQuery returns nil if the parameter is not given. Empty value or
+// null are returned as empty string meaning the field is cleared
+func clearableQuery(c *gin.Context, key string) *string {
+	v, ok := c.GetQuery(key)
+	if !ok {
+		return nil
+	}
+	if v == "null" {
+		v = ""
+	}
+	return &v
+}
+
+func GetProjectFilters(c *gin.Context) models.ProjectFilters {
+	return models.ProjectFilters{
+		Name:        c.Query("name"),
+		Client:      c.Query("client"),
+		Description: c.Query("description"),
+	}
+}
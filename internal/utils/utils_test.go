@@ -1,7 +1,12 @@
 package utils
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
 )
 
 func TestValidatePassword(t *testing.T) {
@@ -28,3 +33,64 @@ func TestValidatePassword(t *testing.T) {
 		}
 	}
 }
+
+func TestParseTime(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		value    string
+		rangeEnd bool
+		expected time.Time
+	}{
+		{"2024-07-01T10:00:00Z", false, time.Date(2024, 7, 1, 10, 0, 0, 0, time.UTC)},
+		{"2024-07-01T10:00:00+03:00", true, time.Date(2024, 7, 1, 7, 0, 0, 0, time.UTC)},
+		{"2024-07-01T10:00:00", false, time.Date(2024, 7, 1, 8, 0, 0, 0, time.UTC)},
+		{"2024-07-01", false, time.Date(2024, 6, 30, 22, 0, 0, 0, time.UTC)},
+		{"2024-07-01", true, time.Date(2024, 7, 1, 22, 0, 0, 0, time.UTC)},
+		{"2024-03-31", true, time.Date(2024, 3, 31, 22, 0, 0, 0, time.UTC)}, // DST starts, day is 23 hours
+		{"2024-10-27", false, time.Date(2024, 10, 26, 22, 0, 0, 0, time.UTC)},
+		{"2024-10-27", true, time.Date(2024, 10, 27, 23, 0, 0, 0, time.UTC)}, // DST ends, day is 25 hours
+	}
+
+	for _, test := range tests {
+		result, err := ParseTime(test.value, berlin, test.rangeEnd)
+		if err != nil {
+			t.Errorf("ParseTime(%q) returned error: %v", test.value, err)
+			continue
+		}
+		if !result.Equal(test.expected) {
+			t.Errorf("ParseTime(%q, %v) = %v; expected %v", test.value, test.rangeEnd, result.UTC(), test.expected)
+		}
+	}
+
+	if _, err := ParseTime("01.07.2024", berlin, false); err == nil {
+		t.Errorf("ParseTime(%q) expected error", "01.07.2024")
+	}
+}
+
+func TestClearableFilters(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected *string
+	}{
+		{"", nil},
+		{"time_zone=Europe/Berlin", ptr("Europe/Berlin")},
+		{"time_zone=", ptr("")},
+		{"time_zone=null", ptr("")},
+	}
+
+	for _, test := range tests {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodPut, "/users/1?"+test.query, nil)
+
+		result := GetFilters(c).TimeZone
+		if (result == nil) != (test.expected == nil) || (result != nil && *result != *test.expected) {
+			t.Errorf("GetFilters(%q).TimeZone = %v; expected %v", test.query, result, test.expected)
+		}
+	}
+}
+
+func ptr(s string) *string { return &s }